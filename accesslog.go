@@ -0,0 +1,89 @@
+package route
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// AccessLog logs a line for each request served by a handler it instruments,
+// giving the method, path, matched pattern, status, duration and size of the
+// response. Any error returned by the handler is included, so that it appears
+// with the request that caused it:
+//
+//   accessLog := &route.AccessLog{Logger: logger, Vars: []string{"tenant"}}
+//   http.ListenAndServe(":8080", accessLog.Instrument(router))
+//
+// Which logs, with a text handler:
+//
+//   level=INFO msg=request method=GET path=/acme/users/5 pattern=/:tenant/users/:id status=200 duration=1.2ms bytes=512 vars.tenant=acme
+//
+// Requests with a 5xx status are logged at the Error level, others at Info.
+type AccessLog struct {
+	// Logger is the logger to write to, if nil slog.Default is used.
+	Logger *slog.Logger
+
+	// Vars lists the names of the parameters to include, with their unescaped
+	// values.
+	Vars []string
+
+	// Attrs, if set, returns further attributes to log for the request.
+	Attrs func(r *http.Request) []slog.Attr
+}
+
+// Instrument wraps the handler, which should be a Router or contain one, so that
+// the requests it serves are logged.
+func (l *AccessLog) Instrument(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		r, o := observe(r, len(l.Vars) > 0)
+		rec := &responseRecorder{ResponseWriter: w}
+
+		handler.ServeHTTP(rec, r)
+
+		l.log(r, o, rec, time.Since(start))
+	})
+}
+
+func (l *AccessLog) log(r *http.Request, o *observation, rec *responseRecorder, duration time.Duration) {
+	logger := l.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", r.Method),
+		slog.String("path", r.URL.Path),
+		slog.String("pattern", o.pattern),
+		slog.Int("status", rec.code()),
+		slog.Duration("duration", duration),
+		slog.Int64("bytes", rec.size),
+	}
+
+	if len(l.Vars) > 0 {
+		var vars []any
+		for _, name := range l.Vars {
+			if value, ok := o.vars[name]; ok {
+				vars = append(vars, slog.String(name, value))
+			}
+		}
+		if len(vars) > 0 {
+			attrs = append(attrs, slog.Group("vars", vars...))
+		}
+	}
+
+	if o.err != nil {
+		attrs = append(attrs, slog.String("error", o.err.Error()))
+	}
+
+	if l.Attrs != nil {
+		attrs = append(attrs, l.Attrs(r)...)
+	}
+
+	level := slog.LevelInfo
+	if rec.code() >= 500 {
+		level = slog.LevelError
+	}
+
+	logger.LogAttrs(r.Context(), level, "request", attrs...)
+}
@@ -0,0 +1,56 @@
+package route
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey || a.Key == "duration" {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	router := New()
+	router.ErrorHandler = IgnoreErrors
+	router.HandleFunc("GET /:tenant/users/:id", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	})
+	router.HandleFunc("GET /fail", func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(502)
+		return errors.New("upstream down")
+	})
+
+	accessLog := &AccessLog{
+		Logger: logger,
+		Vars:   []string{"tenant", "missing"},
+		Attrs: func(r *http.Request) []slog.Attr {
+			return []slog.Attr{slog.String("agent", r.UserAgent())}
+		},
+	}
+	handler := accessLog.Instrument(router)
+
+	for _, path := range []string{"/acme%20co/users/5", "/fail", "/missing"} {
+		r := httptest.NewRequest("GET", path, nil)
+		r.Header.Set("User-Agent", "test")
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	assert.Equal(t, []string{
+		`level=INFO msg=request method=GET path="/acme co/users/5" pattern=/:tenant/users/:id status=200 bytes=5 vars.tenant="acme co" agent=test`,
+		`level=ERROR msg=request method=GET path=/fail pattern=/fail status=502 bytes=0 error="upstream down" agent=test`,
+		`level=INFO msg=request method=GET path=/missing pattern="" status=404 bytes=19 agent=test`,
+	}, strings.Split(strings.TrimSpace(buf.String()), "\n"))
+}
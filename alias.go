@@ -0,0 +1,54 @@
+package route
+
+import "net/http"
+
+// HandleAll registers the handler for each of the paths, with the same options.
+// The first path is the canonical path of the route, the others being aliases
+// for it, so only the first is given any name set with the Name option:
+//
+//   router.HandleAll([]string{"/healthz", "/health", "/ping"}, healthHandler, route.Name("health"))
+//
+//   router.URL("health") // "/healthz"
+//
+// The handler may be any type accepted by Handle or HandleFunc. Routes
+// described by Walk and Routes give the canonical path of aliases.
+func (r *Router) HandleAll(paths []string, handler interface{}, opts ...Option) {
+	if len(paths) == 0 {
+		return
+	}
+
+	_, host, canonical := parsePattern(paths[0])
+
+	for i, path := range paths {
+		o := opts
+		if i > 0 {
+			o = append(append([]Option{}, opts...), aliasOf(host+canonical))
+		}
+
+		switch handler.(type) {
+		case Handler, http.Handler:
+			r.Handle(path, handler, o...)
+		default:
+			r.HandleFunc(path, handler, o...)
+		}
+	}
+}
+
+// HandleAll registers the handler for each of the paths, relative to the Group,
+// as Router.HandleAll does.
+func (g *Group) HandleAll(paths []string, handler interface{}, opts ...Option) {
+	patterns := make([]string, len(paths))
+	for i, path := range paths {
+		patterns[i] = g.pattern(path)
+	}
+
+	g.router.HandleAll(patterns, handler, append(append([]Option{}, g.opts...), opts...)...)
+}
+
+// aliasOf marks the route as an alias of the canonical path, removing its name.
+func aliasOf(canonical string) Option {
+	return func(e *entry) {
+		e.name = ""
+		e.canonical = canonical
+	}
+}
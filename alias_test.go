@@ -0,0 +1,46 @@
+package route
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouterHandleAll(t *testing.T) {
+	router := New()
+	router.HandleAll([]string{"GET /healthz", "/health", "/ping"}, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(204)
+	}, Name("health"), Methods("GET"))
+	router.Group("/api").HandleAll([]string{"/users/:id", "/people/:id"}, &recordingHandler{})
+
+	for _, path := range []string{"/healthz", "/health", "/ping"} {
+		r := httptest.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+
+		assert.Equal(t, 204, w.Code, path)
+	}
+
+	r := httptest.NewRequest("POST", "/ping", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	assert.Equal(t, 405, w.Code)
+
+	u, err := router.URL("health")
+	assert.Nil(t, err)
+	assert.Equal(t, "/healthz", u.String())
+
+	canonical := map[string]string{}
+	for _, info := range router.Routes() {
+		canonical[info.Pattern] = info.Canonical
+	}
+	assert.Equal(t, map[string]string{
+		"/healthz":        "",
+		"/health":         "/healthz",
+		"/ping":           "/healthz",
+		"/api/users/:id":  "",
+		"/api/people/:id": "/api/users/:id",
+	}, canonical)
+}
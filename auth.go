@@ -0,0 +1,202 @@
+package route
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// AuthError is returned when a request is not authenticated. It is responded to
+// with 401 Unauthorized and a WWW-Authenticate header giving the Challenge.
+type AuthError struct {
+	// Challenge is the value of the WWW-Authenticate header, as
+	// `Basic realm="admin"`.
+	Challenge string
+
+	// Err is the reason the request was not authenticated, if any. It is not
+	// shown to clients.
+	Err error
+}
+
+func (e *AuthError) Error() string {
+	if e.Err != nil {
+		return "route: unauthorized: " + e.Err.Error()
+	}
+	return "route: unauthorized"
+}
+
+// StatusCode returns 401.
+func (e *AuthError) StatusCode() int {
+	return http.StatusUnauthorized
+}
+
+// Header returns the WWW-Authenticate header to respond with.
+func (e *AuthError) Header() http.Header {
+	return http.Header{"Www-Authenticate": {e.Challenge}}
+}
+
+func (e *AuthError) Unwrap() error {
+	return e.Err
+}
+
+type basicAuthKey struct{}
+
+// BasicAuth returns a handler that passes requests with a username and password,
+// using HTTP Basic authentication, to h. The password is compared, in constant
+// time, to that returned by lookup for the username. Requests without valid
+// credentials return an AuthError, so are responded to by the ErrorHandler with
+// 401 Unauthorized and a challenge for the realm:
+//
+//   router.Handle("/admin/*path", route.BasicAuth(adminHandler, "admin", func(user string) (string, bool) {
+//     password, ok := admins[user]
+//     return password, ok
+//   }))
+//
+// The handler h must be either a Handler or an http.Handler. The username is
+// available to it with BasicAuthUser.
+func BasicAuth(h interface{}, realm string, lookup func(user string) (password string, ok bool)) Handler {
+	handler := toHandler(h)
+	challenge := "Basic realm=" + strconv.Quote(realm) + `, charset="UTF-8"`
+
+	return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		user, password, ok := r.BasicAuth()
+		if !ok {
+			return &AuthError{Challenge: challenge}
+		}
+
+		expected, found := lookup(user)
+
+		// the hashes are compared, even when the user is not found, so that the
+		// time taken reveals neither the length of the password nor whether the
+		// user exists
+		given, want := sha256.Sum256([]byte(password)), sha256.Sum256([]byte(expected))
+		if subtle.ConstantTimeCompare(given[:], want[:]) != 1 || !found {
+			return &AuthError{Challenge: challenge}
+		}
+
+		return handler.ServeErrorHTTP(w, r.WithContext(context.WithValue(r.Context(), basicAuthKey{}, user)))
+	})
+}
+
+// BasicAuthUser returns the username of a request authenticated by BasicAuth,
+// or an empty string if it was not.
+func BasicAuthUser(r *http.Request) string {
+	user, _ := r.Context().Value(basicAuthKey{}).(string)
+	return user
+}
+
+// authKey is the metadata key of the flag given by RequireAuth.
+const authKey = "auth"
+
+// RequireAuth sets whether the route requires requests to be authenticated by
+// BearerAuth, overriding its Required field. It is stored as metadata, so is
+// listed by Routes.
+func RequireAuth(required bool) Option {
+	return Tag(authKey, required)
+}
+
+// A TokenValidator validates bearer tokens, such as JWTs, returning the claims
+// they make or an error if the token is not valid. The router does not
+// interpret tokens or claims itself.
+type TokenValidator interface {
+	ValidateToken(ctx context.Context, token string) (claims any, err error)
+}
+
+type claimsKey struct{}
+
+// BearerAuth authenticates requests with a bearer token in the Authorization
+// header, as described by RFC 6750. It is added to a Router with BeforeRequest,
+// and enforces authentication for routes that require it:
+//
+//   auth := &route.BearerAuth{Validator: jwtValidator, Realm: "api"}
+//   router.BeforeRequest(auth.Before)
+//
+//   router.Handle("GET /posts", listPosts)
+//   router.Handle("POST /posts", createPost, route.RequireAuth(true))
+//
+// Any token given is validated, whether or not the route requires one, and the
+// claims are made available to the handler with Claims. Requests for routes that
+// require a token without one, or with one that is not valid, are passed to
+// ErrorHandler with an AuthError. Requests for other routes with a token that is
+// not valid are handled as if they gave none.
+type BearerAuth struct {
+	// Validator validates the tokens.
+	Validator TokenValidator
+
+	// Realm is given in the challenge sent to unauthenticated requests.
+	Realm string
+
+	// Required sets whether routes without the RequireAuth option require
+	// authentication.
+	Required bool
+
+	// ErrorHandler responds to requests that are not authenticated. By default
+	// the response is written as by the router's default ErrorHandler.
+	ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// Before authenticates the request, responding with an error if it is not
+// authenticated and the route requires it to be. It is a BeforeRequestFunc.
+func (a *BearerAuth) Before(w http.ResponseWriter, r *http.Request) (*http.Request, bool) {
+	required, ok := MetaValue[bool](r, authKey)
+	if !ok {
+		required = a.Required
+	}
+
+	challenge := "Bearer realm=" + strconv.Quote(a.Realm)
+
+	token, ok := bearerToken(r)
+	if !ok {
+		if required {
+			a.error(w, r, &AuthError{Challenge: challenge})
+			return r, false
+		}
+		return r, true
+	}
+
+	claims, err := a.Validator.ValidateToken(r.Context(), token)
+	if err != nil {
+		if required {
+			a.error(w, r, &AuthError{Challenge: challenge + `, error="invalid_token"`, Err: err})
+			return r, false
+		}
+		return r, true
+	}
+
+	return r.WithContext(context.WithValue(r.Context(), claimsKey{}, claims)), true
+}
+
+func (a *BearerAuth) error(w http.ResponseWriter, r *http.Request, err error) {
+	if a.ErrorHandler != nil {
+		a.ErrorHandler(w, r, err)
+		return
+	}
+
+	code, msg := errorResponse(w, err)
+	if msg == "" {
+		msg = http.StatusText(code)
+	}
+	http.Error(w, msg, code)
+}
+
+// bearerToken returns the token given in the Authorization header of the
+// request, if there is one.
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// Claims returns the claims of the token a request was authenticated with by
+// BearerAuth, if there was one and they are a T.
+func Claims[T any](r *http.Request) (T, bool) {
+	claims, ok := r.Context().Value(claimsKey{}).(T)
+	return claims, ok
+}
@@ -0,0 +1,152 @@
+package route
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBasicAuth(t *testing.T) {
+	var user string
+
+	router := New()
+	router.Handle("/admin/*path", BasicAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user = BasicAuthUser(r)
+	}), "admin area", func(user string) (string, bool) {
+		password, ok := map[string]string{"alice": "secret", "blank": ""}[user]
+		return password, ok
+	}))
+
+	cases := []struct {
+		user, password string
+		auth           bool
+		code           int
+	}{
+		{"", "", false, 401},
+		{"alice", "secret", true, 200},
+		{"alice", "wrong", true, 401},
+		{"alice", "secret2", true, 401},
+		{"bob", "secret", true, 401},
+		{"bob", "", true, 401},
+		{"blank", "", true, 200},
+	}
+
+	for _, tc := range cases {
+		user = ""
+		r := httptest.NewRequest("GET", "/admin/x", nil)
+		if tc.auth {
+			r.SetBasicAuth(tc.user, tc.password)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+
+		assert.Equal(t, tc.code, w.Code, tc.user+":"+tc.password)
+		if tc.code == 401 {
+			assert.Equal(t, `Basic realm="admin area", charset="UTF-8"`, w.Header().Get("WWW-Authenticate"))
+			assert.Equal(t, "", user)
+		} else {
+			assert.Equal(t, tc.user, user)
+		}
+	}
+}
+
+func TestAuthError(t *testing.T) {
+	var err error = &AuthError{Challenge: `Basic realm="x"`, Err: errors.New("expired")}
+
+	var coder StatusCoder
+	assert.True(t, errors.As(err, &coder))
+	assert.Equal(t, 401, coder.StatusCode())
+	assert.Equal(t, "route: unauthorized: expired", err.Error())
+
+	w := httptest.NewRecorder()
+	ProblemHandler(w, httptest.NewRequest("GET", "/", nil), err)
+	assert.Equal(t, 401, w.Code)
+	assert.Equal(t, `Basic realm="x"`, w.Header().Get("WWW-Authenticate"))
+}
+
+type testValidator map[string]string
+
+func (v testValidator) ValidateToken(ctx context.Context, token string) (any, error) {
+	if subject, ok := v[token]; ok {
+		return subject, nil
+	}
+	return nil, errors.New("unknown token")
+}
+
+func TestBearerAuth(t *testing.T) {
+	var subject string
+	var hasClaims bool
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		subject, hasClaims = Claims[string](r)
+	}
+
+	auth := &BearerAuth{Validator: testValidator{"good": "alice"}, Realm: "api"}
+
+	router := New()
+	router.BeforeRequest(auth.Before)
+	router.HandleFunc("GET /posts", handler)
+	router.HandleFunc("POST /posts", handler, RequireAuth(true))
+
+	cases := []struct {
+		method, authorization string
+		code                  int
+		challenge, subject    string
+	}{
+		{"GET", "", 200, "", ""},
+		{"GET", "Bearer good", 200, "", "alice"},
+		{"GET", "Bearer bad", 200, "", ""},
+		{"GET", "Basic abc", 200, "", ""},
+		{"POST", "", 401, `Bearer realm="api"`, ""},
+		{"POST", "bearer good", 200, "", "alice"},
+		{"POST", "Bearer ", 401, `Bearer realm="api"`, ""},
+		{"POST", "Bearer bad", 401, `Bearer realm="api", error="invalid_token"`, ""},
+	}
+
+	for _, tc := range cases {
+		subject, hasClaims = "", false
+		r := httptest.NewRequest(tc.method, "/posts", nil)
+		if tc.authorization != "" {
+			r.Header.Set("Authorization", tc.authorization)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+
+		msg := tc.method + " " + tc.authorization
+		assert.Equal(t, tc.code, w.Code, msg)
+		assert.Equal(t, tc.challenge, w.Header().Get("WWW-Authenticate"), msg)
+		assert.Equal(t, tc.subject, subject, msg)
+		assert.Equal(t, tc.subject != "", hasClaims, msg)
+	}
+}
+
+func TestBearerAuthRequired(t *testing.T) {
+	var handled error
+	auth := &BearerAuth{
+		Validator: testValidator{},
+		Required:  true,
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			handled = err
+			w.WriteHeader(418)
+		},
+	}
+
+	router := New()
+	router.BeforeRequest(auth.Before)
+	router.HandleFunc("/private", func(w http.ResponseWriter, r *http.Request) {})
+	router.HandleFunc("/public", func(w http.ResponseWriter, r *http.Request) {}, RequireAuth(false))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/private", nil))
+	assert.Equal(t, 418, w.Code)
+
+	var authErr *AuthError
+	assert.True(t, errors.As(handled, &authErr))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/public", nil))
+	assert.Equal(t, 200, w.Code)
+}
@@ -0,0 +1,243 @@
+package route
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// A Source is a part of the request that Bind takes values from.
+type Source int
+
+const (
+	// FromBody decodes a JSON request body into the struct, using its json
+	// tags. Bodies of other types are ignored.
+	FromBody Source = iota + 1
+
+	// FromForm sets fields tagged `form:"name"` from the form values in the
+	// request body.
+	FromForm
+
+	// FromQuery sets fields tagged `query:"name"` from the query string.
+	FromQuery
+
+	// FromPath sets fields tagged `route:"name"` from the parameters of the
+	// route, as BindVars does.
+	FromPath
+)
+
+// A Validator checks the values of a struct after it is bound by Bind or
+// BindVars.
+type Validator interface {
+	Validate() error
+}
+
+// ValidationError is returned by Bind and BindVars when the bound struct is not
+// valid. It responds with 422 Unprocessable Entity when returned from a
+// handler.
+type ValidationError struct {
+	Err error
+}
+
+func (e *ValidationError) Error() string {
+	return "route: invalid input: " + e.Err.Error()
+}
+
+// StatusCode returns 422.
+func (e *ValidationError) StatusCode() int {
+	return http.StatusUnprocessableEntity
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// validators holds the function registered with RegisterValidator.
+var validators struct {
+	sync.RWMutex
+	fn func(any) error
+}
+
+// RegisterValidator registers a function to check every struct bound by Bind
+// and BindVars, such as the Struct method of a validation package. It replaces
+// any function previously registered.
+func RegisterValidator(fn func(v any) error) {
+	validators.Lock()
+	defer validators.Unlock()
+
+	validators.fn = fn
+}
+
+// validate checks the struct pointed to by dst with the registered validator,
+// then its Validate method if it is a Validator.
+func validate(dst any) error {
+	validators.RLock()
+	fn := validators.fn
+	validators.RUnlock()
+
+	if fn != nil {
+		if err := fn(dst); err != nil {
+			return &ValidationError{Err: err}
+		}
+	}
+
+	if v, ok := dst.(Validator); ok {
+		if err := v.Validate(); err != nil {
+			return &ValidationError{Err: err}
+		}
+	}
+
+	return nil
+}
+
+// defaultSources are used by Bind when it is not given any.
+var defaultSources = []Source{FromBody, FromForm, FromQuery, FromPath}
+
+// Bind sets the fields of the struct pointed to by dst from the request. Values
+// are taken from the sources in the order given, so that those from later
+// sources take precedence. If no sources are given values are taken from the
+// JSON body, then the form, the query string and the route parameters.
+//
+//   var input struct {
+//     ID    int64    `route:"id"`
+//     Tags  []string `query:"tag"`
+//     Title string   `json:"title" form:"title"`
+//   }
+//   if err := route.Bind(r, &input); err != nil {
+//     return err
+//   }
+//
+// Values are parsed as by Var, and slice fields are set from every value of a
+// query or form field. Missing query and form values leave their fields
+// unchanged, but missing route parameters are errors. If values can't be
+// parsed VarErrors is returned, or an HTTPError responding with 400 Bad
+// Request if the body can't be read. Bind panics if dst is not a pointer to a
+// struct.
+//
+// Once bound the struct is checked by the function registered with
+// RegisterValidator, and its Validate method if it is a Validator, with any
+// error returned as a ValidationError.
+func Bind(r *http.Request, dst any, sources ...Source) error {
+	v := structValue(dst, "Bind")
+
+	if len(sources) == 0 {
+		sources = defaultSources
+	}
+
+	var errs VarErrors
+	for _, source := range sources {
+		switch source {
+		case FromBody:
+			if err := bindBody(r, dst); err != nil {
+				return err
+			}
+		case FromForm:
+			if err := r.ParseForm(); err != nil {
+				return &HTTPError{Code: http.StatusBadRequest, Err: err}
+			}
+			errs = bindFields(v, "form", valuesGetter(r.PostForm), false, errs)
+		case FromQuery:
+			errs = bindFields(v, "query", valuesGetter(r.URL.Query()), false, errs)
+		case FromPath:
+			errs = bindFields(v, "route", varsGetter(r), true, errs)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return validate(dst)
+}
+
+// structValue returns the struct pointed to by dst, panicking if it is not a
+// pointer to a struct.
+func structValue(dst any, fn string) reflect.Value {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		panic("route: " + fn + " requires a pointer to a struct")
+	}
+
+	return v.Elem()
+}
+
+// bindBody decodes the body of the request into dst, if it is JSON.
+func bindBody(r *http.Request, dst any) error {
+	if r.Body == nil {
+		return nil
+	}
+
+	mediatype, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediatype != "application/json" && !strings.HasSuffix(mediatype, "+json") {
+		return nil
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(dst); err != nil && !errors.Is(err, io.EOF) {
+		return &HTTPError{Code: http.StatusBadRequest, Message: "invalid JSON body", Err: err}
+	}
+
+	return nil
+}
+
+// valuesGetter returns a function getting the values of a field from vs.
+func valuesGetter(vs map[string][]string) func(string) ([]string, bool) {
+	return func(name string) ([]string, bool) {
+		values, ok := vs[name]
+		return values, ok && len(values) > 0
+	}
+}
+
+// varsGetter returns a function getting the value of a parameter of the
+// request.
+func varsGetter(r *http.Request) func(string) ([]string, bool) {
+	ps := GetParams(r)
+
+	return func(name string) ([]string, bool) {
+		value, ok := ps.Get(name)
+		return []string{value}, ok
+	}
+}
+
+// bindFields sets each exported field of the struct v tagged with tag to the
+// values get returns for the name in the tag, appending an error to errs for
+// each field that can't be set. If required, fields without values are errors
+// too.
+func bindFields(v reflect.Value, tag string, get func(string) ([]string, bool), required bool, errs VarErrors) VarErrors {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		name := field.Tag.Get(tag)
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+
+		values, ok := get(name)
+		if !ok {
+			if required {
+				errs = append(errs, &VarError{Name: name, Err: ErrMissingVar})
+			}
+			continue
+		}
+
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 {
+			slice := reflect.MakeSlice(fv.Type(), len(values), len(values))
+			for j, value := range values {
+				if err := parseVar(slice.Index(j), value); err != nil {
+					errs = append(errs, &VarError{Name: name, Value: value, Err: err})
+				}
+			}
+			fv.Set(slice)
+			continue
+		}
+
+		if err := parseVar(fv, values[0]); err != nil {
+			errs = append(errs, &VarError{Name: name, Value: values[0], Err: err})
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,131 @@
+package route
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type bindInput struct {
+	ID    int64    `route:"id"`
+	Tags  []string `query:"tag"`
+	Page  int      `query:"page"`
+	Title string   `json:"title" form:"title" query:"title"`
+	Draft bool     `json:"draft" form:"draft"`
+}
+
+func TestBind(t *testing.T) {
+	var input bindInput
+	var err error
+
+	router := New()
+	router.HandleFunc("/posts/:id", func(w http.ResponseWriter, r *http.Request) {
+		input = bindInput{}
+		err = Bind(r, &input)
+	})
+
+	serve := func(target, contentType, body string) {
+		r := httptest.NewRequest("POST", target, strings.NewReader(body))
+		if contentType != "" {
+			r.Header.Set("Content-Type", contentType)
+		}
+		router.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	serve("/posts/5?tag=a&tag=b&page=2", "application/json", `{"title": "Hello", "draft": true}`)
+	assert.Nil(t, err)
+	assert.Equal(t, bindInput{ID: 5, Tags: []string{"a", "b"}, Page: 2, Title: "Hello", Draft: true}, input)
+
+	serve("/posts/5?title=Query", "application/x-www-form-urlencoded", "title=Form&draft=1")
+	assert.Nil(t, err)
+	assert.Equal(t, bindInput{ID: 5, Title: "Query", Draft: true}, input)
+
+	serve("/posts/5", "application/json", `{"title": `)
+	var httpErr *HTTPError
+	assert.True(t, errors.As(err, &httpErr))
+	assert.Equal(t, 400, httpErr.Code)
+
+	serve("/posts/5?page=two&tag=x", "", "")
+	assert.Equal(t, `route: parameter page has invalid value "two": invalid syntax`, err.Error())
+	assert.Equal(t, []string{"x"}, input.Tags)
+}
+
+func TestBindSources(t *testing.T) {
+	var input bindInput
+	var err error
+
+	router := New()
+	router.HandleFunc("/posts/:id", func(w http.ResponseWriter, r *http.Request) {
+		input = bindInput{}
+		err = Bind(r, &input, FromQuery, FromForm)
+	})
+
+	r := httptest.NewRequest("POST", "/posts/5?title=Query", strings.NewReader("title=Form"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	router.ServeHTTP(httptest.NewRecorder(), r)
+
+	assert.Nil(t, err)
+	assert.Equal(t, bindInput{Title: "Form"}, input)
+
+	assert.Panics(t, func() {
+		Bind(r, input)
+	})
+}
+
+type validatedInput struct {
+	Title string `query:"title"`
+	Page  int    `route:"page"`
+}
+
+func (v *validatedInput) Validate() error {
+	if v.Title == "" {
+		return errors.New("title is required")
+	}
+	return nil
+}
+
+func TestBindValidate(t *testing.T) {
+	router := New()
+	router.HandleFunc("/posts/:page", func(w http.ResponseWriter, r *http.Request) error {
+		var input validatedInput
+		return Bind(r, &input)
+	})
+	router.HandleFunc("/pages/:page", func(w http.ResponseWriter, r *http.Request) error {
+		var input validatedInput
+		return BindVars(r, &input)
+	})
+
+	serve := func(target string) int {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+		return w.Code
+	}
+
+	assert.Equal(t, 200, serve("/posts/1?title=Hello"))
+	assert.Equal(t, 422, serve("/posts/1"))
+	assert.Equal(t, 400, serve("/posts/first"))
+	assert.Equal(t, 422, serve("/pages/1"))
+
+	RegisterValidator(func(v any) error {
+		if input, ok := v.(*validatedInput); ok && input.Page > 10 {
+			return errors.New("page out of range")
+		}
+		return nil
+	})
+	defer RegisterValidator(nil)
+
+	assert.Equal(t, 422, serve("/posts/11?title=Hello"))
+	assert.Equal(t, 200, serve("/posts/10?title=Hello"))
+
+	var input validatedInput
+	r := httptest.NewRequest("GET", "/", nil)
+	err := Bind(r, &input, FromQuery)
+
+	var verr *ValidationError
+	assert.True(t, errors.As(err, &verr))
+	assert.Equal(t, "route: invalid input: title is required", err.Error())
+}
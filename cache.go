@@ -0,0 +1,220 @@
+package route
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cacheTTLKey is the metadata key of the duration given by CacheTTL.
+const cacheTTLKey = "cache.ttl"
+
+// CacheTTL sets how long responses for the route are kept by a ResponseCache.
+// It is stored as metadata, so is listed by Routes.
+func CacheTTL(ttl time.Duration) Option {
+	return Tag(cacheTTLKey, ttl)
+}
+
+// A CachedResponse is a response stored by a ResponseCache.
+type CachedResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+
+	// Stored is the time the response was stored.
+	Stored time.Time
+}
+
+// A CacheStore stores responses for a ResponseCache. It must be safe to call
+// from multiple goroutines.
+type CacheStore interface {
+	// Get returns the response stored for the key, if it has not expired.
+	Get(key string) (*CachedResponse, bool)
+
+	// Set stores the response for the key, to expire after the ttl.
+	Set(key string, resp *CachedResponse, ttl time.Duration)
+}
+
+// MemoryStore is a CacheStore holding responses in memory. The zero value is
+// ready to use.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	sets    int
+}
+
+type memoryEntry struct {
+	resp    *CachedResponse
+	expires time.Time
+}
+
+// Get returns the response stored for the key, if it has not expired.
+func (s *MemoryStore) Get(key string) (*CachedResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(s.entries, key)
+		return nil, false
+	}
+
+	return entry.resp, true
+}
+
+// Set stores the response for the key, to expire after the ttl. Expired
+// responses are removed as further responses are stored.
+func (s *MemoryStore) Set(key string, resp *CachedResponse, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.entries == nil {
+		s.entries = map[string]memoryEntry{}
+	}
+
+	now := time.Now()
+	s.sets++
+	if s.sets%sweepEvery == 0 {
+		for k, entry := range s.entries {
+			if now.After(entry.expires) {
+				delete(s.entries, k)
+			}
+		}
+	}
+
+	s.entries[key] = memoryEntry{resp: resp, expires: now.Add(ttl)}
+}
+
+// ResponseCache stores the responses to GET requests for routes given the
+// CacheTTL option, and serves later requests for the same route, parameters and
+// query from the store until they expire:
+//
+//   cache := &route.ResponseCache{Vary: []string{"Accept-Language"}}
+//   router.Handle("GET /reports/:id", reportHandler, route.CacheTTL(time.Minute))
+//
+//   http.ListenAndServe(":8080", cache.Cache(router))
+//
+// Responses have an X-Cache header of "HIT" or "MISS", and cached responses an
+// Age header. Only 200 OK responses are stored, and not those with a Set-Cookie
+// header or a Cache-Control header of "no-store" or "private". Requests with an
+// Authorization header are neither served from nor stored in the cache.
+type ResponseCache struct {
+	// Store holds the responses, by default a MemoryStore.
+	Store CacheStore
+
+	// Vary lists the request headers whose values are part of the cache key, so
+	// that requests that differ in them are cached separately.
+	Vary []string
+
+	once sync.Once
+}
+
+// Cache returns a handler that serves requests with the router, caching the
+// responses for routes given the CacheTTL option. The route is found for each
+// GET request before it is passed to the router, to look up its key.
+func (c *ResponseCache) Cache(router *Router) http.Handler {
+	c.once.Do(func() {
+		if c.Store == nil {
+			c.Store = &MemoryStore{}
+		}
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.Header.Get("Authorization") != "" {
+			router.ServeHTTP(w, r)
+			return
+		}
+
+		match, err := router.MatchRequest(r)
+		ttl, ok := match.Meta[cacheTTLKey].(time.Duration)
+		if err != nil || !ok || ttl <= 0 {
+			router.ServeHTTP(w, r)
+			return
+		}
+
+		key := c.key(r, match)
+
+		if resp, ok := c.Store.Get(key); ok {
+			for k, vs := range resp.Header {
+				w.Header()[k] = append([]string(nil), vs...)
+			}
+			w.Header().Set("Age", strconv.Itoa(int(time.Since(resp.Stored).Seconds())))
+			w.Header().Set("X-Cache", "HIT")
+			w.WriteHeader(resp.Status)
+			w.Write(resp.Body)
+			return
+		}
+
+		w.Header().Set("X-Cache", "MISS")
+		rec := &cacheRecorder{responseRecorder: responseRecorder{ResponseWriter: w}}
+		router.ServeHTTP(rec, r)
+
+		if rec.code() == http.StatusOK && cacheable(w.Header()) {
+			header := w.Header().Clone()
+			header.Del("X-Cache")
+			c.Store.Set(key, &CachedResponse{
+				Status: http.StatusOK,
+				Header: header,
+				Body:   rec.body,
+				Stored: time.Now(),
+			}, ttl)
+		}
+	})
+}
+
+// key returns the cache key for the request matching the route.
+func (c *ResponseCache) key(r *http.Request, match RouteMatch) string {
+	var b strings.Builder
+	b.WriteString(match.Host + match.Pattern)
+
+	names := make([]string, 0, len(match.Vars))
+	for name := range match.Vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b.WriteString("\x00" + name + "=" + match.Vars[name])
+	}
+
+	b.WriteString("\x00?" + r.URL.Query().Encode())
+
+	for _, header := range c.Vary {
+		b.WriteString("\x00" + header + ":" + strings.Join(r.Header.Values(header), ","))
+	}
+
+	return b.String()
+}
+
+// cacheable returns true if a response with the header may be stored.
+func cacheable(header http.Header) bool {
+	if len(header.Values("Set-Cookie")) > 0 {
+		return false
+	}
+
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		switch strings.ToLower(strings.TrimSpace(directive)) {
+		case "no-store", "private":
+			return false
+		}
+	}
+
+	return true
+}
+
+// cacheRecorder records the body of the response written to it.
+type cacheRecorder struct {
+	responseRecorder
+	body []byte
+}
+
+func (w *cacheRecorder) Write(p []byte) (int, error) {
+	n, err := w.responseRecorder.Write(p)
+	w.body = append(w.body, p[:n]...)
+	return n, err
+}
@@ -0,0 +1,93 @@
+package route
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponseCache(t *testing.T) {
+	calls := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(Vars(r)["id"] + " " + strconv.Itoa(calls)))
+	}
+
+	router := New()
+	router.HandleFunc("GET /reports/:id", handler, CacheTTL(time.Minute))
+	router.HandleFunc("GET /live/:id", handler)
+	router.HandleFunc("GET /private/:id", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "private, max-age=60")
+		handler(w, r)
+	}, CacheTTL(time.Minute))
+	router.HandleFunc("GET /missing/:id", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(404)
+	}, CacheTTL(time.Minute))
+
+	cache := &ResponseCache{Vary: []string{"Accept-Language"}}
+	server := cache.Cache(router)
+
+	request := func(path string, header ...string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", path, nil)
+		for i := 0; i < len(header); i += 2 {
+			r.Header.Set(header[i], header[i+1])
+		}
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, r)
+		return w
+	}
+
+	w := request("/reports/1")
+	assert.Equal(t, "1 1", w.Body.String())
+	assert.Equal(t, "MISS", w.Header().Get("X-Cache"))
+
+	w = request("/reports/1")
+	assert.Equal(t, "1 1", w.Body.String())
+	assert.Equal(t, "HIT", w.Header().Get("X-Cache"))
+	assert.Equal(t, "0", w.Header().Get("Age"))
+	assert.Equal(t, "text/plain", w.Header().Get("Content-Type"))
+
+	assert.Equal(t, "2 2", request("/reports/2").Body.String())
+	assert.Equal(t, "1 3", request("/reports/1?page=2").Body.String())
+	assert.Equal(t, "1 3", request("/reports/1?page=2").Body.String())
+	assert.Equal(t, "1 4", request("/reports/1", "Accept-Language", "fr").Body.String())
+	assert.Equal(t, "1 5", request("/reports/1", "Authorization", "Bearer x").Body.String())
+
+	assert.Equal(t, "1 6", request("/live/1").Body.String())
+	assert.Equal(t, "1 7", request("/live/1").Body.String())
+	assert.Equal(t, "", request("/live/1").Header().Get("X-Cache"))
+
+	assert.Equal(t, "1 9", request("/private/1").Body.String())
+	assert.Equal(t, "1 10", request("/private/1").Body.String())
+
+	assert.Equal(t, "MISS", request("/missing/1").Header().Get("X-Cache"))
+	assert.Equal(t, "MISS", request("/missing/1").Header().Get("X-Cache"))
+
+	r := httptest.NewRequest("POST", "/reports/1", nil)
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, r)
+	assert.Equal(t, 405, w.Code)
+}
+
+func TestMemoryStore(t *testing.T) {
+	var store MemoryStore
+	resp := &CachedResponse{Status: 200}
+
+	store.Set("a", resp, time.Minute)
+	store.Set("b", resp, -time.Minute)
+
+	got, ok := store.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, resp, got)
+
+	_, ok = store.Get("b")
+	assert.False(t, ok)
+
+	_, ok = store.Get("c")
+	assert.False(t, ok)
+}
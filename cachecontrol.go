@@ -0,0 +1,157 @@
+package route
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A CachePolicy describes how responses for a route may be cached, by browsers
+// and by shared caches such as CDNs.
+type CachePolicy struct {
+	// MaxAge is how long responses are fresh for.
+	MaxAge time.Duration
+
+	// SharedMaxAge, if set, is how long responses are fresh for in shared
+	// caches, overriding MaxAge.
+	SharedMaxAge time.Duration
+
+	// StaleWhileRevalidate is how long stale responses may be used while they
+	// are revalidated in the background.
+	StaleWhileRevalidate time.Duration
+
+	// Public allows responses to be stored by shared caches, even if they would
+	// not otherwise be, and Private prevents it.
+	Public  bool
+	Private bool
+
+	// NoCache requires responses to be revalidated before each use, and NoStore
+	// prevents them being stored at all.
+	NoCache bool
+	NoStore bool
+
+	// Immutable marks responses as never changing while fresh.
+	Immutable bool
+
+	// SurrogateMaxAge, if set, is sent in a Surrogate-Control header, for CDNs
+	// that read it in place of Cache-Control.
+	SurrogateMaxAge time.Duration
+}
+
+// String returns the value of the Cache-Control header for the policy.
+func (p CachePolicy) String() string {
+	var directives []string
+	add := func(set bool, directive string) {
+		if set {
+			directives = append(directives, directive)
+		}
+	}
+
+	add(p.Public, "public")
+	add(p.Private, "private")
+	add(p.NoCache, "no-cache")
+	add(p.NoStore, "no-store")
+	add(p.MaxAge > 0, "max-age="+seconds(p.MaxAge))
+	add(p.SharedMaxAge > 0, "s-maxage="+seconds(p.SharedMaxAge))
+	add(p.StaleWhileRevalidate > 0, "stale-while-revalidate="+seconds(p.StaleWhileRevalidate))
+	add(p.Immutable, "immutable")
+
+	return strings.Join(directives, ", ")
+}
+
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(d/time.Second), 10)
+}
+
+// Caching sets the Cache-Control, and if given Surrogate-Control, headers of
+// successful responses for the route to those for the policy, so that caching
+// is declared where routes are rather than in each handler:
+//
+//   router.Handle("GET /products/:id", productHandler, route.Caching(route.CachePolicy{
+//     Public:          true,
+//     MaxAge:          time.Minute,
+//     SurrogateMaxAge: time.Hour,
+//   }))
+//   router.Handle("GET /account", accountHandler, route.Caching(route.CachePolicy{NoStore: true}))
+//
+// The headers are only set if the handler has not set them itself, so it can
+// override the policy for a response, and not for responses with a 4xx or 5xx
+// status.
+func Caching(policy CachePolicy) Option {
+	return func(e *entry) {
+		e.cacheControl = policy.String()
+		e.surrogateControl = ""
+		if policy.SurrogateMaxAge > 0 {
+			e.surrogateControl = "max-age=" + seconds(policy.SurrogateMaxAge)
+		}
+	}
+}
+
+// cachingWriter sets the caching headers of a route before the response is
+// written, unless the handler has set them.
+type cachingWriter struct {
+	http.ResponseWriter
+	cacheControl     string
+	surrogateControl string
+	wroteHeader      bool
+}
+
+func (w *cachingWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+
+		if code < 400 {
+			w.setHeaders()
+		}
+	}
+
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// setHeaders sets the caching headers that the handler has not set.
+func (w *cachingWriter) setHeaders() {
+	header := w.Header()
+	if _, ok := header["Cache-Control"]; !ok && w.cacheControl != "" {
+		header.Set("Cache-Control", w.cacheControl)
+	}
+	if _, ok := header["Surrogate-Control"]; !ok && w.surrogateControl != "" {
+		header.Set("Surrogate-Control", w.surrogateControl)
+	}
+}
+
+func (w *cachingWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush sends any buffered data to the client, if the ResponseWriter supports
+// it.
+func (w *cachingWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack lets the caller take over the connection, if the ResponseWriter, or
+// any it wraps, supports it. Nothing is written to the response afterwards.
+func (w *cachingWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	w.wroteHeader = true
+	return conn, rw, nil
+}
+
+// Unwrap returns the ResponseWriter, for use by http.ResponseController.
+func (w *cachingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,123 @@
+package route
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachePolicyString(t *testing.T) {
+	cases := []struct {
+		policy   CachePolicy
+		expected string
+	}{
+		{CachePolicy{}, ""},
+		{CachePolicy{NoStore: true}, "no-store"},
+		{CachePolicy{Public: true, MaxAge: time.Minute, SharedMaxAge: time.Hour}, "public, max-age=60, s-maxage=3600"},
+		{CachePolicy{Private: true, NoCache: true}, "private, no-cache"},
+		{CachePolicy{MaxAge: 365 * 24 * time.Hour, Immutable: true}, "max-age=31536000, immutable"},
+		{CachePolicy{MaxAge: time.Minute, StaleWhileRevalidate: 30 * time.Second}, "max-age=60, stale-while-revalidate=30"},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.expected, tc.policy.String())
+	}
+}
+
+func TestCaching(t *testing.T) {
+	router := New()
+	api := router.Group("/api", Caching(CachePolicy{Public: true, MaxAge: time.Minute, SurrogateMaxAge: time.Hour}))
+	api.HandleFunc("/products/:id", func(w http.ResponseWriter, r *http.Request) {
+		switch Vars(r)["id"] {
+		case "override":
+			w.Header().Set("Cache-Control", "no-cache")
+		case "missing":
+			w.WriteHeader(404)
+		case "empty":
+			return
+		}
+		w.Write([]byte("product"))
+	})
+	api.HandleFunc("/account", func(w http.ResponseWriter, r *http.Request) {}, Caching(CachePolicy{NoStore: true}))
+	router.HandleFunc("/plain", func(w http.ResponseWriter, r *http.Request) {})
+
+	request := func(path string) http.Header {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w.Header()
+	}
+
+	h := request("/api/products/1")
+	assert.Equal(t, "public, max-age=60", h.Get("Cache-Control"))
+	assert.Equal(t, "max-age=3600", h.Get("Surrogate-Control"))
+
+	h = request("/api/products/override")
+	assert.Equal(t, "no-cache", h.Get("Cache-Control"))
+	assert.Equal(t, "max-age=3600", h.Get("Surrogate-Control"))
+
+	h = request("/api/products/missing")
+	assert.Equal(t, "", h.Get("Cache-Control"))
+
+	h = request("/api/account")
+	assert.Equal(t, "no-store", h.Get("Cache-Control"))
+	assert.Equal(t, "", h.Get("Surrogate-Control"))
+
+	h = request("/plain")
+	assert.Equal(t, "", h.Get("Cache-Control"))
+}
+
+// hijackRecorder is a ResponseRecorder that can be hijacked, recording any
+// calls to WriteHeader.
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+	codes    []int
+}
+
+func (w *hijackRecorder) WriteHeader(code int) {
+	w.codes = append(w.codes, code)
+	w.ResponseRecorder.WriteHeader(code)
+}
+
+func (w *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.hijacked = true
+	server, client := net.Pipe()
+	client.Close()
+	return server, bufio.NewReadWriter(bufio.NewReader(server), bufio.NewWriter(server)), nil
+}
+
+func TestCachingDoesNotWriteAfterHijack(t *testing.T) {
+	router := New()
+	router.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+		conn, _, err := http.NewResponseController(w).Hijack()
+		if assert.Nil(t, err) {
+			conn.Close()
+		}
+	}, Caching(CachePolicy{MaxAge: time.Minute}))
+
+	w := &hijackRecorder{ResponseRecorder: httptest.NewRecorder()}
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/ws", nil))
+
+	assert.True(t, w.hijacked)
+	assert.Empty(t, w.codes)
+}
+
+func TestCachingWithIgnoredError(t *testing.T) {
+	router := New()
+	router.ErrorHandler = IgnoreErrors
+	router.HandleFunc("/fail", func(w http.ResponseWriter, r *http.Request) error {
+		return errors.New("failed")
+	}, Caching(CachePolicy{MaxAge: time.Minute}))
+
+	w := &hijackRecorder{ResponseRecorder: httptest.NewRecorder()}
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/fail", nil))
+
+	assert.Empty(t, w.codes)
+	assert.Equal(t, "", w.Header().Get("Cache-Control"))
+}
@@ -0,0 +1,72 @@
+package route
+
+import (
+	"context"
+	"net/http"
+)
+
+type fallthroughKey struct{}
+
+// Chain returns a handler that layers independently built handlers, passing
+// each request to the first and, when no route of a Router matches it, on to
+// the next in turn:
+//
+//   http.ListenAndServe(":8080", route.Chain(appRouter, legacyMux, staticRouter))
+//
+// A request falls through in place of the NotFoundHandler of the Router, but a
+// handler set for a Group with NotFound is still used. Handlers other than a
+// Router, such as legacyMux above, can't fall through so end the chain for the
+// requests they are passed. The next handler is passed the request as it was
+// received by the chain, so has its original path even if an earlier handler
+// changed it, as when a Router is wrapped by http.StripPrefix or Locales. If no
+// handler serves the request the NotFoundHandler of the last Router is used.
+func Chain(handlers ...http.Handler) http.Handler {
+	if len(handlers) == 0 {
+		return http.NotFoundHandler()
+	}
+
+	return chain(handlers)
+}
+
+type chain []http.Handler
+
+func (c chain) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.serve(w, r, 0)
+}
+
+// serve passes the request to the i-th handler, with the rest of the chain to
+// fall through to.
+func (c chain) serve(w http.ResponseWriter, r *http.Request, i int) {
+	if i == len(c)-1 {
+		c[i].ServeHTTP(w, r)
+		return
+	}
+
+	f := &chainLink{
+		next: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			c.serve(w, r, i+1)
+		}),
+	}
+	f.router, _ = c[i].(*Router)
+
+	c[i].ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), fallthroughKey{}, f)))
+}
+
+// chainLink is the rest of a Chain that a request falls through to.
+type chainLink struct {
+	next http.Handler
+
+	// router is the Router in the chain, if the handler is one. Only it can
+	// fall through, so that routers it passes requests to do not. Otherwise any
+	// router the request is passed to can.
+	router *Router
+}
+
+// fallthroughHandler returns the handler the request falls through to when no
+// route of the router matches it, or nil if it is not being served by a Chain.
+func (r *Router) fallthroughHandler(req *http.Request) http.Handler {
+	if f, ok := req.Context().Value(fallthroughKey{}).(*chainLink); ok && (f.router == nil || f.router == r) {
+		return f.next
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package route
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChain(t *testing.T) {
+	respond := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(body + " " + r.URL.Path))
+		}
+	}
+
+	nested := New()
+	nested.Get("/api/users", respond("nested"))
+
+	app := New()
+	app.Get("/users", respond("app"))
+	app.Handle("/api/*path", nested)
+	app.Group("/admin").NotFound(respond("admin not found"))
+
+	legacy := http.NewServeMux()
+	legacy.Handle("/legacy/", respond("legacy"))
+
+	static := New()
+	static.Get("/site.css", respond("static"))
+	static.NotFoundHandler = respond("not found")
+
+	// strip wraps app, passing on requests for paths without the prefix too
+	strip := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u := *r.URL
+		u.Path = strings.TrimPrefix(u.Path, "/app")
+		r = r.WithContext(r.Context())
+		r.URL = &u
+		app.ServeHTTP(w, r)
+	})
+
+	handler := Chain(strip, static)
+
+	testCases := map[string]string{
+		"/app/users":     "app /users",
+		"/app/admin/x":   "admin not found /admin/x",
+		"/app/api/users": "nested /api/users",
+		"/app/api/other": "not found /app/api/other",
+		"/site.css":      "static /site.css",
+		"/missing":       "not found /missing",
+	}
+
+	for path, body := range testCases {
+		t.Run(path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+
+			assert.Equal(t, body, rec.Body.String())
+		})
+	}
+
+	handler = Chain(app, legacy, static)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/legacy/page", nil))
+	assert.Equal(t, "legacy /legacy/page", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/site.css", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/api/other", nil))
+	assert.Equal(t, "404 page not found\n", rec.Body.String())
+}
+
+func TestChainNested(t *testing.T) {
+	first := New()
+	second := New()
+	third := New()
+	third.Get("/third", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("third"))
+	}))
+
+	rec := httptest.NewRecorder()
+	Chain(Chain(first, second), third).ServeHTTP(rec, httptest.NewRequest("GET", "/third", nil))
+	assert.Equal(t, "third", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	Chain().ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,132 @@
+package route
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// Networks is a list of IP networks.
+type Networks []netip.Prefix
+
+// PrivateNetworks are the loopback, private (RFC 1918) and unique local IPv6
+// networks.
+var PrivateNetworks = ParseNetworks(
+	"127.0.0.0/8", "10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16",
+	"::1/128", "fc00::/7",
+)
+
+// ParseNetworks parses networks in CIDR notation, as "10.0.0.0/8", or single
+// addresses, as "10.1.2.3". It panics if any are invalid.
+func ParseNetworks(cidrs ...string) Networks {
+	networks := make(Networks, len(cidrs))
+	for i, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			addr, err := netip.ParseAddr(cidr)
+			if err != nil {
+				panic("route: invalid network: " + err.Error())
+			}
+			networks[i] = netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen())
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			panic("route: invalid network: " + err.Error())
+		}
+		networks[i] = prefix.Masked()
+	}
+
+	return networks
+}
+
+// Contains returns true if the address is in one of the networks.
+func (n Networks) Contains(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range n {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ClientIP returns the address of the client that made the request. If the
+// request was received from one of the trusted proxies the address is taken
+// from the X-Forwarded-For header, skipping any trusted proxies it lists, or
+// failing that from X-Real-IP. Otherwise these headers are ignored, as they can
+// be set by anyone. It returns the zero Addr if the address is not valid.
+func ClientIP(r *http.Request, trusted Networks) netip.Addr {
+	addr := parseAddr(remoteIP(r))
+	if !addr.IsValid() || !trusted.Contains(addr) {
+		return addr
+	}
+
+	if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		hops := strings.Split(strings.Join(forwarded, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := parseAddr(strings.TrimSpace(hops[i]))
+			if !hop.IsValid() {
+				break
+			}
+			addr = hop
+			if !trusted.Contains(hop) {
+				break
+			}
+		}
+		return addr
+	}
+
+	if real := parseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); real.IsValid() {
+		return real
+	}
+
+	return addr
+}
+
+// parseAddr parses an address, which may have a port, returning the zero Addr
+// if it is not valid.
+func parseAddr(s string) netip.Addr {
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Addr{}
+	}
+	return addr.Unmap()
+}
+
+// FromNetworks restricts the route to requests from clients in one of the
+// allowed networks, with the address of the client found by ClientIP trusting
+// the headers set by proxies in trusted, which may be nil:
+//
+//   router.Handle("/internal/*path", internalHandler, route.FromNetworks(route.PrivateNetworks, nil))
+//
+// When the client is not in the networks other routes for the same path are
+// tried, if none match NotFoundHandler is used. Use RestrictNetworks to respond
+// with 403 Forbidden instead.
+func FromNetworks(allowed, trusted Networks) Option {
+	return When(func(r *http.Request) bool {
+		return allowed.Contains(ClientIP(r, trusted))
+	})
+}
+
+// RestrictNetworks returns a handler that passes requests from clients in one
+// of the allowed networks to h, and responds to others with 403 Forbidden. The
+// address of the client is found as for FromNetworks.
+//
+//   admin := route.RestrictNetworks(adminRouter, route.PrivateNetworks, nil)
+func RestrictNetworks(h http.Handler, allowed, trusted Networks) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !allowed.Contains(ClientIP(r, trusted)) {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
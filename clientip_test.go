@@ -0,0 +1,110 @@
+package route
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNetworks(t *testing.T) {
+	networks := ParseNetworks("10.1.2.3/8", "192.168.1.1", "2001:db8::/32")
+
+	assert.Equal(t, Networks{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("192.168.1.1/32"),
+		netip.MustParsePrefix("2001:db8::/32"),
+	}, networks)
+
+	assert.True(t, networks.Contains(netip.MustParseAddr("10.200.0.1")))
+	assert.True(t, networks.Contains(netip.MustParseAddr("::ffff:10.200.0.1")))
+	assert.True(t, networks.Contains(netip.MustParseAddr("192.168.1.1")))
+	assert.False(t, networks.Contains(netip.MustParseAddr("192.168.1.2")))
+	assert.False(t, networks.Contains(netip.Addr{}))
+
+	checkPanics(t, func() { ParseNetworks("10.0.0.0/33") })
+	checkPanics(t, func() { ParseNetworks("nope") })
+}
+
+func TestClientIP(t *testing.T) {
+	trusted := ParseNetworks("10.0.0.0/8")
+
+	cases := []struct {
+		remote, forwarded, real string
+		expected                string
+	}{
+		{"1.2.3.4:80", "", "", "1.2.3.4"},
+		{"1.2.3.4:80", "5.6.7.8", "9.9.9.9", "1.2.3.4"},
+		{"10.0.0.1:80", "", "", "10.0.0.1"},
+		{"10.0.0.1:80", "5.6.7.8", "", "5.6.7.8"},
+		{"10.0.0.1:80", "6.6.6.6, 5.6.7.8, 10.0.0.2", "", "5.6.7.8"},
+		{"10.0.0.1:80", "10.0.0.3, 10.0.0.2", "", "10.0.0.3"},
+		{"10.0.0.1:80", "junk, 10.0.0.2", "", "10.0.0.2"},
+		{"10.0.0.1:80", "", "5.6.7.8", "5.6.7.8"},
+		{"[::1]:80", "", "", "::1"},
+		{"nonsense", "", "", "invalid IP"},
+	}
+
+	for _, tc := range cases {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = tc.remote
+		if tc.forwarded != "" {
+			r.Header.Set("X-Forwarded-For", tc.forwarded)
+		}
+		if tc.real != "" {
+			r.Header.Set("X-Real-IP", tc.real)
+		}
+
+		assert.Equal(t, tc.expected, ClientIP(r, trusted).String(), tc.remote+" "+tc.forwarded)
+	}
+}
+
+func TestFromNetworks(t *testing.T) {
+	internal := &recordingHandler{}
+	public := &recordingHandler{}
+
+	router := New()
+	router.Handle("/status", internal, FromNetworks(PrivateNetworks, nil))
+	router.Handle("/status", public)
+	router.Handle("/internal/*path", internal, FromNetworks(PrivateNetworks, ParseNetworks("203.0.113.1")))
+
+	request := func(path, remote, forwarded string) int {
+		r := httptest.NewRequest("GET", path, nil)
+		r.RemoteAddr = remote
+		r.Header.Set("X-Forwarded-For", forwarded)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	internal.Used, public.Used = false, false
+	request("/status", "192.168.0.4:1234", "")
+	assert.True(t, internal.Used)
+	assert.False(t, public.Used)
+
+	internal.Used, public.Used = false, false
+	request("/status", "8.8.8.8:1234", "192.168.0.4")
+	assert.False(t, internal.Used)
+	assert.True(t, public.Used)
+
+	assert.Equal(t, 404, request("/internal/x", "8.8.8.8:1234", ""))
+	assert.Equal(t, 200, request("/internal/x", "203.0.113.1:1234", "10.0.0.5"))
+	assert.Equal(t, 404, request("/internal/x", "203.0.113.1:1234", "8.8.8.8"))
+}
+
+func TestRestrictNetworks(t *testing.T) {
+	handler := RestrictNetworks(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), PrivateNetworks, nil)
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, 200, w.Code)
+
+	r.RemoteAddr = "8.8.8.8:1234"
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, 403, w.Code)
+}
@@ -0,0 +1,247 @@
+// Command routegen writes a file registering the handlers of a package that
+// are annotated with the routes they handle, so that routes can be declared
+// next to their handlers:
+//
+//   //route:GET /users/:id name=user.show
+//   func showUser(w http.ResponseWriter, r *http.Request) error {
+//     ...
+//   }
+//
+// Each annotation gives a pattern, as passed to route.Router.Handle, optionally
+// followed by a name for the route. A handler may have more than one. Handlers
+// must be top-level functions with one of the signatures accepted by
+// route.Router.HandleFunc.
+//
+// It is intended to be run with go generate:
+//
+//   //go:generate go run hawx.me/code/route/cmd/routegen
+//
+// which writes routes_gen.go, containing a function that registers the routes:
+//
+//   func RegisterRoutes(r route.Registrar)
+//
+// Usage:
+//
+//   routegen [-dir .] [-o routes_gen.go] [-func RegisterRoutes]
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const annotation = "//route:"
+
+// A routeDecl is a route declared by an annotation.
+type routeDecl struct {
+	pattern string
+	name    string
+	handler string
+	adapter string
+}
+
+func main() {
+	var (
+		dir      = flag.String("dir", ".", "directory of the package to scan")
+		out      = flag.String("o", "routes_gen.go", "file to write, relative to dir")
+		funcName = flag.String("func", "RegisterRoutes", "name of the function to generate")
+	)
+	flag.Parse()
+
+	src, err := generate(*dir, *out, *funcName)
+	if err != nil {
+		log.Fatal("routegen: ", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(*dir, *out), src, 0o644); err != nil {
+		log.Fatal("routegen: ", err)
+	}
+}
+
+// generate returns the source of a file registering the routes annotated in
+// the package in dir, ignoring the file out.
+func generate(dir, out, funcName string) ([]byte, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go") && fi.Name() != filepath.Base(out)
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected one package in %s, found %d", dir, len(pkgs))
+	}
+
+	var pkgName string
+	var files []*ast.File
+	for name, pkg := range pkgs {
+		pkgName = name
+		for _, file := range pkg.Files {
+			files = append(files, file)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return fset.Position(files[i].Pos()).Filename < fset.Position(files[j].Pos()).Filename
+	})
+
+	var routes []routeDecl
+	for _, file := range files {
+		decls, err := fileRoutes(fset, file)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, decls...)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by routegen; DO NOT EDIT.\n\npackage %s\n\n", pkgName)
+
+	usesHTTP := false
+	for _, route := range routes {
+		if strings.HasPrefix(route.adapter, "http.") {
+			usesHTTP = true
+		}
+	}
+	buf.WriteString("import (\n")
+	if usesHTTP {
+		buf.WriteString("\t\"net/http\"\n\n")
+	}
+	buf.WriteString("\t\"hawx.me/code/route\"\n)\n\n")
+
+	fmt.Fprintf(&buf, "// %s registers the handlers annotated with the routes they handle.\n", funcName)
+	fmt.Fprintf(&buf, "func %s(r route.Registrar) {\n", funcName)
+	for _, route := range routes {
+		fmt.Fprintf(&buf, "\tr.Handle(%s, %s(%s)", strconv.Quote(route.pattern), route.adapter, route.handler)
+		if route.name != "" {
+			fmt.Fprintf(&buf, ", route.Name(%s)", strconv.Quote(route.name))
+		}
+		buf.WriteString(")\n")
+	}
+	buf.WriteString("}\n")
+
+	return format.Source(buf.Bytes())
+}
+
+// fileRoutes returns the routes annotated on the functions of the file.
+func fileRoutes(fset *token.FileSet, file *ast.File) ([]routeDecl, error) {
+	var routes []routeDecl
+
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Doc == nil {
+			continue
+		}
+
+		for _, comment := range fn.Doc.List {
+			if !strings.HasPrefix(comment.Text, annotation) {
+				continue
+			}
+			pos := fset.Position(comment.Pos())
+
+			if fn.Recv != nil {
+				return nil, fmt.Errorf("%s: annotated method %s, only functions can be annotated", pos, fn.Name.Name)
+			}
+			adapter, err := handlerAdapter(fn.Type)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s %v", pos, fn.Name.Name, err)
+			}
+			pattern, name, err := parseAnnotation(strings.TrimPrefix(comment.Text, annotation))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", pos, err)
+			}
+
+			routes = append(routes, routeDecl{
+				pattern: pattern,
+				name:    name,
+				handler: fn.Name.Name,
+				adapter: adapter,
+			})
+		}
+	}
+
+	return routes, nil
+}
+
+// parseAnnotation splits an annotation into its pattern and name, as
+// "GET /users/:id name=user.show".
+func parseAnnotation(s string) (pattern, name string, err error) {
+	fields := strings.Fields(s)
+
+	for len(fields) > 0 && strings.HasPrefix(fields[len(fields)-1], "name=") {
+		name = strings.TrimPrefix(fields[len(fields)-1], "name=")
+		fields = fields[:len(fields)-1]
+	}
+
+	switch {
+	case len(fields) == 1 && strings.Contains(fields[0], "/"):
+		return fields[0], name, nil
+	case len(fields) == 2 && !strings.Contains(fields[0], "/") && strings.Contains(fields[1], "/"):
+		return fields[0] + " " + fields[1], name, nil
+	}
+
+	return "", "", errors.New("invalid route annotation: " + annotation + s)
+}
+
+// handlerAdapter returns the function converting a handler with the type to a
+// route.Handler or http.Handler, or an error if it is not a handler.
+func handlerAdapter(ft *ast.FuncType) (string, error) {
+	var params int
+	var first ast.Expr
+	for _, field := range ft.Params.List {
+		if first == nil {
+			first = field.Type
+		}
+		if len(field.Names) == 0 {
+			params++
+		} else {
+			params += len(field.Names)
+		}
+	}
+
+	var results int
+	if ft.Results != nil {
+		results = len(ft.Results.List)
+		if results == 1 {
+			if ident, ok := ft.Results.List[0].Type.(*ast.Ident); !ok || ident.Name != "error" {
+				results = -1
+			}
+		}
+	}
+
+	switch {
+	case params == 2 && results == 0:
+		return "http.HandlerFunc", nil
+	case params == 2 && results == 1:
+		return "route.HandlerFunc", nil
+	case params == 3 && results == 1 && isContext(first):
+		return "route.ContextHandlerFunc", nil
+	case params == 3 && results == 0:
+		return "route.ParamsFunc", nil
+	case params == 3 && results == 1:
+		return "route.ParamsHandlerFunc", nil
+	}
+
+	return "", errors.New("does not have the signature of a handler")
+}
+
+// isContext returns true if the type is context.Context.
+func isContext(t ast.Expr) bool {
+	sel, ok := t.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	return ok && pkg.Name == "context" && sel.Sel.Name == "Context"
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestGenerate(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"users.go": `package users
+
+import (
+	"net/http"
+
+	"hawx.me/code/route"
+)
+
+//route:GET /users
+func listUsers(w http.ResponseWriter, r *http.Request) {}
+
+// showUser shows a user.
+//
+//route:GET /users/:id name=user.show
+//route:/people/:id
+func showUser(w http.ResponseWriter, r *http.Request) error { return nil }
+
+//route:DELETE /users/:id
+func deleteUser(w http.ResponseWriter, r *http.Request, ps route.Params) {}
+
+//route:POST /users
+func createUser(ctx context.Context, w http.ResponseWriter, r *http.Request) error { return nil }
+
+func helper() {}
+`,
+		"users_test.go": `package users
+
+//route:GET /test
+func testHandler(w http.ResponseWriter, r *http.Request) {}
+`,
+		"routes_gen.go": `package users
+
+//route:GET /stale
+func stale(w http.ResponseWriter, r *http.Request) {}
+`,
+	})
+
+	src, err := generate(dir, "routes_gen.go", "RegisterRoutes")
+	assert.Nil(t, err)
+	assert.Equal(t, `// Code generated by routegen; DO NOT EDIT.
+
+package users
+
+import (
+	"net/http"
+
+	"hawx.me/code/route"
+)
+
+// RegisterRoutes registers the handlers annotated with the routes they handle.
+func RegisterRoutes(r route.Registrar) {
+	r.Handle("GET /users", http.HandlerFunc(listUsers))
+	r.Handle("GET /users/:id", route.HandlerFunc(showUser), route.Name("user.show"))
+	r.Handle("/people/:id", route.HandlerFunc(showUser))
+	r.Handle("DELETE /users/:id", route.ParamsFunc(deleteUser))
+	r.Handle("POST /users", route.ContextHandlerFunc(createUser))
+}
+`, string(src))
+}
+
+func TestGenerateErrors(t *testing.T) {
+	cases := map[string]string{
+		"invalid annotation": `package users
+
+//route:GET
+func h(w http.ResponseWriter, r *http.Request) {}
+`,
+		"not a handler": `package users
+
+//route:GET /a
+func h(s string) {}
+`,
+		"method": `package users
+
+type T struct{}
+
+//route:GET /a
+func (T) h(w http.ResponseWriter, r *http.Request) {}
+`,
+	}
+
+	for name, src := range cases {
+		dir := writeFiles(t, map[string]string{"a.go": src})
+
+		_, err := generate(dir, "routes_gen.go", "RegisterRoutes")
+		assert.NotNil(t, err, name)
+	}
+}
@@ -0,0 +1,212 @@
+package route
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// A RouteConfig describes a route to register with HandleRoutes. Exactly one of
+// Handler, Redirect or Proxy must be set.
+type RouteConfig struct {
+	// Pattern is the path of the route, as given to Handle.
+	Pattern string `json:"pattern"`
+
+	// Name is the name of the route, if any.
+	Name string `json:"name,omitempty"`
+
+	// Handler is the name of the handler in the Registry to use.
+	Handler string `json:"handler,omitempty"`
+
+	// Middleware are the names of middleware in the Registry to wrap the route
+	// with, the first being the outermost.
+	Middleware []string `json:"middleware,omitempty"`
+
+	// Redirect is the target to redirect to, as given to Redirect.
+	Redirect string `json:"redirect,omitempty"`
+
+	// Status is the status code of redirects, by default 302 Found.
+	Status int `json:"status,omitempty"`
+
+	// Proxy is the URL of the backend to proxy to, as given to Proxy.
+	Proxy string `json:"proxy,omitempty"`
+}
+
+// A Registry names the handlers and middleware that routes registered with
+// HandleRoutes can use.
+type Registry struct {
+	Handlers   map[string]http.Handler
+	Middleware map[string]func(http.Handler) http.Handler
+}
+
+// ReadRoutes reads routes from a JSON or YAML document, as:
+//
+//   {"routes": [
+//     {"pattern": "GET /users/:id", "handler": "users", "middleware": ["auth"]},
+//     {"pattern": "/old/*path", "redirect": "/new/*path", "status": 301},
+//     {"pattern": "/api/*path", "proxy": "http://api.internal:8080"}
+//   ]}
+//
+// or:
+//
+//   routes:
+//     - pattern: GET /users/:id
+//       handler: users
+//       middleware: [auth]
+//     - pattern: /old/*path
+//       redirect: /new/*path
+//       status: 301
+//
+// A document starting with "{" is read as JSON, any other as YAML. Only the
+// block mappings and sequences, flow sequences, scalars and comments that
+// routes need are read from YAML; anchors, tags, multi-line strings and flow
+// mappings are an error. Unknown fields are an error, so that mistakes are not
+// ignored.
+func ReadRoutes(config io.Reader) ([]RouteConfig, error) {
+	var doc struct {
+		Routes []RouteConfig `json:"routes"`
+	}
+
+	data, err := io.ReadAll(config)
+	if err != nil {
+		return nil, fmt.Errorf("route: reading routes: %w", err)
+	}
+
+	if trimmed := bytes.TrimLeft(data, " \t\r\n"); len(trimmed) > 0 && trimmed[0] != '{' {
+		v, err := parseYAML(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("route: reading routes: %w", err)
+		}
+		if data, err = json.Marshal(v); err != nil {
+			return nil, fmt.Errorf("route: reading routes: %w", err)
+		}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("route: reading routes: %w", err)
+	}
+
+	return doc.Routes, nil
+}
+
+// HandleRoutes registers the routes, taking handlers and middleware from the
+// registry by name, so that routes that change more often than code can be
+// kept in configuration:
+//
+//   routes, err := route.ReadRoutes(f)
+//   err = router.HandleRoutes(routes, route.Registry{
+//     Handlers:   map[string]http.Handler{"users": usersHandler},
+//     Middleware: map[string]func(http.Handler) http.Handler{"auth": requireAuth},
+//   })
+//
+// An error is returned if a route names a handler or middleware not in the
+// registry, does not have exactly one of a handler, redirect or proxy, or
+// cannot be registered. In each case no routes are registered, unless they
+// conflict with routes already registered, as for Merge. To replace the routes
+// when the configuration changes use Swap, panicking with the error:
+//
+//   err := router.Swap(func(r *route.Router) {
+//     if err := r.HandleRoutes(routes, registry); err != nil {
+//       panic(err)
+//     }
+//   })
+func (r *Router) HandleRoutes(routes []RouteConfig, registry Registry) error {
+	if r.frozen {
+		return ErrFrozen
+	}
+
+	var problems []string
+	for _, rc := range routes {
+		if err := rc.check(registry); err != nil {
+			problems = append(problems, rc.Pattern+": "+err.Error())
+		}
+	}
+	if len(problems) > 0 {
+		return errors.New("route: invalid routes: " + strings.Join(problems, "; "))
+	}
+
+	next := r.fork()
+	for _, rc := range routes {
+		if err := next.handleConfig(r, rc, registry); err != nil {
+			return fmt.Errorf("route: registering %s: %w", rc.Pattern, err)
+		}
+	}
+
+	return r.Merge(next)
+}
+
+// check returns an error if the route cannot be registered with the registry.
+func (rc RouteConfig) check(registry Registry) error {
+	targets := 0
+	for _, target := range []string{rc.Handler, rc.Redirect, rc.Proxy} {
+		if target != "" {
+			targets++
+		}
+	}
+	if targets != 1 {
+		return errors.New("requires exactly one of handler, redirect or proxy")
+	}
+
+	if _, ok := registry.Handlers[rc.Handler]; rc.Handler != "" && !ok {
+		return errors.New("unknown handler " + rc.Handler)
+	}
+	for _, name := range rc.Middleware {
+		if _, ok := registry.Middleware[name]; !ok {
+			return errors.New("unknown middleware " + name)
+		}
+	}
+
+	return nil
+}
+
+// handleConfig registers the route described by rc to r, as HandleRoutes does
+// for root. Errors from redirects are handled by root.
+func (r *Router) handleConfig(root *Router, rc RouteConfig, registry Registry) (err error) {
+	defer recoverRegistration(&err)
+
+	var handler http.Handler
+	switch {
+	case rc.Handler != "":
+		handler = registry.Handlers[rc.Handler]
+
+	case rc.Redirect != "":
+		code := rc.Status
+		if code == 0 {
+			code = http.StatusFound
+		}
+		_, _, path := parsePattern(rc.Pattern)
+		redirect := newRedirectHandler(path, rc.Redirect, code)
+
+		handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if err := redirect.ServeErrorHTTP(w, req); err != nil {
+				root.ErrorHandler(w, req, root.mapError(err))
+			}
+		})
+
+	case rc.Proxy != "":
+		target, err := url.Parse(rc.Proxy)
+		if err != nil {
+			return err
+		}
+		handler = NewProxy(target, proxyParam(rc.Pattern))
+	}
+
+	for i := len(rc.Middleware) - 1; i >= 0; i-- {
+		handler = registry.Middleware[rc.Middleware[i]](handler)
+	}
+
+	var opts []Option
+	if rc.Name != "" {
+		opts = append(opts, Name(rc.Name))
+	}
+
+	r.Handle(rc.Pattern, handler, opts...)
+	return nil
+}
@@ -0,0 +1,137 @@
+package route
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouterHandleRoutes(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("backend " + r.URL.Path))
+	}))
+	defer backend.Close()
+
+	routes, err := ReadRoutes(strings.NewReader(`{"routes": [
+	  {"pattern": "GET /users/:id", "name": "user", "handler": "users", "middleware": ["outer", "inner"]},
+	  {"pattern": "/old/*path", "redirect": "/new/*path", "status": 301, "middleware": ["outer"]},
+	  {"pattern": "/moved", "redirect": "/here"},
+	  {"pattern": "/api/*path", "proxy": "` + backend.URL + `/v1"}
+	]}`))
+	assert.Nil(t, err)
+
+	mark := func(name string) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("X-Middleware", name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	router := New()
+	err = router.HandleRoutes(routes, Registry{
+		Handlers: map[string]http.Handler{
+			"users": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("user " + Vars(r)["id"]))
+			}),
+		},
+		Middleware: map[string]func(http.Handler) http.Handler{
+			"outer": mark("outer"),
+			"inner": mark("inner"),
+		},
+	})
+	assert.Nil(t, err)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/users/5", nil))
+	assert.Equal(t, "user 5", w.Body.String())
+	assert.Equal(t, []string{"outer", "inner"}, w.Header()["X-Middleware"])
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/old/a/b", nil))
+	assert.Equal(t, 301, w.Code)
+	assert.Equal(t, "/new/a/b", w.Header().Get("Location"))
+	assert.Equal(t, []string{"outer"}, w.Header()["X-Middleware"])
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/moved", nil))
+	assert.Equal(t, 302, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/things", nil))
+	assert.Equal(t, "backend /v1/things", w.Body.String())
+
+	u, err := router.URL("user", "id", "7")
+	assert.Nil(t, err)
+	assert.Equal(t, "/users/7", u.String())
+}
+
+func TestRouterHandleRoutesInvalid(t *testing.T) {
+	cases := []RouteConfig{
+		{Pattern: "/a"},
+		{Pattern: "/a", Handler: "missing"},
+		{Pattern: "/a", Handler: "ok", Middleware: []string{"missing"}},
+		{Pattern: "/a", Handler: "ok", Redirect: "/b"},
+		{Pattern: "/a", Redirect: "/b", Status: 200},
+		{Pattern: "/a", Redirect: "/:b"},
+		{Pattern: "/a", Proxy: "http://example.com"},
+		{Pattern: "a", Handler: "ok"},
+	}
+
+	registry := Registry{Handlers: map[string]http.Handler{"ok": &recordingHandler{}}}
+
+	for _, tc := range cases {
+		router := New()
+		err := router.HandleRoutes([]RouteConfig{{Pattern: "/fine", Handler: "ok"}, tc}, registry)
+
+		assert.NotNil(t, err)
+		assert.Empty(t, router.Routes())
+	}
+}
+
+func TestReadRoutesUnknownField(t *testing.T) {
+	_, err := ReadRoutes(strings.NewReader(`{"routes": [{"pattern": "/a", "hander": "x"}]}`))
+
+	assert.NotNil(t, err)
+}
+
+func TestReadRoutesYAML(t *testing.T) {
+	routes, err := ReadRoutes(strings.NewReader(`# routes for the edge
+routes:
+  - pattern: GET /users/:id
+    name: user
+    handler: users
+    middleware: [outer, "inner"]
+  - pattern: /old/*path   # moved in 2024
+    redirect: /new/*path
+    status: 301
+    middleware:
+      - outer
+  - pattern: '/api/*path'
+    proxy: http://api.internal:8080/v1
+`))
+	assert.Nil(t, err)
+
+	assert.Equal(t, []RouteConfig{
+		{Pattern: "GET /users/:id", Name: "user", Handler: "users", Middleware: []string{"outer", "inner"}},
+		{Pattern: "/old/*path", Redirect: "/new/*path", Status: 301, Middleware: []string{"outer"}},
+		{Pattern: "/api/*path", Proxy: "http://api.internal:8080/v1"},
+	}, routes)
+}
+
+func TestReadRoutesYAMLErrors(t *testing.T) {
+	for _, doc := range []string{
+		"routes:\n  - pattern: /a\n    hander: x\n",
+		"routes:\n  - pattern: /a\n      handler: x\n",
+		"routes:\n  - pattern: /a\n    handler: &x users\n",
+		"routes:\n  - pattern: /a\n    pattern: /b\n",
+		"routes:\n  - pattern: /a\n    status: many\n",
+	} {
+		_, err := ReadRoutes(strings.NewReader(doc))
+		assert.NotNil(t, err, doc)
+	}
+}
@@ -0,0 +1,95 @@
+package route
+
+import (
+	"encoding/json"
+	"html/template"
+	"io"
+	"net/http"
+	"strings"
+	"text/tabwriter"
+)
+
+// DebugHandler returns a handler that lists the routes registered with router,
+// showing for each its pattern, parameters, priority and handler. Clients that
+// ask for JSON, or requests with the query "format=json", are sent the result
+// of Routes, otherwise an HTML table is written. It is intended for use in
+// development:
+//
+//   router.Handle("/_routes", route.DebugHandler(router))
+func DebugHandler(router *Router) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		routes := router.Routes()
+
+		if r.URL.Query().Get("format") == "json" || prefersJSON(r) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(routes)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		debugTemplate.Execute(w, routes)
+	})
+}
+
+// WriteTable writes the registered routes to w as an aligned table, one route
+// per line, suitable for logging at startup:
+//
+//   METHODS  PATTERN      NAME  HANDLER             SOURCE
+//   GET      /user/:name  user  main.showUser       main.go:21
+//   *        /files/*path       http.fileHandler    main.go:22
+func (r *Router) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	io.WriteString(tw, "METHODS\tPATTERN\tNAME\tHANDLER\tSOURCE\n")
+
+	for _, route := range r.Routes() {
+		methods := "*"
+		if len(route.Methods) > 0 {
+			methods = strings.Join(route.Methods, ",")
+		}
+
+		io.WriteString(tw, methods+"\t"+route.Host+route.Pattern+"\t"+route.Name+"\t"+route.Handler+"\t"+route.Source+"\n")
+	}
+
+	return tw.Flush()
+}
+
+// String returns the table of routes written by WriteTable.
+func (r *Router) String() string {
+	var b strings.Builder
+	r.WriteTable(&b)
+
+	return b.String()
+}
+
+// prefersJSON returns true if the Accept header of the request gives JSON a
+// higher quality than HTML.
+func prefersJSON(r *http.Request) bool {
+	return negotiate(r.Header.Get("Accept"), []string{"text/html", "application/json"}) == "application/json"
+}
+
+var debugTemplate = template.Must(template.New("routes").Funcs(template.FuncMap{
+	"join": strings.Join,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Routes</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+th, td { padding: .25em .75em; text-align: left; border-bottom: 1px solid #ddd; }
+td { font-family: monospace; }
+</style>
+</head>
+<body>
+<table>
+<thead>
+<tr><th>Host</th><th>Pattern</th><th>Methods</th><th>Params</th><th>Priority</th><th>Name</th><th>Handler</th><th>Source</th></tr>
+</thead>
+<tbody>
+{{range .}}<tr><td>{{.Host}}</td><td>{{.Pattern}}</td><td>{{if .Methods}}{{join .Methods ", "}}{{else}}*{{end}}</td><td>{{join .Params ", "}}</td><td>{{.Priority}}</td><td>{{.Name}}</td><td>{{.Handler}}</td><td>{{.Source}}</td></tr>
+{{end}}</tbody>
+</table>
+</body>
+</html>
+`))
@@ -0,0 +1,60 @@
+package route
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebugHandlerJSON(t *testing.T) {
+	router := New()
+	router.Handle("GET /user/:name", &recordingHandler{}, Name("user"))
+	router.Handle("/user/:name", &recordingHandler{})
+
+	for _, target := range []string{"/_routes?format=json", "/_routes"} {
+		r, _ := http.NewRequest("GET", target, nil)
+		if target == "/_routes" {
+			r.Header.Set("Accept", "application/json")
+		}
+		w := httptest.NewRecorder()
+		DebugHandler(router).ServeHTTP(w, r)
+
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+		var routes []RouteInfo
+		assert.Nil(t, json.NewDecoder(w.Body).Decode(&routes))
+		assert.Equal(t, router.Routes(), routes)
+	}
+}
+
+func TestDebugHandlerHTML(t *testing.T) {
+	router := New()
+	router.Handle("GET /user/:name", &recordingHandler{}, Name("user"))
+	router.Handle("/<script>", &recordingHandler{})
+
+	r, _ := http.NewRequest("GET", "/_routes", nil)
+	r.Header.Set("Accept", "text/html,application/json;q=0.9")
+	w := httptest.NewRecorder()
+	DebugHandler(router).ServeHTTP(w, r)
+
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "<td>/user/:name</td><td>GET</td><td>name</td><td>0</td><td>user</td><td>*route.recordingHandler</td>")
+	assert.Contains(t, w.Body.String(), "&lt;script&gt;")
+}
+
+func TestRouterWriteTable(t *testing.T) {
+	router := New()
+	router.Handle("GET /user/:name", &recordingHandler{}, Name("user"))
+	router.Handle("example.com/files/*path", http.NotFoundHandler())
+
+	table := regexp.MustCompile(`debug_test\.go:\d+`).ReplaceAllString(router.String(), "debug_test.go:N")
+
+	assert.Equal(t, `METHODS  PATTERN                  NAME  HANDLER                  SOURCE
+GET      /user/:name              user  *route.recordingHandler  debug_test.go:N
+*        example.com/files/*path        net/http.NotFound        debug_test.go:N
+`, table)
+}
@@ -0,0 +1,68 @@
+package route
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+// drainRetryAfter is the number of seconds clients are asked to wait before
+// retrying requests refused while draining.
+const drainRetryAfter = 5
+
+// refuseDraining responds to a request received after Drain has been called
+// with 503 Service Unavailable and a Retry-After header. It is written directly,
+// rather than through the ErrorHandler, so that it is sent whichever is set.
+func refuseDraining(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", strconv.Itoa(drainRetryAfter))
+	http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+}
+
+// Drain stops the router accepting requests, so that those received are
+// responded to with 503 Service Unavailable and a Retry-After header, and waits
+// for the requests it is serving to finish. It returns the error of ctx if it
+// is done first. Drain is intended to be called before shutting down the
+// server, so that a load balancer can move clients elsewhere while outstanding
+// requests complete:
+//
+//   ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+//   defer cancel()
+//
+//   router.Drain(ctx)
+//   server.Shutdown(ctx)
+//
+// Unlike http.Server.Shutdown, hijacked connections, such as those of
+// WebSocket handlers, are waited for while their handler is running. Once
+// called the router does not accept requests again.
+func (r *Router) Drain(ctx context.Context) error {
+	idle := make(chan struct{}, 1)
+	r.idle.CompareAndSwap(nil, &idle)
+	ch := *r.idle.Load()
+
+	for r.active.Load() > 0 {
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// InFlight returns the number of requests the router is serving.
+func (r *Router) InFlight() int {
+	return int(r.active.Load())
+}
+
+// release marks a request as served, signalling Drain if it was the last.
+func (r *Router) release() {
+	if r.active.Add(-1) == 0 {
+		if idle := r.idle.Load(); idle != nil {
+			select {
+			case *idle <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package route
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouterDrain(t *testing.T) {
+	started := make(chan struct{})
+	finish := make(chan struct{})
+
+	router := New()
+	router.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-finish
+		w.Write([]byte("done"))
+	})
+	router.HandleFunc("/fast", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("fast"))
+	})
+
+	slow := httptest.NewRecorder()
+	served := make(chan struct{})
+	go func() {
+		router.ServeHTTP(slow, httptest.NewRequest("GET", "/slow", nil))
+		close(served)
+	}()
+	<-started
+
+	assert.Equal(t, 1, router.InFlight())
+
+	drained := make(chan error)
+	go func() {
+		drained <- router.Drain(context.Background())
+	}()
+
+	for router.idle.Load() == nil {
+		time.Sleep(time.Millisecond)
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/fast", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "5", rec.Header().Get("Retry-After"))
+
+	select {
+	case <-drained:
+		t.Fatal("drained before the request finished")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	close(finish)
+	assert.Nil(t, <-drained)
+	<-served
+
+	assert.Equal(t, "done", slow.Body.String())
+	assert.Equal(t, 0, router.InFlight())
+}
+
+func TestRouterDrainTimeout(t *testing.T) {
+	finish := make(chan struct{})
+	defer close(finish)
+
+	router := New()
+	router.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		<-finish
+	})
+
+	go router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/slow", nil))
+	for router.InFlight() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assert.Equal(t, context.DeadlineExceeded, router.Drain(ctx))
+}
+
+func TestRouterDrainIdle(t *testing.T) {
+	router := New()
+	assert.Nil(t, router.Drain(context.Background()))
+}
+
+func TestRouterDrainWithIgnoreErrors(t *testing.T) {
+	router := New()
+	router.ErrorHandler = IgnoreErrors
+	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {})
+
+	assert.Nil(t, router.Drain(context.Background()))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "5", rec.Header().Get("Retry-After"))
+}
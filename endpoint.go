@@ -0,0 +1,170 @@
+package route
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// endpoint is the Handler stored in the lookup tree for each registered path. It
+// holds the routes registered for the path, and chooses between them for each
+// request.
+type endpoint struct {
+	pattern string
+	host    string
+	routes  []*entry
+
+	// vars are added to the parameters of every request matching the endpoint.
+	vars map[string]string
+}
+
+// add adds the route to the endpoint, replacing any route previously registered
+// for the same methods without conditions.
+func (ep *endpoint) add(e *entry) {
+	if !e.conditional() {
+		if i := ep.unconditional(e.methods); i >= 0 {
+			ep.routes[i] = e
+			return
+		}
+	}
+
+	// routes restricted to methods are tried before those accepting any, and
+	// within those routes with conditions are tried first
+	i := 0
+	for i < len(ep.routes) && ep.routes[i].priority() <= e.priority() {
+		i++
+	}
+	ep.routes = append(ep.routes[:i], append([]*entry{e}, ep.routes[i:]...)...)
+}
+
+// match returns the route that should handle the request. HEAD requests are
+// handled by a GET route if there is not one for HEAD.
+//
+// If there is no route for the request, the status to respond with is returned
+// instead: 405 if no route accepts the method, 415 if the only routes that
+// would match do not accept the Content-Type of the request, otherwise 404.
+func (ep *endpoint) match(r *http.Request) (*entry, int) {
+	status := http.StatusMethodNotAllowed
+
+	try := func(e *entry) bool {
+		if status == http.StatusMethodNotAllowed {
+			status = http.StatusNotFound
+		}
+
+		if e.satisfied(r) {
+			if e.acceptsContentType(r) {
+				return true
+			}
+			status = http.StatusUnsupportedMediaType
+		}
+		return false
+	}
+
+	var get *entry
+	for _, e := range ep.routes {
+		if e.accepts(r.Method) && try(e) {
+			return e, 0
+		}
+		if get == nil && r.Method == "HEAD" && e.accepts("GET") && try(e) {
+			get = e
+		}
+	}
+
+	if get != nil {
+		return get, 0
+	}
+
+	return nil, status
+}
+
+// remove removes the routes for which fn returns true, returning them.
+func (ep *endpoint) remove(fn func(*entry) bool) []*entry {
+	var removed []*entry
+	routes := ep.routes[:0]
+	for _, e := range ep.routes {
+		if fn(e) {
+			removed = append(removed, e)
+		} else {
+			routes = append(routes, e)
+		}
+	}
+	ep.routes = routes
+
+	return removed
+}
+
+// unconditional returns the index of the route without conditions for exactly
+// the methods, or -1 if there is not one.
+func (ep *endpoint) unconditional(methods []string) int {
+	for i, e := range ep.routes {
+		if !e.conditional() && sameMethods(e.methods, methods) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// source returns where the route with the name was registered, or if name is
+// empty where the first route was.
+func (ep *endpoint) source(name string) string {
+	for _, e := range ep.routes {
+		if e.name == name || name == "" {
+			return e.source
+		}
+	}
+
+	return ""
+}
+
+// allowed returns the methods that the endpoint has routes for.
+func (ep *endpoint) allowed() []string {
+	set := map[string]bool{}
+	for _, e := range ep.routes {
+		for _, method := range e.methods {
+			set[method] = true
+		}
+	}
+	if set["GET"] {
+		set["HEAD"] = true
+	}
+
+	methods := make([]string, 0, len(set))
+	for method := range set {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	return methods
+}
+
+func (ep *endpoint) ServeErrorHTTP(w http.ResponseWriter, r *http.Request) error {
+	e, status := ep.match(r)
+	if e != nil {
+		return e.handler.ServeErrorHTTP(w, r)
+	}
+
+	if status == http.StatusMethodNotAllowed {
+		w.Header().Set("Allow", strings.Join(ep.allowed(), ", "))
+	}
+	http.Error(w, http.StatusText(status), status)
+	return nil
+}
+
+func sameMethods(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	set := map[string]bool{}
+	for _, method := range a {
+		set[method] = true
+	}
+	for _, method := range b {
+		if !set[method] {
+			return false
+		}
+	}
+
+	return true
+}
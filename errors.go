@@ -0,0 +1,196 @@
+package route
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// StatusCoder is implemented by errors that know the HTTP status code that
+// should be responded with. If the error also has a method
+//
+//   Header() http.Header
+//
+// then the headers it returns are added to the response.
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// errorResponse returns the status code and message to respond to err with,
+// 500 if it has none or one outside 100-999, and adds any headers the error has
+// to w. The message is empty unless err is, or wraps, an HTTPError.
+func errorResponse(w http.ResponseWriter, err error) (code int, msg string) {
+	code = http.StatusInternalServerError
+
+	var coder StatusCoder
+	if errors.As(err, &coder) {
+		// codes that cannot be written are responded to as any other error
+		if c := coder.StatusCode(); c >= 100 && c <= 999 {
+			code = c
+		}
+	}
+
+	var headerer interface{ Header() http.Header }
+	if errors.As(err, &headerer) {
+		for k, vs := range headerer.Header() {
+			for _, v := range vs {
+				w.Header().Add(k, v)
+			}
+		}
+	}
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		msg = httpErr.Message
+	}
+
+	return code, msg
+}
+
+// HTTPError is an error that should be responded to with a particular status
+// code. Handlers can return it to control the response written by the
+// ErrorHandler.
+type HTTPError struct {
+	// Code is the HTTP status code to respond with.
+	Code int
+
+	// Message describes the error and is safe to show to clients. If empty the
+	// standard text for the status code is used.
+	Message string
+
+	// Err is the underlying error, if any. It is not shown to clients.
+	Err error
+}
+
+// Error returns an HTTPError with the code and message.
+func Error(code int, message string) *HTTPError {
+	return &HTTPError{Code: code, Message: message}
+}
+
+func (e *HTTPError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.Code)
+	}
+	if e.Err != nil {
+		return msg + ": " + e.Err.Error()
+	}
+
+	return msg
+}
+
+// StatusCode returns Code.
+func (e *HTTPError) StatusCode() int {
+	return e.Code
+}
+
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
+
+// An ErrorMapper converts an error returned by a handler to an HTTPError, or
+// returns nil if it does not know how to.
+type ErrorMapper func(err error) *HTTPError
+
+// MapError registers that errors matching target, as reported by errors.Is,
+// should be responded to with the code and message.
+//
+//   router.MapError(sql.ErrNoRows, http.StatusNotFound, "")
+func (r *Router) MapError(target error, code int, message string) {
+	r.MapErrorFunc(func(err error) *HTTPError {
+		if errors.Is(err, target) {
+			return &HTTPError{Code: code, Message: message}
+		}
+		return nil
+	})
+}
+
+// MapErrorFunc registers an ErrorMapper. When a handler returns an error that is
+// not already an HTTPError the mappers are tried in the order they were
+// registered, and the ErrorHandler is passed the first HTTPError returned, with
+// Err set to the original error.
+//
+//   router.MapErrorFunc(func(err error) *route.HTTPError {
+//     var verr *ValidationError
+//     if errors.As(err, &verr) {
+//       return route.Error(http.StatusUnprocessableEntity, verr.Error())
+//     }
+//     return nil
+//   })
+func (r *Router) MapErrorFunc(mapper ErrorMapper) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.mappers = append(r.mappers[:len(r.mappers):len(r.mappers)], mapper)
+	r.changed()
+}
+
+// mapError converts err using the registered ErrorMappers.
+func (r *Router) mapError(err error) error {
+	return r.load().mapError(err)
+}
+
+// mapError converts err using the ErrorMappers of the snapshot.
+func (s *snapshot) mapError(err error) error {
+	var httpErr *HTTPError
+	if len(s.mappers) == 0 || errors.As(err, &httpErr) {
+		return err
+	}
+
+	for _, mapper := range s.mappers {
+		if mapped := mapper(err); mapped != nil {
+			mapped.Err = err
+			return mapped
+		}
+	}
+
+	return err
+}
+
+// Problem is the body of an application/problem+json response, as described by
+// RFC 7807.
+type Problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// ProblemHandler is an ErrorHandler that responds with a Problem describing the
+// error. If the error is, or wraps, an HTTPError its code and message are used
+// for the status and detail, or if it implements StatusCoder its code is used
+// for the status, otherwise the response is 500 Internal Server Error with no
+// detail. Clients that do not accept JSON are sent the title and detail as
+// plain text.
+//
+//   router.ErrorHandler = route.ProblemHandler
+func ProblemHandler(w http.ResponseWriter, r *http.Request, err error) {
+	code, msg := errorResponse(w, err)
+
+	problem := Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(code),
+		Status: code,
+		Detail: msg,
+	}
+
+	if !acceptsJSON(r) {
+		msg := problem.Title
+		if problem.Detail != "" {
+			msg += ": " + problem.Detail
+		}
+		http.Error(w, msg, problem.Status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
+}
+
+// acceptsJSON returns true if the Accept header of the request allows a JSON
+// response, or is missing.
+func acceptsJSON(r *http.Request) bool {
+	return negotiate(r.Header.Get("Accept"), []string{"application/problem+json", "application/json"}) != ""
+}
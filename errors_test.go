@@ -0,0 +1,225 @@
+package route
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPError(t *testing.T) {
+	cause := errors.New("no rows")
+	err := &HTTPError{Code: 404, Message: "user not found", Err: cause}
+
+	assert.Equal(t, "user not found: no rows", err.Error())
+	assert.True(t, errors.Is(err, cause))
+
+	assert.Equal(t, "Bad Request", Error(400, "").Error())
+}
+
+func TestProblemHandler(t *testing.T) {
+	router := New()
+	router.ErrorHandler = ProblemHandler
+	router.HandleFunc("/users/:name", func(w http.ResponseWriter, r *http.Request) error {
+		return fmt.Errorf("finding user: %w", Error(404, "no user named "+Vars(r)["name"]))
+	})
+	router.HandleFunc("/fail", func(w http.ResponseWriter, r *http.Request) error {
+		return errors.New("secret database details")
+	})
+
+	r, _ := http.NewRequest("GET", "/users/gopher", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, 404, w.Code)
+	assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"type":"about:blank","title":"Not Found","status":404,"detail":"no user named gopher"}`, w.Body.String())
+
+	r, _ = http.NewRequest("GET", "/fail", nil)
+	r.Header.Set("Accept", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, 500, w.Code)
+	assert.JSONEq(t, `{"type":"about:blank","title":"Internal Server Error","status":500}`, w.Body.String())
+}
+
+func TestProblemHandlerWithoutJSON(t *testing.T) {
+	r, _ := http.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept", "text/html, application/json;q=0")
+	w := httptest.NewRecorder()
+
+	ProblemHandler(w, r, Error(422, "name is required"))
+
+	assert.Equal(t, 422, w.Code)
+	assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "Unprocessable Entity: name is required\n", w.Body.String())
+}
+
+type validationError struct {
+	field string
+}
+
+func (e *validationError) Error() string {
+	return e.field + " is invalid"
+}
+
+func TestRouterMapError(t *testing.T) {
+	errNoRows := errors.New("no rows")
+
+	router := New()
+	router.MapError(errNoRows, 404, "")
+	router.MapErrorFunc(func(err error) *HTTPError {
+		var verr *validationError
+		if errors.As(err, &verr) {
+			return Error(422, verr.Error())
+		}
+		return nil
+	})
+
+	router.HandleFunc("/users/:name", func(w http.ResponseWriter, r *http.Request) error {
+		return fmt.Errorf("finding user: %w", errNoRows)
+	})
+	router.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) error {
+		return &validationError{"name"}
+	})
+	router.HandleFunc("/explicit", func(w http.ResponseWriter, r *http.Request) error {
+		return Error(409, "conflict")
+	})
+
+	cases := []struct {
+		path string
+		code int
+		body string
+	}{
+		{"/users/gopher", 404, "Not Found\n"},
+		{"/users", 422, "name is invalid\n"},
+		{"/explicit", 409, "conflict\n"},
+	}
+
+	for _, tc := range cases {
+		r, _ := http.NewRequest("GET", tc.path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+
+		assert.Equal(t, tc.code, w.Code)
+		assert.Equal(t, tc.body, w.Body.String())
+	}
+}
+
+func TestRouterMapErrorKeepsOriginal(t *testing.T) {
+	errNoRows := errors.New("no rows")
+	var handled error
+
+	router := New()
+	router.MapError(errNoRows, 404, "missing")
+	router.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		handled = err
+	}
+	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) error {
+		return errNoRows
+	})
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+
+	assert.True(t, errors.Is(handled, errNoRows))
+	assert.Equal(t, "missing: no rows", handled.Error())
+}
+
+func TestRouterMapErrorWhileServing(t *testing.T) {
+	errNoRows := errors.New("no rows")
+
+	router := New()
+	router.ErrorHandler = IgnoreErrors
+	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) error {
+		return errNoRows
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+			r, _ := http.NewRequest("GET", "/", nil)
+			router.ServeHTTP(httptest.NewRecorder(), r)
+		}()
+
+		go func() {
+			defer wg.Done()
+			router.MapError(errNoRows, 404, "")
+		}()
+	}
+	wg.Wait()
+
+	var handled error
+	router.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		handled = err
+	}
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+
+	var httpErr *HTTPError
+	if assert.True(t, errors.As(handled, &httpErr)) {
+		assert.Equal(t, 404, httpErr.Code)
+	}
+}
+
+type rateLimitError struct{}
+
+func (rateLimitError) Error() string   { return "rate limited" }
+func (rateLimitError) StatusCode() int { return 429 }
+func (rateLimitError) Header() http.Header {
+	return http.Header{"Retry-After": {"30"}}
+}
+
+func TestRouterStatusCoderError(t *testing.T) {
+	router := New()
+	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) error {
+		return fmt.Errorf("calling api: %w", rateLimitError{})
+	})
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, 429, w.Code)
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+	assert.Equal(t, "Too Many Requests\n", w.Body.String())
+}
+
+func TestProblemHandlerStatusCoderError(t *testing.T) {
+	r, _ := http.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+
+	ProblemHandler(w, r, rateLimitError{})
+
+	assert.Equal(t, 429, w.Code)
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+	assert.JSONEq(t, `{"type":"about:blank","title":"Too Many Requests","status":429}`, w.Body.String())
+}
+
+func TestRouterInvalidStatusCode(t *testing.T) {
+	router := New()
+	router.ErrorLog = log.New(io.Discard, "", 0)
+	router.HandleFunc("/:code", func(w http.ResponseWriter, r *http.Request) error {
+		code, _ := strconv.Atoi(Vars(r)["code"])
+		return &HTTPError{Code: code}
+	})
+
+	for path, code := range map[string]int{"/0": 500, "/99": 500, "/1000": 500, "/100": 100, "/999": 999} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+
+		assert.Equal(t, code, w.Code, path)
+	}
+}
@@ -0,0 +1,39 @@
+package route
+
+import (
+	"errors"
+	"net/http"
+)
+
+// A HandlerFactory makes the handler for a request once its route has matched,
+// so that the handler can be given dependencies that depend on the request,
+// such as the database of a tenant:
+//
+//   router.Handle("/:tenant/orders", route.HandlerFactory(func(r *http.Request) (route.Handler, error) {
+//     db, err := tenants.DB(route.Vars(r)["tenant"])
+//     if err != nil {
+//       return nil, err
+//     }
+//     return &ordersHandler{db: db}, nil
+//   }))
+//
+// An error returned by the factory is handled as if the handler had returned
+// it, so is passed to the ErrorHandler. Factories can also be registered with
+// HandleFunc, as a `func(*http.Request) (route.Handler, error)`.
+type HandlerFactory func(r *http.Request) (Handler, error)
+
+// ErrNoHandler is returned for requests when a HandlerFactory returns neither a
+// handler nor an error.
+var ErrNoHandler = errors.New("route: factory returned no handler")
+
+func (f HandlerFactory) ServeErrorHTTP(w http.ResponseWriter, r *http.Request) error {
+	h, err := f(r)
+	if err != nil {
+		return err
+	}
+	if h == nil {
+		return ErrNoHandler
+	}
+
+	return h.ServeErrorHTTP(w, r)
+}
@@ -0,0 +1,50 @@
+package route
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandlerFactory(t *testing.T) {
+	unknown := errors.New("unknown tenant")
+	var handled error
+
+	router := New()
+	router.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		handled = err
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	router.Get("/:tenant/orders", func(r *http.Request) (Handler, error) {
+		tenant := Vars(r)["tenant"]
+		switch tenant {
+		case "acme":
+			return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+				w.Write([]byte("orders for " + tenant))
+				return nil
+			}), nil
+		case "empty":
+			return nil, nil
+		default:
+			return nil, unknown
+		}
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/acme/orders", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "orders for acme", rec.Body.String())
+	assert.Nil(t, handled)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/other/orders", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, unknown, handled)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/empty/orders", nil))
+	assert.Equal(t, ErrNoHandler, handled)
+}
@@ -0,0 +1,88 @@
+package route
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+)
+
+// ErrFrozen is returned, or panicked with, when trying to change the routes of
+// a Router returned by Freeze.
+var ErrFrozen = errors.New("route: router is frozen")
+
+// Freeze returns a copy of the router that can no longer be changed, suited to
+// the common case of registering all routes at startup:
+//
+//   router := route.New()
+//   router.Handle("/", homeHandler)
+//   // ...
+//
+//   http.ListenAndServe(":8080", router.Freeze())
+//
+// Registering, removing or replacing routes of the copy fails. The methods
+// that return an error return ErrFrozen: TryHandle, TryHandleFunc, Replace,
+// Swap, Merge, HandleRoutes and HandleOpenAPI. Those that do not panic with
+// it, as Handle does for an invalid pattern: Handle, HandleFunc, the methods
+// of Group that register routes such as Redirect, Group.NotFound, Remove and
+// Matcher. The fields of the copy, such as NotFoundHandler, may still be set
+// but should not be changed while serving requests. Changes to the original
+// router do not affect the copy.
+func (r *Router) Freeze() *Router {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	f := New()
+	f.NotFoundHandler = r.NotFoundHandler
+	f.MethodNotAllowedHandler = r.MethodNotAllowedHandler
+	if !isLogError(r.ErrorHandler) {
+		f.ErrorHandler = r.ErrorHandler
+	}
+	f.RouteErrorHandler = r.RouteErrorHandler
+	f.ErrorLog = r.ErrorLog
+	f.SkipClean = r.SkipClean
+	f.DecodePath = r.DecodePath
+	f.UnescapeVars = r.UnescapeVars
+	f.RedirectStatus = r.RedirectStatus
+	f.RedirectFixedPath = r.RedirectFixedPath
+	f.TrailingSlash = r.TrailingSlash
+	f.Tracer = r.Tracer
+	f.RecordStats = r.RecordStats
+	f.SuggestRoutes = r.SuggestRoutes
+	f.CanonicalHost = r.CanonicalHost
+	f.MaxPathLength = r.MaxPathLength
+	f.MaxPathSegments = r.MaxPathSegments
+	f.mappers = append(f.mappers, r.mappers...)
+	f.before = append(f.before, r.before...)
+	f.after = append(f.after, r.after...)
+
+	for name, matcher := range r.tree.matchers {
+		f.tree.matchers[name] = matcher
+	}
+
+	for _, ep := range r.sortedEndpoints() {
+		for _, e := range ep.routes {
+			copied := *e
+			f.add(ep.host, ep.pattern, &copied)
+		}
+	}
+
+	for host, tree := range r.notFounds {
+		f.notFounds[host] = &treeLookup{root: tree.root.clone(cloneEndpoint), matchers: f.tree.matchers}
+	}
+
+	f.frozen = true
+	return f
+}
+
+// isLogError returns true if the ErrorHandler is the default of a Router, which
+// is bound to that Router so cannot be shared with a copy of it.
+func isLogError(h func(http.ResponseWriter, *http.Request, error)) bool {
+	return h != nil && reflect.ValueOf(h).Pointer() == reflect.ValueOf((&Router{}).logError).Pointer()
+}
+
+// checkFrozen panics with ErrFrozen if the router is frozen.
+func (r *Router) checkFrozen() {
+	if r.frozen {
+		panic(ErrFrozen)
+	}
+}
@@ -0,0 +1,96 @@
+package route
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouterFreeze(t *testing.T) {
+	router := New()
+	router.Matcher("short", func(segment string) (string, bool) {
+		return segment, len(segment) <= 3
+	})
+
+	userHandler := &recordingHandler{}
+	reportHandler := &recordingHandler{}
+	router.Handle("GET /users/:id<short>", userHandler, Name("user"))
+	router.Handle("/reports/:id", reportHandler, Formats("json"))
+	router.Group("/admin").NotFound(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(418)
+	}))
+
+	frozen := router.Freeze()
+
+	// changes to the original are not seen
+	router.Handle("/later", &recordingHandler{})
+	router.Replace("/reports/:id", &recordingHandler{})
+
+	serve := func(method, path string) int {
+		r, _ := http.NewRequest(method, path, nil)
+		w := httptest.NewRecorder()
+		frozen.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	assert.Equal(t, 200, serve("GET", "/users/abc"))
+	assert.Equal(t, 405, serve("POST", "/users/abc"))
+	assert.Equal(t, 404, serve("GET", "/users/abcd"))
+	assert.Equal(t, 200, serve("GET", "/reports/1.json"))
+	assert.Equal(t, 418, serve("GET", "/admin/missing"))
+	assert.Equal(t, 404, serve("GET", "/later"))
+	assert.True(t, userHandler.Used)
+	assert.Equal(t, map[string]string{"id": "1", "format": "json"}, reportHandler.Vars)
+
+	u, err := frozen.URL("user", "id", "abc")
+	assert.Nil(t, err)
+	assert.Equal(t, "/users/abc", u.String())
+
+	assert.Equal(t, ErrFrozen, frozen.TryHandle("/new", &recordingHandler{}))
+	assert.Equal(t, ErrFrozen, frozen.Replace("/reports/:id", &recordingHandler{}))
+	assert.Equal(t, ErrFrozen, frozen.Swap(func(r *Router) {}))
+	assert.Equal(t, ErrFrozen, frozen.Merge(New()))
+	assert.Equal(t, ErrFrozen, checkPanics(t, func() { frozen.Handle("/new", &recordingHandler{}) }))
+	assert.Equal(t, ErrFrozen, checkPanics(t, func() { frozen.Remove("/reports/:id") }))
+	assert.Equal(t, ErrFrozen, checkPanics(t, func() { frozen.Group("/x").NotFound(http.NotFoundHandler()) }))
+}
+
+func TestRouterFreezeMutators(t *testing.T) {
+	frozen := New().Freeze()
+	handler := func(w http.ResponseWriter, r *http.Request) error { return nil }
+
+	assert.Equal(t, ErrFrozen, frozen.TryHandleFunc("/new", handler))
+	assert.Equal(t, ErrFrozen, frozen.HandleRoutes(nil, Registry{}))
+
+	assert.Equal(t, ErrFrozen, checkPanics(t, func() { frozen.HandleFunc("/new", handler) }))
+	assert.Equal(t, ErrFrozen, checkPanics(t, func() { frozen.Redirect("/old", "/new", 301) }))
+	assert.Equal(t, ErrFrozen, checkPanics(t, func() { frozen.Group("/x").Handle("/new", &recordingHandler{}) }))
+	assert.Equal(t, ErrFrozen, checkPanics(t, func() {
+		frozen.Matcher("any", func(segment string) (string, bool) { return segment, true })
+	}))
+}
+
+func TestRouterFreezeLogsToCopy(t *testing.T) {
+	var original, copied bytes.Buffer
+
+	router := New()
+	router.ErrorLog = log.New(&original, "", 0)
+	router.HandleFunc("/fail", func(w http.ResponseWriter, r *http.Request) error {
+		return errors.New("failed")
+	})
+
+	frozen := router.Freeze()
+	frozen.ErrorLog = log.New(&copied, "", 0)
+
+	w := httptest.NewRecorder()
+	frozen.ServeHTTP(w, httptest.NewRequest("GET", "/fail", nil))
+
+	assert.Equal(t, 500, w.Code)
+	assert.Equal(t, "", original.String())
+	assert.Equal(t, "route: GET /fail: failed\n", copied.String())
+}
@@ -0,0 +1,174 @@
+package route
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Group registers routes to a Router below a common path prefix, and with
+// common options.
+//
+//   router.Route("/users", func(g *route.Group) {
+//     g.Get("/", listUsers)
+//     g.Post("/", createUser)
+//
+//     g.Route("/:name", func(g *route.Group) {
+//       g.Get("/", showUser)
+//       g.Delete("/", deleteUser)
+//     })
+//   })
+type Group struct {
+	router *Router
+	host   string
+	prefix string
+	opts   []Option
+}
+
+// Group returns a Group for registering routes below the prefix. The options
+// are applied to every route registered with the Group, before those given on
+// registration.
+func (r *Router) Group(prefix string, opts ...Option) *Group {
+	return &Group{router: r, prefix: strings.TrimSuffix(prefix, "/"), opts: opts}
+}
+
+// Host returns a Group for registering routes that only match requests for the
+// host. Routes registered for a host are tried before those for any host.
+//
+//   api := router.Host("api.example.com")
+//   api.Get("/users/:name", apiUserHandler)
+func (r *Router) Host(host string, opts ...Option) *Group {
+	return &Group{router: r, host: strings.ToLower(host), opts: opts}
+}
+
+// Route calls fn with a Group for registering routes below the prefix.
+func (r *Router) Route(prefix string, fn func(*Group)) {
+	fn(r.Group(prefix))
+}
+
+// Get registers the handler for GET requests to the path.
+func (r *Router) Get(path string, handler interface{}, opts ...Option) {
+	r.Group("").Get(path, handler, opts...)
+}
+
+// Post registers the handler for POST requests to the path.
+func (r *Router) Post(path string, handler interface{}, opts ...Option) {
+	r.Group("").Post(path, handler, opts...)
+}
+
+// Put registers the handler for PUT requests to the path.
+func (r *Router) Put(path string, handler interface{}, opts ...Option) {
+	r.Group("").Put(path, handler, opts...)
+}
+
+// Patch registers the handler for PATCH requests to the path.
+func (r *Router) Patch(path string, handler interface{}, opts ...Option) {
+	r.Group("").Patch(path, handler, opts...)
+}
+
+// Delete registers the handler for DELETE requests to the path.
+func (r *Router) Delete(path string, handler interface{}, opts ...Option) {
+	r.Group("").Delete(path, handler, opts...)
+}
+
+// Group returns a Group for registering routes below the prefix, relative to
+// this Group.
+func (g *Group) Group(prefix string, opts ...Option) *Group {
+	return &Group{
+		router: g.router,
+		host:   g.host,
+		prefix: g.prefix + strings.TrimSuffix(prefix, "/"),
+		opts:   append(append([]Option{}, g.opts...), opts...),
+	}
+}
+
+// Route calls fn with a Group for registering routes below the prefix, relative
+// to this Group.
+func (g *Group) Route(prefix string, fn func(*Group)) {
+	fn(g.Group(prefix))
+}
+
+// NotFound sets the handler to use for requests with paths below the prefix of
+// the Group that do not match a route, instead of the NotFoundHandler of the
+// Router. If Groups are nested, the handler of the deepest is used.
+//
+//   api := router.Group("/api")
+//   api.NotFound(jsonNotFoundHandler)
+func (g *Group) NotFound(handler http.Handler) {
+	r := g.router
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checkFrozen()
+	r.changed()
+
+	tree, ok := r.notFounds[g.host]
+	if !ok {
+		tree = newLookup()
+		tree.matchers = r.tree.matchers
+		r.notFounds[g.host] = tree
+	}
+
+	tree.Add(g.prefix+"/*path", nilErrorHandler{handler})
+}
+
+// Handle registers the handler for the path, relative to the Group. The path
+// may begin with a method, as with Router.Handle.
+func (g *Group) Handle(path string, handler interface{}, opts ...Option) {
+	g.router.Handle(g.pattern(path), handler, append(append([]Option{}, g.opts...), opts...)...)
+}
+
+// HandleFunc registers the handler function for the path, relative to the
+// Group.
+func (g *Group) HandleFunc(path string, handler interface{}, opts ...Option) {
+	g.router.HandleFunc(g.pattern(path), handler, append(append([]Option{}, g.opts...), opts...)...)
+}
+
+// Get registers the handler for GET requests to the path.
+func (g *Group) Get(path string, handler interface{}, opts ...Option) {
+	g.handleMethod("GET", path, handler, opts)
+}
+
+// Post registers the handler for POST requests to the path.
+func (g *Group) Post(path string, handler interface{}, opts ...Option) {
+	g.handleMethod("POST", path, handler, opts)
+}
+
+// Put registers the handler for PUT requests to the path.
+func (g *Group) Put(path string, handler interface{}, opts ...Option) {
+	g.handleMethod("PUT", path, handler, opts)
+}
+
+// Patch registers the handler for PATCH requests to the path.
+func (g *Group) Patch(path string, handler interface{}, opts ...Option) {
+	g.handleMethod("PATCH", path, handler, opts)
+}
+
+// Delete registers the handler for DELETE requests to the path.
+func (g *Group) Delete(path string, handler interface{}, opts ...Option) {
+	g.handleMethod("DELETE", path, handler, opts)
+}
+
+func (g *Group) handleMethod(method, path string, handler interface{}, opts []Option) {
+	opts = append([]Option{Methods(method)}, opts...)
+
+	switch handler.(type) {
+	case Handler, http.Handler:
+		g.Handle(path, handler, opts...)
+	default:
+		g.HandleFunc(path, handler, opts...)
+	}
+}
+
+// pattern joins the path to the host and prefix of the group, keeping any
+// method at the start of path.
+func (g *Group) pattern(path string) string {
+	method := ""
+	if i := strings.IndexAny(path, " \t"); i >= 0 {
+		method, path = path[:i+1], strings.TrimLeft(path[i:], " \t")
+	}
+
+	if path == "/" && g.prefix != "" {
+		path = ""
+	}
+
+	return method + g.host + g.prefix + path
+}
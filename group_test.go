@@ -0,0 +1,183 @@
+package route
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroup(t *testing.T) {
+	router := New()
+
+	listHandler := &recordingHandler{}
+	showHandler := &recordingHandler{}
+	deleteHandler := &recordingHandler{}
+
+	router.Route("/users", func(g *Group) {
+		g.Get("/", listHandler)
+
+		g.Route("/:name", func(g *Group) {
+			g.Get("/", showHandler)
+			g.Delete("/", deleteHandler)
+		})
+	})
+
+	r, _ := http.NewRequest("GET", "/users", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	assert.True(t, listHandler.Used)
+
+	r, _ = http.NewRequest("GET", "/users/gopher", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	assert.True(t, showHandler.Used)
+	assert.Equal(t, map[string]string{"name": "gopher"}, showHandler.Vars)
+	assert.False(t, deleteHandler.Used)
+
+	r, _ = http.NewRequest("DELETE", "/users/gopher", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	assert.True(t, deleteHandler.Used)
+}
+
+func TestGroupWithHandleFunc(t *testing.T) {
+	router := New()
+
+	g := router.Group("/api/")
+	g.Post("/things", func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(201)
+		return nil
+	})
+	g.HandleFunc("PUT /things", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(418)
+	})
+
+	r, _ := http.NewRequest("POST", "/api/things", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	assert.Equal(t, 201, w.Code)
+
+	r, _ = http.NewRequest("PUT", "/api/things", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	assert.Equal(t, 418, w.Code)
+
+	r, _ = http.NewRequest("GET", "/api/things", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	assert.Equal(t, 405, w.Code)
+}
+
+func TestGroupOptions(t *testing.T) {
+	router := New()
+
+	g := router.Group("/admin", Methods("GET"))
+	g.Handle("/users", &recordingHandler{}, Name("admin.users"))
+
+	u, err := router.URL("admin.users")
+	assert.Nil(t, err)
+	assert.Equal(t, "/admin/users", u.String())
+
+	r, _ := http.NewRequest("POST", "/admin/users", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	assert.Equal(t, 405, w.Code)
+}
+
+func TestRouterMethodHelpers(t *testing.T) {
+	router := New()
+
+	for _, method := range []string{"GET", "POST", "PUT", "PATCH", "DELETE"} {
+		handler := &recordingHandler{}
+
+		switch method {
+		case "GET":
+			router.Get("/thing", handler)
+		case "POST":
+			router.Post("/thing", handler)
+		case "PUT":
+			router.Put("/thing", handler)
+		case "PATCH":
+			router.Patch("/thing", handler)
+		case "DELETE":
+			router.Delete("/thing", handler)
+		}
+
+		r, _ := http.NewRequest(method, "/thing", nil)
+		router.ServeHTTP(httptest.NewRecorder(), r)
+		assert.True(t, handler.Used, method)
+	}
+}
+
+func TestRouterHost(t *testing.T) {
+	router := New()
+
+	apiHandler := &recordingHandler{}
+	defaultHandler := &recordingHandler{}
+	otherHandler := &recordingHandler{}
+
+	router.Host("api.example.com").Get("/users/:name", apiHandler, Name("api.user"))
+	router.Get("/users/:name", defaultHandler)
+	router.Get("other.example.com/only", otherHandler)
+
+	r, _ := http.NewRequest("GET", "http://API.example.com:8080/users/gopher", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	assert.True(t, apiHandler.Used)
+	assert.False(t, defaultHandler.Used)
+
+	r, _ = http.NewRequest("GET", "http://www.example.com/users/gopher", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	assert.True(t, defaultHandler.Used)
+
+	r, _ = http.NewRequest("GET", "http://other.example.com/only", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	assert.True(t, otherHandler.Used)
+
+	r, _ = http.NewRequest("GET", "http://www.example.com/only", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	assert.Equal(t, 404, w.Code)
+
+	u, err := router.URL("api.user", "name", "gopher")
+	assert.Nil(t, err)
+	assert.Equal(t, "//api.example.com/users/gopher", u.String())
+}
+
+func TestGroupNotFound(t *testing.T) {
+	router := New()
+	router.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("html"))
+	})
+
+	api := router.Group("/api")
+	api.Get("/users", &recordingHandler{})
+	api.NotFound(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("json"))
+	}))
+
+	api.Group("/v2/:tenant").NotFound(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("v2"))
+	}))
+
+	router.Host("admin.example.com").Group("/api").NotFound(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("admin"))
+	}))
+
+	cases := map[string]string{
+		"http://example.com/nowhere":              "html",
+		"http://example.com/api":                  "json",
+		"http://example.com/api/nowhere":          "json",
+		"http://example.com/api/v2":               "json",
+		"http://example.com/api/v2/acme/nowhere":  "v2",
+		"http://example.com/apis":                 "html",
+		"http://admin.example.com/api/nowhere":    "admin",
+		"http://admin.example.com:80/api/nowhere": "admin",
+		"http://admin.example.com/nowhere":        "html",
+	}
+
+	for path, expected := range cases {
+		r, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		assert.Equal(t, expected, w.Body.String(), path)
+	}
+}
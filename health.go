@@ -0,0 +1,142 @@
+package route
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// A HealthCheck returns an error if the thing it checks, such as a database
+// connection, is not healthy.
+type HealthCheck func(ctx context.Context) error
+
+// HealthOptions configures the endpoints registered by Health.
+type HealthOptions struct {
+	// Live are the checks of /healthz, which should only fail if the process
+	// needs restarting.
+	Live map[string]HealthCheck
+
+	// Ready are the checks of /readyz, which fail while the process cannot
+	// serve requests, for example before it has connected to its dependencies.
+	Ready map[string]HealthCheck
+
+	// Timeout limits the time each check may take, by default 5 seconds.
+	Timeout time.Duration
+
+	// LivePath and ReadyPath replace the default paths of "/healthz" and
+	// "/readyz".
+	LivePath, ReadyPath string
+}
+
+// HealthStatus is the body of responses from the endpoints registered by
+// Health.
+type HealthStatus struct {
+	// Status is "ok" if all checks passed, otherwise "fail".
+	Status string `json:"status"`
+
+	// Checks gives the result of each check by name.
+	Checks map[string]CheckStatus `json:"checks,omitempty"`
+}
+
+// CheckStatus is the result of a HealthCheck.
+type CheckStatus struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Health registers liveness and readiness endpoints, at /healthz and /readyz,
+// which run their checks concurrently and respond with a HealthStatus, with the
+// status 200 OK if all checks pass or 503 Service Unavailable if any fail:
+//
+//   route.Health(router, route.HealthOptions{
+//     Ready: map[string]route.HealthCheck{
+//       "db": db.PingContext,
+//     },
+//   })
+//
+//   GET /readyz   {"status":"fail","checks":{"db":{"status":"fail","error":"connection refused"}}}
+//
+// An endpoint without checks always responds that it is ok.
+func Health(r Registrar, opts HealthOptions) {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Second
+	}
+	if opts.LivePath == "" {
+		opts.LivePath = "/healthz"
+	}
+	if opts.ReadyPath == "" {
+		opts.ReadyPath = "/readyz"
+	}
+
+	r.Handle(opts.LivePath, healthHandler(opts.Live, opts.Timeout), Methods("GET", "HEAD"))
+	r.Handle(opts.ReadyPath, healthHandler(opts.Ready, opts.Timeout), Methods("GET", "HEAD"))
+}
+
+func healthHandler(checks map[string]HealthCheck, timeout time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := runChecks(r.Context(), checks, timeout)
+
+		code := http.StatusOK
+		if status.Status != "ok" {
+			code = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(status)
+	})
+}
+
+// runChecks runs the checks concurrently, each limited to the timeout.
+func runChecks(ctx context.Context, checks map[string]HealthCheck, timeout time.Duration) HealthStatus {
+	names := make([]string, 0, len(checks))
+	for name := range checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	results := make([]CheckStatus, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, check HealthCheck) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			done := make(chan error, 1)
+			go func() { done <- check(ctx) }()
+
+			var err error
+			select {
+			case err = <-done:
+			case <-ctx.Done():
+				err = ctx.Err()
+			}
+
+			results[i] = CheckStatus{Status: "ok"}
+			if err != nil {
+				results[i] = CheckStatus{Status: "fail", Error: err.Error()}
+			}
+		}(i, checks[name])
+	}
+	wg.Wait()
+
+	status := HealthStatus{Status: "ok"}
+	if len(names) > 0 {
+		status.Checks = make(map[string]CheckStatus, len(names))
+	}
+	for i, name := range names {
+		status.Checks[name] = results[i]
+		if results[i].Status != "ok" {
+			status.Status = "fail"
+		}
+	}
+
+	return status
+}
@@ -0,0 +1,72 @@
+package route
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealth(t *testing.T) {
+	router := New()
+	Health(router, HealthOptions{
+		Ready: map[string]HealthCheck{
+			"db":    func(ctx context.Context) error { return nil },
+			"cache": func(ctx context.Context) error { return errors.New("connection refused") },
+			"slow": func(ctx context.Context) error {
+				time.Sleep(time.Second)
+				return nil
+			},
+		},
+		Timeout: 10 * time.Millisecond,
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/healthz", nil))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"status":"ok"}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+	assert.Equal(t, 503, w.Code)
+
+	var status HealthStatus
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &status))
+	assert.Equal(t, HealthStatus{
+		Status: "fail",
+		Checks: map[string]CheckStatus{
+			"db":    {Status: "ok"},
+			"cache": {Status: "fail", Error: "connection refused"},
+			"slow":  {Status: "fail", Error: "context deadline exceeded"},
+		},
+	}, status)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/healthz", nil))
+	assert.Equal(t, 405, w.Code)
+}
+
+func TestHealthPaths(t *testing.T) {
+	router := New()
+	Health(router.Group("/ops"), HealthOptions{
+		LivePath:  "/live",
+		ReadyPath: "/ready",
+		Ready: map[string]HealthCheck{
+			"db": func(ctx context.Context) error { return nil },
+		},
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/ops/live", nil))
+	assert.Equal(t, 200, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/ops/ready", nil))
+	assert.Equal(t, 200, w.Code)
+	assert.JSONEq(t, `{"status":"ok","checks":{"db":{"status":"ok"}}}`, w.Body.String())
+}
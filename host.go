@@ -0,0 +1,76 @@
+package route
+
+import (
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// A HostPolicy decides the canonical form of the host requests are made for.
+// Requests for any other form are redirected to it, keeping their path and
+// query, with RedirectStatus.
+type HostPolicy int
+
+const (
+	// AnyHost accepts requests for the host as given. This is the default.
+	AnyHost HostPolicy = iota
+
+	// LowercaseHost redirects requests for a host containing uppercase letters
+	// to the lowercase host, so "Example.COM" redirects to "example.com".
+	LowercaseHost
+
+	// ApexHost redirects requests for a "www." subdomain to the domain itself,
+	// so "www.example.com" redirects to "example.com". Hosts are also made
+	// lowercase.
+	ApexHost
+
+	// WWWHost redirects requests for a domain to its "www." subdomain, so
+	// "example.com" redirects to "www.example.com". Hosts that are addresses or
+	// have a single label, as "localhost", are not redirected. Hosts are also
+	// made lowercase.
+	WWWHost
+)
+
+// canonical returns the canonical form of the host, which may include a port.
+func (policy HostPolicy) canonical(host string) string {
+	if policy == AnyHost || host == "" {
+		return host
+	}
+
+	name := stripPort(host)
+	port := host[len(name):]
+	name = strings.ToLower(name)
+
+	switch policy {
+	case ApexHost:
+		name = strings.TrimPrefix(name, "www.")
+	case WWWHost:
+		if !strings.HasPrefix(name, "www.") && strings.Contains(name, ".") && !isAddr(name) {
+			name = "www." + name
+		}
+	}
+
+	return name + port
+}
+
+// isAddr returns true if the host is an IP address.
+func isAddr(host string) bool {
+	_, err := netip.ParseAddr(strings.Trim(host, "[]"))
+	return err == nil
+}
+
+// redirectHost redirects the request to the canonical form of its host,
+// returning false if it is already canonical.
+func (r *Router) redirectHost(w http.ResponseWriter, req *http.Request) bool {
+	host := r.CanonicalHost.canonical(req.Host)
+	if host == req.Host {
+		return false
+	}
+
+	url := *req.URL
+	url.Scheme = requestScheme(req)
+	url.Host = host
+
+	r.redirect(w, req, url.String())
+	return true
+}
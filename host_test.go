@@ -0,0 +1,53 @@
+package route
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouterCanonicalHost(t *testing.T) {
+	testCases := map[string]struct {
+		policy   HostPolicy
+		method   string
+		target   string
+		tls      bool
+		code     int
+		location string
+	}{
+		"any":               {AnyHost, "GET", "http://WWW.Example.com/a", false, 200, ""},
+		"lowercase":         {LowercaseHost, "GET", "http://Example.COM/a?b=c", false, 301, "http://example.com/a?b=c"},
+		"lowercase already": {LowercaseHost, "GET", "http://www.example.com/a", false, 200, ""},
+		"apex":              {ApexHost, "GET", "http://www.example.com:8080/a", false, 301, "http://example.com:8080/a"},
+		"apex tls":          {ApexHost, "GET", "http://WWW.example.com/a%2Fb", true, 301, "https://example.com/a%2Fb"},
+		"apex post":         {ApexHost, "POST", "http://www.example.com/a", false, 308, "http://example.com/a"},
+		"apex already":      {ApexHost, "GET", "http://example.com/a", false, 200, ""},
+		"www":               {WWWHost, "GET", "http://example.com/a", false, 301, "http://www.example.com/a"},
+		"www already":       {WWWHost, "GET", "http://www.example.com/a", false, 200, ""},
+		"www localhost":     {WWWHost, "GET", "http://localhost:8080/a", false, 200, ""},
+		"www address":       {WWWHost, "GET", "http://127.0.0.1/a", false, 200, ""},
+		"www ipv6 address":  {WWWHost, "GET", "http://[::1]:8080/a", false, 200, ""},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			router := New()
+			router.CanonicalHost = tc.policy
+			router.HandleFunc("/*path", func(w http.ResponseWriter, r *http.Request) {})
+
+			req := httptest.NewRequest(tc.method, tc.target, nil)
+			if tc.tls {
+				req.TLS = &tls.ConnectionState{}
+			}
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.code, rec.Code)
+			assert.Equal(t, tc.location, rec.Header().Get("Location"))
+		})
+	}
+}
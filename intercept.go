@@ -0,0 +1,94 @@
+package route
+
+import (
+	"net/http"
+	"time"
+)
+
+// A BeforeFunc is called before a request is dispatched to the route it
+// matched. If it returns false the handler is not called, so it must have
+// written a response.
+type BeforeFunc func(w http.ResponseWriter, r *http.Request) bool
+
+// A BeforeRequestFunc is a BeforeFunc that also returns the request to continue
+// with, which may have been given a new context.
+type BeforeRequestFunc func(w http.ResponseWriter, r *http.Request) (*http.Request, bool)
+
+// An AfterFunc is called once the handler for a request, and the ErrorHandler
+// if it returned an error, have finished.
+type AfterFunc func(r *http.Request, result Result)
+
+// Result describes how a request was handled.
+type Result struct {
+	// Pattern, Host and Name identify the route, as for RouteMatch.
+	Pattern string
+	Host    string
+	Name    string
+
+	// Status is the status of the response, and Size the number of bytes
+	// written for its body.
+	Status int
+	Size   int64
+
+	// Duration is the time taken by the handler and any ErrorHandler.
+	Duration time.Duration
+
+	// Err is the error returned by the handler, if any.
+	Err error
+}
+
+// Before adds fn to the functions called before each request is dispatched to
+// the route it matched, in the order they were added. Unlike middleware,
+// wrapping handlers as they are registered, it can be added to a router that
+// has already been built, including one returned by Freeze:
+//
+//   router.Before(func(w http.ResponseWriter, r *http.Request) bool {
+//     if maintenance.Load() {
+//       http.Error(w, "down for maintenance", http.StatusServiceUnavailable)
+//       return false
+//     }
+//     return true
+//   })
+//
+// The request has been matched, so Vars, Pattern and Meta can be used. Requests
+// that match no route, or are redirected by the router, are not passed to fn.
+func (r *Router) Before(fn BeforeFunc) {
+	r.BeforeRequest(func(w http.ResponseWriter, req *http.Request) (*http.Request, bool) {
+		return req, fn(w, req)
+	})
+}
+
+// BeforeRequest adds fn to the functions called before each request is
+// dispatched, as Before does, but the request fn returns is passed to the
+// functions after it and to the handler. This lets fn add values to the context
+// of the request:
+//
+//   router.BeforeRequest(func(w http.ResponseWriter, r *http.Request) (*http.Request, bool) {
+//     return r.WithContext(context.WithValue(r.Context(), startKey{}, time.Now())), true
+//   })
+func (r *Router) BeforeRequest(fn BeforeRequestFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.before = append(r.before[:len(r.before):len(r.before)], fn)
+	r.changed()
+}
+
+// After adds fn to the functions called after each request dispatched to a
+// route has been handled, in the order they were added. As for Before it can be
+// added to a router that has already been built:
+//
+//   router.After(func(r *http.Request, result route.Result) {
+//     if result.Err != nil {
+//       errorCount.WithLabelValues(result.Pattern).Inc()
+//     }
+//   })
+//
+// Requests that are stopped by a BeforeFunc are not passed to fn.
+func (r *Router) After(fn AfterFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.after = append(r.after[:len(r.after):len(r.after)], fn)
+	r.changed()
+}
@@ -0,0 +1,68 @@
+package route
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouterBeforeAfter(t *testing.T) {
+	failure := errors.New("failed")
+
+	router := New()
+	router.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		http.Error(w, err.Error(), 500)
+	}
+	router.HandleFunc("GET /users/:id", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("user " + Vars(r)["id"]))
+	}, Name("user"))
+	router.HandleFunc("GET /fail", func(w http.ResponseWriter, r *http.Request) error {
+		return failure
+	})
+
+	frozen := router.Freeze()
+
+	var calls []string
+	var results []Result
+	frozen.Before(func(w http.ResponseWriter, r *http.Request) bool {
+		calls = append(calls, "first "+Vars(r)["id"])
+		return true
+	})
+	frozen.Before(func(w http.ResponseWriter, r *http.Request) bool {
+		calls = append(calls, "second")
+		if Vars(r)["id"] == "blocked" {
+			w.WriteHeader(403)
+			return false
+		}
+		return true
+	})
+	frozen.After(func(r *http.Request, result Result) {
+		result.Duration = 0
+		results = append(results, result)
+	})
+
+	w := httptest.NewRecorder()
+	frozen.ServeHTTP(w, httptest.NewRequest("GET", "/users/5", nil))
+	assert.Equal(t, "user 5", w.Body.String())
+
+	w = httptest.NewRecorder()
+	frozen.ServeHTTP(w, httptest.NewRequest("GET", "/users/blocked", nil))
+	assert.Equal(t, 403, w.Code)
+	assert.Equal(t, "", w.Body.String())
+
+	frozen.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/fail", nil))
+	frozen.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/missing", nil))
+
+	assert.Equal(t, []string{"first 5", "second", "first blocked", "second", "first ", "second"}, calls)
+	assert.Equal(t, []Result{
+		{Pattern: "/users/:id", Name: "user", Status: 200, Size: 6},
+		{Pattern: "/fail", Status: 500, Size: 7, Err: failure},
+	}, results)
+
+	calls = nil
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/5", nil))
+	assert.Empty(t, calls)
+}
@@ -0,0 +1,175 @@
+package route
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type localeKey struct{}
+
+// Locales routes requests by a leading locale segment, as "/en/about" or
+// "/fr-CA/about". The segment is removed from the path of the request before it
+// is passed on, so routes are registered once for every locale:
+//
+//   locales := route.Locales{Supported: []string{"en", "fr", "fr-CA"}, Default: "en"}
+//
+//   router.Get("/about", aboutHandler)
+//   http.ListenAndServe(":8080", locales.Handler(router))
+//
+//   /fr-CA/about      aboutHandler with Locale(r) == "fr-CA"
+//   /about            redirects to /fr/about for "Accept-Language: fr-FR, en"
+//
+// Requests without a supported locale are redirected to the path beginning
+// with the locale best matching their Accept-Language header, or Default.
+type Locales struct {
+	// Supported lists the locales that may begin a path. They are matched
+	// ignoring case, and Locale returns them as given here.
+	Supported []string
+
+	// Default is the locale redirected to when none of those accepted by the
+	// client are supported. If empty the first of Supported is used.
+	Default string
+
+	// Exclude lists prefixes of paths that do not begin with a locale, such as
+	// "/assets/", which are passed on unchanged.
+	Exclude []string
+}
+
+// Handler returns a handler that removes the locale from the path of requests
+// before passing them to h, and redirects those without one.
+func (l Locales) Handler(h http.Handler) http.Handler {
+	supported := make(map[string]string, len(l.Supported))
+	for _, locale := range l.Supported {
+		supported[strings.ToLower(locale)] = locale
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, prefix := range l.Exclude {
+			if strings.HasPrefix(r.URL.Path, prefix) {
+				h.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		segment, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+		locale, ok := supported[strings.ToLower(segment)]
+		if !ok {
+			l.redirect(w, r)
+			return
+		}
+
+		u := *r.URL
+		u.Path = stripLocale(u.Path, segment)
+		if u.RawPath != "" {
+			u.RawPath = stripLocale(u.RawPath, segment)
+		}
+
+		r = r.WithContext(context.WithValue(r.Context(), localeKey{}, locale))
+		r.URL = &u
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+// stripLocale removes the leading segment from the path.
+func stripLocale(path, segment string) string {
+	path = path[1+len(segment):]
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+// redirect redirects the request to its path beginning with the locale that
+// best matches its Accept-Language header.
+func (l Locales) redirect(w http.ResponseWriter, r *http.Request) {
+	u := *r.URL
+	u.Path = "/" + l.negotiate(r.Header.Get("Accept-Language")) + u.Path
+	u.RawPath = ""
+
+	code := http.StatusTemporaryRedirect
+	if r.Method == "GET" || r.Method == "HEAD" {
+		code = http.StatusFound
+	}
+
+	w.Header().Add("Vary", "Accept-Language")
+	http.Redirect(w, r, u.String(), code)
+}
+
+// negotiate returns the supported locale that best matches the Accept-Language
+// header. A language accepted without a region matches a supported locale for
+// any region, and the reverse.
+func (l Locales) negotiate(header string) string {
+	for _, tag := range acceptedLanguages(header) {
+		for _, locale := range l.Supported {
+			if strings.EqualFold(tag, locale) {
+				return locale
+			}
+		}
+		for _, locale := range l.Supported {
+			if strings.EqualFold(primaryLanguage(tag), primaryLanguage(locale)) {
+				return locale
+			}
+		}
+	}
+
+	if l.Default != "" || len(l.Supported) == 0 {
+		return l.Default
+	}
+	return l.Supported[0]
+}
+
+// acceptedLanguages returns the language tags of the Accept-Language header,
+// most preferred first, leaving out those with a quality of 0 and "*".
+func acceptedLanguages(header string) []string {
+	type language struct {
+		tag string
+		q   float64
+	}
+
+	var languages []language
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if q > 0 {
+			languages = append(languages, language{tag, q})
+		}
+	}
+
+	sort.SliceStable(languages, func(i, j int) bool {
+		return languages[i].q > languages[j].q
+	})
+
+	tags := make([]string, len(languages))
+	for i, language := range languages {
+		tags[i] = language.tag
+	}
+	return tags
+}
+
+// primaryLanguage returns the language of the tag without its region, as "fr"
+// for "fr-CA".
+func primaryLanguage(tag string) string {
+	language, _, _ := strings.Cut(tag, "-")
+	return language
+}
+
+// Locale returns the locale the path of the request began with, as routed by
+// Locales, or "" if it was not.
+func Locale(r *http.Request) string {
+	locale, _ := r.Context().Value(localeKey{}).(string)
+	return locale
+}
@@ -0,0 +1,67 @@
+package route
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocales(t *testing.T) {
+	router := New()
+	router.Get("/about", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("about " + Locale(r)))
+	}))
+	router.Get("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("home " + Locale(r)))
+	}))
+	router.Get("/assets/*path", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("asset " + Vars(r)["path"]))
+	}))
+
+	handler := Locales{
+		Supported: []string{"en", "fr", "fr-CA"},
+		Default:   "en",
+		Exclude:   []string{"/assets/"},
+	}.Handler(router)
+
+	testCases := map[string]struct {
+		path     string
+		language string
+		code     int
+		body     string
+		location string
+	}{
+		"locale":          {"/fr-CA/about", "", 200, "about fr-CA", ""},
+		"ignoring case":   {"/FR-ca/about", "", 200, "about fr-CA", ""},
+		"root":            {"/en", "", 200, "home en", ""},
+		"root with slash": {"/en/", "", 200, "home en", ""},
+		"excluded":        {"/assets/site.css", "", 200, "asset site.css", ""},
+		"missing":         {"/about?x=1", "", 302, "", "/en/about?x=1"},
+		"unsupported":     {"/de/about", "de", 302, "", "/en/de/about"},
+		"accepted":        {"/about", "de;q=0.9, fr-FR;q=0.8, en;q=0.5", 302, "", "/fr/about"},
+		"accepted region": {"/about", "fr-ca, fr", 302, "", "/fr-CA/about"},
+		"not accepted":    {"/about", "fr;q=0, *", 302, "", "/en/about"},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tc.path, nil)
+			if tc.language != "" {
+				req.Header.Set("Accept-Language", tc.language)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.code, rec.Code)
+			if tc.code == 200 {
+				assert.Equal(t, tc.body, rec.Body.String())
+			} else {
+				assert.Equal(t, tc.location, rec.Header().Get("Location"))
+				assert.Equal(t, "Accept-Language", rec.Header().Get("Vary"))
+			}
+		})
+	}
+}
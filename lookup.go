@@ -1,7 +1,12 @@
 package route
 
 import (
+	"errors"
+	"fmt"
+	"net/http"
 	"path"
+	"regexp"
+	"sort"
 	"strings"
 )
 
@@ -32,6 +37,19 @@ would both match. It acts like a leaf on the tree, and I've called it a "greedy"
 leaf since it will match any input. These are always considered after exact and
 wild matches have failed.
 
+A wild edge may also carry a constraint, as in /posts/:id(\d+), so that it is
+only taken when the path fragment matches the regular expression. A node can
+have many constrained wild edges, which are tried in the order they were added,
+but only a single unconstrained one which is always tried last. Constraints
+can also name a MatcherFunc, as in /users/:id<base58>, which decides whether
+the edge is taken and also the value given to the parameter, or list the
+allowed values, as in /reports/:period{daily,weekly}.
+
+Wild edges can also be surrounded by fixed text within the path fragment, as
+in /files/:name.json or /v{version}/users, in which case the edge is only taken
+if the fragment starts and ends with the text, and the parameter is whatever
+is between. These are tried before the unconstrained edge too.
+
 There is one interesting edge case to discuss. Consider the following tree.
 
 	( ) --[image]--> ( ) --[my]--> ( ) --[photo.jpg]--> (Handler)
@@ -42,14 +60,29 @@ For a path like /image/my/cat.gif we would start by following the image->my
 edges but then hit a dead-end, when in fact we could have matched
 image->*path. Therefore we must be careful in situations like this to backtrack.
 
+Trees that are only read from, as used for routing requests, are compressed so
+that a chain of nodes each with a single exact edge, like /api/v1/internal, is
+merged into one. Its fragments are then compared in turn instead of following
+an edge for each.
+
 */
 
 func newLookup() *treeLookup {
-	return &treeLookup{root: &node{children: map[string]*node{}, value: nil}}
+	return &treeLookup{
+		root:     &node{children: map[string]*node{}, value: nil},
+		matchers: map[string]MatcherFunc{},
+	}
 }
 
 type treeLookup struct {
 	root *node
+
+	// matchers are the named MatcherFuncs that can be used as constraints.
+	matchers map[string]MatcherFunc
+
+	// statics, if set, maps the paths of routes without parameters to their
+	// handler, so they can be found without walking the tree.
+	statics map[string]Handler
 }
 
 type node struct {
@@ -57,9 +90,10 @@ type node struct {
 	// children.
 	children map[string]*node
 
-	// wildedge is set if the path fragment was :something, the edge then contains
-	// the next node.
-	wildedge *wildedge
+	// wildedges are set if the path fragment was :something, each edge then
+	// contains the next node. Edges with constraints come before the
+	// unconstrained edge, if there is one, so that they are tried first.
+	wildedges []*wildedge
 
 	// greedyleaf contains a greedyleaf if the path fragment was *something, the
 	// leaf then contains the value.
@@ -67,6 +101,66 @@ type node struct {
 
 	// value contains the handler, if any.
 	value Handler
+
+	// skip is set when the node has been compressed, it lists the fragments
+	// that must follow the edge to the node before its own edges are taken.
+	skip []string
+
+	// keys and nodes hold the children instead of the map when the node has
+	// been packed, sorted by key.
+	keys  []string
+	nodes []*node
+}
+
+// maxPackedChildren is the most children a node can have to be packed. Up to
+// this many comparing each key is quicker than hashing the fragment.
+const maxPackedChildren = 8
+
+// pack moves the children of each node beneath the node, and the node itself,
+// with few enough children from the map into sorted slices. As with compress it
+// must only be used on a tree that is not added to afterwards.
+func (curr *node) pack() {
+	for _, child := range curr.children {
+		child.pack()
+	}
+	for _, edge := range curr.wildedges {
+		edge.child.pack()
+	}
+
+	if len(curr.children) > maxPackedChildren {
+		return
+	}
+
+	curr.keys = make([]string, 0, len(curr.children))
+	for key := range curr.children {
+		curr.keys = append(curr.keys, key)
+	}
+	sort.Strings(curr.keys)
+
+	curr.nodes = make([]*node, len(curr.keys))
+	for i, key := range curr.keys {
+		curr.nodes[i] = curr.children[key]
+	}
+	curr.children = nil
+}
+
+// child returns the child for the exact fragment, if there is one.
+func (curr *node) child(part string) (*node, bool) {
+	if curr.children != nil {
+		child, ok := curr.children[part]
+		return child, ok
+	}
+
+	for i, key := range curr.keys {
+		if key >= part {
+			if key == part {
+				return curr.nodes[i], true
+			}
+			break
+		}
+	}
+
+	return nil, false
 }
 
 type wildedge struct {
@@ -75,6 +169,38 @@ type wildedge struct {
 
 	// name of parameter
 	name string
+
+	// prefix and suffix are any fixed text around the parameter in the path
+	// fragment, as in v{version} or :name.json.
+	prefix, suffix string
+
+	// constraint is the source of the constraint on the parameter, or empty if
+	// it matches any value.
+	constraint string
+
+	// match returns the value of the parameter and whether the path fragment can
+	// be taken by the edge.
+	match MatcherFunc
+
+	// path is the path that added the edge.
+	path string
+}
+
+// unconstrained returns true if the edge can be taken by any path fragment.
+func (edge *wildedge) unconstrained() bool {
+	return edge.constraint == "" && edge.prefix == "" && edge.suffix == ""
+}
+
+// take returns the value of the parameter and whether the edge can be taken for
+// the path fragment.
+func (edge *wildedge) take(part string) (string, bool) {
+	if len(part) <= len(edge.prefix)+len(edge.suffix) ||
+		!strings.HasPrefix(part, edge.prefix) ||
+		!strings.HasSuffix(part, edge.suffix) {
+		return "", false
+	}
+
+	return edge.match(part[len(edge.prefix) : len(part)-len(edge.suffix)])
 }
 
 type greedyleaf struct {
@@ -83,54 +209,85 @@ type greedyleaf struct {
 
 	// name of parameter
 	name string
+
+	// path is the path that added the leaf.
+	path string
+}
+
+// A conflictError is panicked by Add when the path cannot be added as it
+// conflicts with a path that was added before.
+type conflictError struct {
+	reason   string
+	path     string
+	existing string
+
+	// source is where the existing path was registered, if known.
+	source string
+}
+
+func (err *conflictError) Error() string {
+	msg := err.reason + ": " + err.path + " conflicts with " + err.existing
+	if err.source != "" {
+		msg += " registered at " + err.source
+	}
+
+	return msg
+}
+
+// nextSegment returns the path fragment starting at index i of the path, and
+// the index of the fragment after it. Once the last fragment has been returned
+// the index is past the end of the path. Iterating over the path like this,
+// rather than splitting it, means finding a route doesn't allocate.
+func nextSegment(path string, i int) (string, int) {
+	end := strings.IndexByte(path[i:], '/')
+	if end < 0 {
+		return path[i:], len(path) + 1
+	}
+
+	return path[i : i+end], i + end + 1
 }
 
 func (look *treeLookup) Add(path string, handler Handler) {
+	if path == "" || path[0] != '/' {
+		panic("path must begin with '/': " + path)
+	}
 	if path != "/" && strings.HasSuffix(path, "/") {
 		panic("cannot insert path with trailing slash: " + path)
 	}
 
-	parts := strings.Split(path, "/")[1:]
-
-	look.root.add(parts, handler)
+	look.root.add(path, 1, handler, look.matchers)
 }
 
-func (curr *node) add(parts []string, handler Handler) {
-	part := parts[0]
-	parts = parts[1:]
+// add adds the handler for the fragments of path from index i beneath the node.
+func (curr *node) add(path string, i int, handler Handler, matchers map[string]MatcherFunc) {
+	part, i := nextSegment(path, i)
+	more := i <= len(path)
+
+	fresh := &node{children: map[string]*node{}, value: nil}
 
 	child, ok := curr.children[part]
 	if !ok {
-		child = &node{children: map[string]*node{}, value: nil}
-
-		if strings.HasPrefix(part, ":") {
-			// Check if we already have a wildedge, if so check it has same name, then
-			// move to its child. Otherwise create new wildedge
-			if curr.wildedge != nil {
-				if curr.wildedge.name != part[1:] {
-					panic("wildedge with different name already registered")
-				}
-				child = curr.wildedge.child
+		child = fresh
 
-			} else {
-				if part == ":" {
-					panic("parameter name is empty")
-				}
-				curr.wildedge = &wildedge{name: part[1:], child: child}
-			}
+		if seg, ok := parseSegment(part); ok {
+			child = curr.addWildedge(path, seg, child, matchers)
 
 		} else if strings.HasPrefix(part, "*") {
-			if len(parts) > 0 {
-				panic("path after greedy parameter")
+			if more {
+				panic("path after greedy parameter: " + path)
 			}
 			if part == "*" {
-				panic("greedy parameter name is empty")
+				panic("greedy parameter name is empty: " + path)
 			}
 			if curr.greedyleaf != nil {
-				panic("greedy parameter already registered")
+				panic(&conflictError{
+					reason:   "greedy parameter already registered",
+					path:     path,
+					existing: curr.greedyleaf.path,
+				})
 			}
 
-			curr.greedyleaf = &greedyleaf{name: part[1:], value: handler}
+			curr.greedyleaf = &greedyleaf{name: part[1:], value: handler, path: path}
 			return
 		} else {
 			curr.children[part] = child
@@ -138,8 +295,19 @@ func (curr *node) add(parts []string, handler Handler) {
 	}
 
 	// go deeper into the tree
-	if len(parts) > 0 {
-		child.add(parts, handler)
+	if more {
+		// If the child was created for this path, but the path can't be added
+		// beneath it, remove it again so the tree is left unchanged.
+		if child == fresh {
+			defer func() {
+				if v := recover(); v != nil {
+					curr.unlink(child)
+					panic(v)
+				}
+			}()
+		}
+
+		child.add(path, i, handler, matchers)
 		return
 	}
 
@@ -147,73 +315,624 @@ func (curr *node) add(parts []string, handler Handler) {
 	child.value = handler
 }
 
+// unlink removes the exact or wild edge leading to child.
+func (curr *node) unlink(child *node) {
+	for part, c := range curr.children {
+		if c == child {
+			delete(curr.children, part)
+			return
+		}
+	}
+
+	for i, edge := range curr.wildedges {
+		if edge.child == child {
+			curr.wildedges = append(curr.wildedges[:i], curr.wildedges[i+1:]...)
+			return
+		}
+	}
+}
+
+// addWildedge finds the wildedge for the parameter, or creates it with child at
+// its end, and returns the node at the end of the edge.
+func (curr *node) addWildedge(path string, seg segment, child *node, matchers map[string]MatcherFunc) *node {
+	if seg.name == "" {
+		panic("parameter name is empty: " + path)
+	}
+
+	// Check if we already have a wildedge with the same constraint, prefix and
+	// suffix, if so check it has same name, then move to its child. Otherwise
+	// create new wildedge
+	for _, edge := range curr.wildedges {
+		if edge.constraint == seg.constraint && edge.prefix == seg.prefix && edge.suffix == seg.suffix {
+			if edge.name != seg.name {
+				panic(&conflictError{
+					reason:   "wildedge with different name already registered",
+					path:     path,
+					existing: edge.path,
+				})
+			}
+			return edge.child
+		}
+	}
+
+	edge := &wildedge{
+		name:       seg.name,
+		prefix:     seg.prefix,
+		suffix:     seg.suffix,
+		constraint: seg.constraint,
+		match:      newConstraint(seg.constraint, matchers),
+		child:      child,
+		path:       path,
+	}
+
+	if edge.unconstrained() {
+		curr.wildedges = append(curr.wildedges, edge)
+	} else {
+		// insert before any unconstrained edge
+		i := len(curr.wildedges)
+		if i > 0 && curr.wildedges[i-1].unconstrained() {
+			i--
+		}
+		curr.wildedges = append(curr.wildedges[:i], append([]*wildedge{edge}, curr.wildedges[i:]...)...)
+	}
+
+	return child
+}
+
+// Remove removes the handler added for the path, and any nodes that are left
+// without handlers beneath them.
+func (look *treeLookup) Remove(path string) {
+	look.root.remove(path, 1)
+}
+
+func (curr *node) remove(path string, i int) {
+	part, i := nextSegment(path, i)
+	more := i <= len(path)
+
+	var child *node
+	if seg, ok := parseSegment(part); ok {
+		for _, edge := range curr.wildedges {
+			if edge.name == seg.name && edge.constraint == seg.constraint && edge.prefix == seg.prefix && edge.suffix == seg.suffix {
+				child = edge.child
+			}
+		}
+	} else if strings.HasPrefix(part, "*") {
+		if !more && curr.greedyleaf != nil && curr.greedyleaf.name == part[1:] {
+			curr.greedyleaf = nil
+		}
+		return
+	} else {
+		child = curr.children[part]
+	}
+
+	if child == nil {
+		return
+	}
+
+	if more {
+		child.remove(path, i)
+	} else {
+		child.value = nil
+	}
+
+	if child.empty() {
+		curr.unlink(child)
+	}
+}
+
+// clone returns a copy of the node and all of the nodes beneath it, with each
+// handler replaced by the result of copyValue. Packed children are copied back
+// into a map, so the copy can be added to.
+func (curr *node) clone(copyValue func(Handler) Handler) *node {
+	c := &node{children: make(map[string]*node, len(curr.children)+len(curr.keys))}
+	if curr.value != nil {
+		c.value = copyValue(curr.value)
+	}
+
+	for part, child := range curr.children {
+		c.children[part] = child.clone(copyValue)
+	}
+	for i, part := range curr.keys {
+		c.children[part] = curr.nodes[i].clone(copyValue)
+	}
+
+	for _, edge := range curr.wildedges {
+		copied := *edge
+		copied.child = edge.child.clone(copyValue)
+		c.wildedges = append(c.wildedges, &copied)
+	}
+
+	if len(curr.skip) > 0 {
+		c.skip = append([]string{}, curr.skip...)
+	}
+
+	if curr.greedyleaf != nil {
+		copied := *curr.greedyleaf
+		copied.value = copyValue(copied.value)
+		c.greedyleaf = &copied
+	}
+
+	return c
+}
+
+// compress merges each chain of nodes beneath the node that only lead on to a
+// single exact child into the last node of the chain, recording the fragments
+// passed over in its skip. So that /api/v1/internal/admin is then found by
+// following the edge for "api" and comparing the next three fragments, rather
+// than by following four edges. Nodes are changed in place, so compress must
+// only be used on a tree that is not added to afterwards.
+func (curr *node) compress() {
+	for part, child := range curr.children {
+		curr.children[part] = child.compressed()
+	}
+
+	for _, edge := range curr.wildedges {
+		edge.child = edge.child.compressed()
+	}
+}
+
+// compressed returns the node that the chain starting at curr can be merged
+// into, having compressed the nodes beneath.
+func (curr *node) compressed() *node {
+	for curr.value == nil && curr.greedyleaf == nil && len(curr.wildedges) == 0 && len(curr.children) == 1 {
+		for part, child := range curr.children {
+			skip := make([]string, 0, len(curr.skip)+1+len(child.skip))
+			skip = append(append(append(skip, curr.skip...), part), child.skip...)
+
+			child.skip = skip
+			curr = child
+		}
+	}
+
+	curr.compress()
+	return curr
+}
+
+// addStatics adds the handler of each node reached from curr by exact edges
+// alone to statics, keyed by its path. Nodes with a greedy leaf are left out,
+// as the leaf is preferred when there are no more fragments.
+func (curr *node) addStatics(path string, statics map[string]Handler) {
+	for part, child := range curr.children {
+		if child.value != nil && child.greedyleaf == nil {
+			statics[path+"/"+part] = child.value
+		}
+
+		child.addStatics(path+"/"+part, statics)
+	}
+}
+
+// empty returns true if the node has no handler, and no edges to other nodes.
+func (curr *node) empty() bool {
+	return curr.value == nil && len(curr.children) == 0 && len(curr.wildedges) == 0 && curr.greedyleaf == nil
+}
+
+// Validate checks that the tree is consistent, returning an error describing
+// each problem found. It is intended for tests of changes to the tree, so
+// that they can check every tree they build is one the tree functions expect.
+func (look *treeLookup) Validate() error {
+	errs := look.root.validate("", true)
+
+	for path, handler := range look.statics {
+		if handler == nil {
+			errs = append(errs, fmt.Errorf("route: static %s has no handler", path))
+		} else if look.root.get(path, 1, &Params{}, nil) == nil {
+			errs = append(errs, fmt.Errorf("route: static %s is not in the tree", path))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate returns the problems found with the node, reached by path, and the
+// nodes beneath it.
+func (curr *node) validate(path string, root bool) []error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		at := path
+		if at == "" {
+			at = "/"
+		}
+		errs = append(errs, fmt.Errorf("route: %s: "+format, append([]any{at}, args...)...))
+	}
+
+	for _, skip := range curr.skip {
+		if skip == "" {
+			fail("empty skipped fragment")
+		}
+		path += "/" + skip
+	}
+
+	if !root && curr.empty() && len(curr.keys) == 0 {
+		fail("node has no handler or edges")
+	}
+
+	if curr.children != nil && curr.keys != nil {
+		fail("node has both mapped and packed children")
+	}
+	if len(curr.keys) != len(curr.nodes) {
+		fail("node has %d packed keys but %d children", len(curr.keys), len(curr.nodes))
+	}
+	for i := 1; i < len(curr.keys); i++ {
+		if curr.keys[i-1] >= curr.keys[i] {
+			fail("packed keys %q and %q are not sorted", curr.keys[i-1], curr.keys[i])
+		}
+	}
+
+	children := map[string]*node{}
+	for part, child := range curr.children {
+		children[part] = child
+	}
+	for i, part := range curr.keys {
+		if i < len(curr.nodes) {
+			children[part] = curr.nodes[i]
+		}
+	}
+
+	for part, child := range children {
+		if _, ok := parseSegment(part); ok || strings.HasPrefix(part, "*") {
+			fail("parameter %q is an exact edge", part)
+		}
+		if child == nil {
+			fail("exact edge %q has no node", part)
+			continue
+		}
+		errs = append(errs, child.validate(path+"/"+part, false)...)
+	}
+
+	for i, edge := range curr.wildedges {
+		if edge.name == "" {
+			fail("wild edge %d has no name", i)
+		}
+		if edge.match == nil {
+			fail("wild edge :%s has no matcher", edge.name)
+		}
+		if edge.unconstrained() && i != len(curr.wildedges)-1 {
+			fail("unconstrained wild edge :%s is not last", edge.name)
+		}
+		for _, other := range curr.wildedges[:i] {
+			if other.constraint == edge.constraint && other.prefix == edge.prefix && other.suffix == edge.suffix {
+				fail("wild edges :%s and :%s take the same fragments", other.name, edge.name)
+			}
+		}
+		if edge.child == nil {
+			fail("wild edge :%s has no node", edge.name)
+			continue
+		}
+		errs = append(errs, edge.child.validate(path+"/"+edge.prefix+"{"+edge.name+"}"+edge.constraint+edge.suffix, false)...)
+	}
+
+	if leaf := curr.greedyleaf; leaf != nil {
+		if leaf.name == "" {
+			fail("greedy leaf has no name")
+		}
+		if leaf.value == nil {
+			fail("greedy leaf *%s has no handler", leaf.name)
+		}
+	}
+
+	return errs
+}
+
 func (look *treeLookup) Get(path string) (Handler, map[string]string) {
-	params := map[string]string{}
+	var ps Params
+	handler := look.GetParams(path, &ps)
+
+	return handler, ps.Map()
+}
+
+// GetParams finds the handler for the path as Get does, but appends the
+// parameters to ps instead of returning a map.
+func (look *treeLookup) GetParams(path string, ps *Params) Handler {
+	return look.GetRequest(path, ps, nil)
+}
 
+// GetRequest finds the handler for the path as GetParams does, but skips over
+// endpoints without a route for the request, so that a less specific path
+// whose routes accept the request's method and conditions is found instead. If
+// req is nil every handler is accepted.
+func (look *treeLookup) GetRequest(path string, ps *Params, req *http.Request) Handler {
 	if path != "/" && strings.HasSuffix(path, "/") {
 		path = path[:len(path)-1]
 	}
 
-	parts := strings.Split(path, "/")[1:]
+	if handler, ok := look.statics[path]; ok && accepts(handler, req) {
+		return handler
+	}
 
-	return look.root.get(parts, params)
+	return look.root.get(path, 1, ps, req)
 }
 
-func (curr *node) get(parts []string, pars map[string]string) (Handler, map[string]string) {
-	if len(parts) == 0 {
+// accepts returns true if the handler is found for the request, which is when
+// it is not an endpoint or the endpoint has a route for the request.
+func accepts(handler Handler, req *http.Request) bool {
+	if handler == nil {
+		return false
+	}
+	if ep, ok := handler.(*endpoint); ok && req != nil {
+		_, status := ep.match(req)
+		return status == 0
+	}
+
+	return true
+}
+
+// get finds the handler for the fragments of path from index i beneath the
+// node, skipping handlers that do not accept req. Parameter values are slices
+// of path, rather than copies, so that finding them doesn't allocate; only a
+// map made from them by Vars does.
+func (curr *node) get(path string, i int, ps *Params, req *http.Request) Handler {
+	for _, skip := range curr.skip {
+		if i > len(path) {
+			return nil
+		}
+
+		var part string
+		if part, i = nextSegment(path, i); part != skip {
+			return nil
+		}
+	}
+
+	if i > len(path) {
 		// If it has a greedyleaf we have an empty match
-		if curr.greedyleaf != nil {
-			pars[curr.greedyleaf.name] = ""
-			return curr.greedyleaf.value, pars
+		if curr.greedyleaf != nil && accepts(curr.greedyleaf.value, req) {
+			*ps = append(*ps, Param{Key: curr.greedyleaf.name})
+			return curr.greedyleaf.value
 		}
 
-		return curr.value, pars
+		if !accepts(curr.value, req) {
+			return nil
+		}
+		return curr.value
 	}
 
-	child, ok := curr.children[parts[0]]
-	if !ok {
-		if curr.wildedge != nil {
-			// If we have a parameter, add the parameter and make the child the node
-			// at the end of the edge.
-			pars[curr.wildedge.name] = parts[0]
-			child = curr.wildedge.child
+	part, next := nextSegment(path, i)
 
-		} else if curr.greedyleaf != nil {
-			// If we have a greedyleaf, add the parameter and return the handler.
-			pars[curr.greedyleaf.name] = strings.Join(parts, "/")
-			return curr.greedyleaf.value, pars
+	// Exact matches are tried first, then parameters in order, going deeper into
+	// the tree for each and backtracking if there was no handler further on.
+	if child, ok := curr.child(part); ok {
+		if handler := child.get(path, next, ps, req); handler != nil {
+			return handler
+		}
+	}
 
-		} else {
-			// If no matches, return the nil value and params so far.
-			return nil, pars
+	n := len(*ps)
+	for _, edge := range curr.wildedges {
+		value, ok := edge.take(part)
+		if !ok {
+			continue
 		}
+
+		*ps = append(*ps, Param{Key: edge.name, Value: value})
+		if handler := edge.child.get(path, next, ps, req); handler != nil {
+			return handler
+		}
+
+		// If we added a parameter at this depth, but there was no handler further
+		// on, remove it.
+		*ps = (*ps)[:n]
+	}
+
+	// If we had no match deeper in the tree, try to match a greedyleaf.
+	if curr.greedyleaf != nil && accepts(curr.greedyleaf.value, req) {
+		*ps = append(*ps, Param{Key: curr.greedyleaf.name, Value: path[i:]})
+		return curr.greedyleaf.value
 	}
 
-	// Go deeper into the tree
-	handler, pars := child.get(parts[1:], pars)
+	// If no matches, return the nil value.
+	return nil
+}
 
-	if handler == nil && curr.wildedge != nil {
-		if child == curr.wildedge.child {
-			// If we added a parameter at this depth, but there was no handler further on,
-			// remove it.
-			delete(pars, curr.wildedge.name)
+// FixCase appends to found the paths of the routes that match path when the
+// case of their fixed fragments is ignored, written with the case they were
+// registered with. It stops once found holds more than one path.
+func (look *treeLookup) FixCase(path string, found []string) []string {
+	if path != "/" && strings.HasSuffix(path, "/") {
+		path = path[:len(path)-1]
+	}
 
-		} else {
-			// If we didn't take the wildedge last time, do now
-			pars[curr.wildedge.name] = parts[0]
-			child = curr.wildedge.child
+	return look.root.fixCase(path, 1, "", found)
+}
 
-			handler, pars = child.get(parts[1:], pars)
+// fixCase appends to found the paths matching the fragments of path from index
+// i beneath the node ignoring case, where fixed is the path of the node.
+func (curr *node) fixCase(path string, i int, fixed string, found []string) []string {
+	for _, skip := range curr.skip {
+		if i > len(path) {
+			return found
 		}
+
+		var part string
+		if part, i = nextSegment(path, i); !strings.EqualFold(part, skip) {
+			return found
+		}
+		fixed += "/" + skip
 	}
 
-	// If we had no match deeper in the tree, try to match a greedyleaf.
-	if handler == nil && curr.greedyleaf != nil {
-		pars[curr.greedyleaf.name] = strings.Join(parts, "/")
-		return curr.greedyleaf.value, pars
+	if i > len(path) {
+		if curr.value == nil && curr.greedyleaf == nil {
+			return found
+		}
+		if fixed == "" {
+			fixed = "/"
+		}
+		return appendPath(found, fixed)
+	}
+
+	part, next := nextSegment(path, i)
+
+	for key, child := range curr.children {
+		if len(found) < 2 && strings.EqualFold(key, part) {
+			found = child.fixCase(path, next, fixed+"/"+key, found)
+		}
+	}
+	for j, key := range curr.keys {
+		if len(found) < 2 && strings.EqualFold(key, part) {
+			found = curr.nodes[j].fixCase(path, next, fixed+"/"+key, found)
+		}
+	}
+
+	for _, edge := range curr.wildedges {
+		if _, ok := edge.take(part); ok && len(found) < 2 {
+			found = edge.child.fixCase(path, next, fixed+"/"+part, found)
+		}
+	}
+
+	if curr.greedyleaf != nil && len(found) < 2 {
+		found = appendPath(found, fixed+"/"+path[i:])
+	}
+
+	return found
+}
+
+// appendPath appends the path to paths, if it is not already in it.
+func appendPath(paths []string, path string) []string {
+	for _, p := range paths {
+		if p == path {
+			return paths
+		}
+	}
+
+	return append(paths, path)
+}
+
+// segment is a path fragment containing a named parameter.
+type segment struct {
+	prefix, name, constraint, suffix string
+}
+
+// paramDelimiters are the characters that a ':' must follow to begin a
+// parameter part way through a path fragment. Elsewhere a ':' is matched as is,
+// so that fragments such as "things:batchGet" are fixed.
+const paramDelimiters = ".-~@+,"
+
+// parseSegment parses a path fragment containing a named parameter, returning
+// false if it does not contain one. The parameter is written either as ':'
+// then its name, where the ':' begins the fragment or follows one of
+// paramDelimiters, or as its name in braces anywhere in the fragment. The name
+// is made of letters, digits and underscores, and is followed by an optional
+// constraint in brackets. For example "v{id}(\d+).json" has prefix "v", name
+// "id", constraint "(\d+)" and suffix ".json".
+//
+// The constraint is either "(regexp)", "<matcher>" or "{a,b,c}".
+func parseSegment(part string) (segment, bool) {
+	if strings.HasPrefix(part, "*") {
+		return segment{}, false
+	}
+
+	i := paramStart(part)
+	if i < 0 {
+		return segment{}, false
+	}
+
+	seg := segment{prefix: part[:i]}
+	rest := part[i+1:]
+
+	if part[i] == '{' {
+		j := strings.IndexByte(rest, '}')
+		seg.name, rest = rest[:j], rest[j+1:]
+	} else {
+		j := 0
+		for j < len(rest) && isNameByte(rest[j]) {
+			j++
+		}
+		seg.name, rest = rest[:j], rest[j:]
 	}
 
-	return handler, pars
+	if rest != "" {
+		if closer := strings.IndexByte("()<>{}", rest[0]); closer >= 0 && closer%2 == 0 {
+			k := matchingBracket(rest, rest[0], "()<>{}"[closer+1])
+			if k < 0 {
+				panic("parameter constraint is not terminated: " + part)
+			}
+			seg.constraint, rest = rest[:k+1], rest[k+1:]
+		}
+	}
+
+	seg.suffix = rest
+	return seg, true
+}
+
+// paramStart returns the index of the ':' or '{' beginning the parameter in the
+// path fragment, or -1 if it does not contain one.
+func paramStart(part string) int {
+	for i := 0; i < len(part); i++ {
+		switch part[i] {
+		case ':':
+			if i == 0 || (strings.IndexByte(paramDelimiters, part[i-1]) >= 0 && i+1 < len(part) && isNameByte(part[i+1])) {
+				return i
+			}
+		case '{':
+			if j := strings.IndexByte(part[i:], '}'); j > 1 && isName(part[i+1:i+j]) {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+func isNameByte(c byte) bool {
+	return c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// matchingBracket returns the index of the close bracket matching the open
+// bracket at the start of s, or -1 if there is not one.
+func matchingBracket(s string, open, close byte) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// newConstraint returns a MatcherFunc checking values of a parameter satisfy
+// the constraint. A constraint of the form "(regexp)" must match the whole
+// value, one of the form "<name>" uses the named matcher, and one of the form
+// "{a,b,c}" must be equal to one of the listed values.
+func newConstraint(constraint string, matchers map[string]MatcherFunc) MatcherFunc {
+	if constraint == "" {
+		return func(segment string) (string, bool) { return segment, true }
+	}
+
+	switch constraint[0] {
+	case '{':
+		values := map[string]bool{}
+		for _, value := range strings.Split(constraint[1:len(constraint)-1], ",") {
+			values[value] = true
+		}
+
+		return func(segment string) (string, bool) {
+			return segment, values[segment]
+		}
+
+	case '<':
+		name := constraint[1 : len(constraint)-1]
+		matcher, ok := matchers[name]
+		if !ok {
+			panic("no matcher registered with name: " + name)
+		}
+		return matcher
+	}
+
+	re, err := regexp.Compile("^(?:" + constraint[1:len(constraint)-1] + ")$")
+	if err != nil {
+		panic("parameter constraint is invalid: " + err.Error())
+	}
+
+	return func(segment string) (string, bool) {
+		return segment, re.MatchString(segment)
+	}
 }
 
 // Taken from net/http
@@ -2,6 +2,8 @@ package route
 
 import (
 	"net/http"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -83,18 +85,24 @@ func TestLookupRegisterGreedyParameterWithSameName(t *testing.T) {
 	lookup := newLookup()
 
 	lookup.Add("/file/*path", registeredHandler{"yay"})
-	checkPanics(t, func() {
+	recv := checkPanics(t, func() {
 		lookup.Add("/file/*path", registeredHandler{""})
 	})
+
+	assert.Equal(t, "greedy parameter already registered: /file/*path conflicts with /file/*path",
+		recv.(error).Error())
 }
 
 func TestLookupRegisterNamedParameterWithDifferentNames(t *testing.T) {
 	lookup := newLookup()
 
-	lookup.Add("/file/:path", registeredHandler{"yay"})
-	checkPanics(t, func() {
+	lookup.Add("/file/:path/edit", registeredHandler{"yay"})
+	recv := checkPanics(t, func() {
 		lookup.Add("/file/:notpath", registeredHandler{""})
 	})
+
+	assert.Equal(t, "wildedge with different name already registered: /file/:notpath conflicts with /file/:path/edit",
+		recv.(error).Error())
 }
 
 func TestLookupRegisterNamedParameterWithEmptyName(t *testing.T) {
@@ -204,6 +212,175 @@ func TestLookupPriorities(t *testing.T) {
 	checkExpectations(t, lookup, expectations)
 }
 
+func TestLookupConstrainedParameter(t *testing.T) {
+	lookup := newLookup()
+
+	handlers := registerRoutes(lookup, []string{
+		"/posts/:id([0-9]+)",
+		"/posts/:slug",
+		"/posts/:id([0-9]+)/comments",
+		"/posts/:year(20[0-9]{2})/archive",
+		"/posts/:slug/comments",
+	})
+
+	expectations := []lookupExpectation{
+		{"/posts/123", handlers["/posts/:id([0-9]+)"], map[string]string{"id": "123"}},
+		{"/posts/hello", handlers["/posts/:slug"], map[string]string{"slug": "hello"}},
+		{"/posts/12a", handlers["/posts/:slug"], map[string]string{"slug": "12a"}},
+		{"/posts/123/comments", handlers["/posts/:id([0-9]+)/comments"], map[string]string{"id": "123"}},
+		{"/posts/hello/comments", handlers["/posts/:slug/comments"], map[string]string{"slug": "hello"}},
+		{"/posts/2019/archive", handlers["/posts/:year(20[0-9]{2})/archive"], map[string]string{"year": "2019"}},
+		{"/posts/1999/archive", nil, map[string]string{}},
+	}
+
+	checkExpectations(t, lookup, expectations)
+}
+
+func TestLookupConstrainedParameterWithoutFallback(t *testing.T) {
+	lookup := newLookup()
+
+	handlers := registerRoutes(lookup, []string{"/posts/:id([0-9]+)"})
+
+	checkExpectations(t, lookup, []lookupExpectation{
+		{"/posts/123", handlers["/posts/:id([0-9]+)"], map[string]string{"id": "123"}},
+		{"/posts/hello", nil, map[string]string{}},
+	})
+}
+
+func TestLookupEnumParameter(t *testing.T) {
+	lookup := newLookup()
+
+	handlers := registerRoutes(lookup, []string{
+		"/reports/:period{daily,weekly,monthly}",
+		"/reports/:id",
+	})
+
+	checkExpectations(t, lookup, []lookupExpectation{
+		{"/reports/daily", handlers["/reports/:period{daily,weekly,monthly}"], map[string]string{"period": "daily"}},
+		{"/reports/monthly", handlers["/reports/:period{daily,weekly,monthly}"], map[string]string{"period": "monthly"}},
+		{"/reports/yearly", handlers["/reports/:id"], map[string]string{"id": "yearly"}},
+		{"/reports/dail", handlers["/reports/:id"], map[string]string{"id": "dail"}},
+	})
+}
+
+func TestLookupPartialSegmentParameter(t *testing.T) {
+	lookup := newLookup()
+
+	handlers := registerRoutes(lookup, []string{
+		"/files/:name.json",
+		"/files/:name.tar.gz",
+		"/files/:name",
+		"/v{version}([0-9]+)-beta/users",
+		"/v{version}/users",
+	})
+
+	checkExpectations(t, lookup, []lookupExpectation{
+		{"/files/report.json", handlers["/files/:name.json"], map[string]string{"name": "report"}},
+		{"/files/backup.tar.gz", handlers["/files/:name.tar.gz"], map[string]string{"name": "backup"}},
+		{"/files/report.csv", handlers["/files/:name"], map[string]string{"name": "report.csv"}},
+		{"/files/.json", handlers["/files/:name"], map[string]string{"name": ".json"}},
+		{"/v2/users", handlers["/v{version}/users"], map[string]string{"version": "2"}},
+		{"/v2-beta/users", handlers["/v{version}([0-9]+)-beta/users"], map[string]string{"version": "2"}},
+		{"/vx-beta/users", handlers["/v{version}/users"], map[string]string{"version": "x-beta"}},
+		{"/v/users", nil, map[string]string{}},
+	})
+}
+
+func TestLookupStaticSegmentWithColon(t *testing.T) {
+	lookup := newLookup()
+
+	handlers := registerRoutes(lookup, []string{
+		"/v1/things:batchGet",
+		"/a/b:",
+		"/files/:name:info",
+	})
+
+	checkExpectations(t, lookup, []lookupExpectation{
+		{"/v1/things:batchGet", handlers["/v1/things:batchGet"], map[string]string{}},
+		{"/v1/thingsXYZ", nil, map[string]string{}},
+		{"/v1/things:other", nil, map[string]string{}},
+		{"/a/b:", handlers["/a/b:"], map[string]string{}},
+		{"/a/b", nil, map[string]string{}},
+		{"/files/report:info", handlers["/files/:name:info"], map[string]string{"name": "report"}},
+	})
+}
+
+func TestParseSegment(t *testing.T) {
+	cases := map[string]segment{
+		":name":            {name: "name"},
+		":id(\\d+)":        {name: "id", constraint: "(\\d+)"},
+		":id((a|b)c).json": {name: "id", constraint: "((a|b)c)", suffix: ".json"},
+		":p{a,b}":          {name: "p", constraint: "{a,b}"},
+		":id<hex>":         {name: "id", constraint: "<hex>"},
+		"v{version}":       {prefix: "v", name: "version"},
+		"v{id}(\\d+).json": {prefix: "v", name: "id", constraint: "(\\d+)", suffix: ".json"},
+		"{id}":             {name: "id"},
+		"img-:size-x.png":  {prefix: "img-", name: "size", suffix: "-x.png"},
+		"img-:size(\\))px": {prefix: "img-", name: "size", constraint: "(\\))", suffix: "px"},
+	}
+
+	for part, expected := range cases {
+		seg, ok := parseSegment(part)
+		assert.True(t, ok)
+		assert.Equal(t, expected, seg)
+	}
+
+	for _, part := range []string{"exact", "things:batchGet", "b:", "a-:", "x{}", "x{a-b}"} {
+		_, ok := parseSegment(part)
+		assert.False(t, ok, part)
+	}
+
+	checkPanics(t, func() {
+		parseSegment(":id(\\d+")
+	})
+}
+
+func TestLookupRegisterConstrainedParameterWithDifferentNames(t *testing.T) {
+	lookup := newLookup()
+
+	lookup.Add("/file/:id([0-9]+)", registeredHandler{"yay"})
+	lookup.Add("/file/:name", registeredHandler{"yay"})
+	checkPanics(t, func() {
+		lookup.Add("/file/:num([0-9]+)", registeredHandler{""})
+	})
+}
+
+func TestLookupRegisterInvalidConstraint(t *testing.T) {
+	lookup := newLookup()
+
+	checkPanics(t, func() {
+		lookup.Add("/file/:id([0-9+)", registeredHandler{""})
+	})
+}
+
+func TestLookupMatcherParameter(t *testing.T) {
+	lookup := newLookup()
+	lookup.matchers["lower"] = func(segment string) (string, bool) {
+		return strings.ToLower(segment), true
+	}
+	lookup.matchers["short"] = func(segment string) (string, bool) {
+		return segment, len(segment) <= 3
+	}
+
+	handlers := registerRoutes(lookup, []string{
+		"/code/:code<short>",
+		"/code/:name<lower>",
+	})
+
+	checkExpectations(t, lookup, []lookupExpectation{
+		{"/code/ABC", handlers["/code/:code<short>"], map[string]string{"code": "ABC"}},
+		{"/code/ABCD", handlers["/code/:name<lower>"], map[string]string{"name": "abcd"}},
+	})
+}
+
+func TestLookupRegisterUnknownMatcher(t *testing.T) {
+	lookup := newLookup()
+
+	checkPanics(t, func() {
+		lookup.Add("/file/:id<what>", registeredHandler{""})
+	})
+}
+
 type route struct {
 	method, path string
 }
@@ -366,3 +543,316 @@ func (m *mockResponseWriter) WriteString(s string) (n int, err error) {
 }
 
 func (m *mockResponseWriter) WriteHeader(int) {}
+
+func TestLookupRemove(t *testing.T) {
+	lookup := newLookup()
+
+	handlers := registerRoutes(lookup, []string{
+		"/",
+		"/user/:name",
+		"/user/:name/edit",
+		"/files/*path",
+		"/posts/:id(\\d+)",
+	})
+
+	lookup.Remove("/user/:name")
+	lookup.Remove("/files/*path")
+	lookup.Remove("/posts/:id(\\d+)")
+	lookup.Remove("/missing/path")
+
+	checkExpectations(t, lookup, []lookupExpectation{
+		{"/", handlers["/"], map[string]string{}},
+		{"/user/john", nil, map[string]string{}},
+		{"/user/john/edit", handlers["/user/:name/edit"], map[string]string{"name": "john"}},
+		{"/files/a.txt", nil, map[string]string{}},
+		{"/posts/12", nil, map[string]string{}},
+	})
+
+	// empty nodes are pruned, so different parameter names can now be used
+	assert.Nil(t, lookup.root.children["posts"])
+	register(lookup, "/files/:name")
+
+	lookup.Remove("/user/:name/edit")
+	assert.Nil(t, lookup.root.children["user"])
+
+	lookup.Remove("/")
+	lookup.Remove("/files/:name")
+	assert.True(t, lookup.root.empty())
+}
+
+func TestLookupCompressed(t *testing.T) {
+	lookup := newLookup()
+
+	registerRoutes(lookup, []string{
+		"/",
+		"/api/v1/internal/admin/users",
+		"/api/v1/internal/admin/users/:id",
+		"/api/v1/internal/status",
+		"/api/v1/:resource/list",
+		"/files/static/*path",
+		"/people/:id/activities/collection/public",
+	})
+
+	compressed := lookup.clone()
+
+	api, _ := compressed.root.child("api")
+	internal, _ := api.child("internal")
+	admin, _ := internal.child("admin")
+	people, _ := compressed.root.child("people")
+
+	assert.Equal(t, []string{"v1"}, api.skip)
+	assert.Equal(t, []string{"users"}, admin.skip)
+	assert.Equal(t, []string{"activities", "collection", "public"}, people.wildedges[0].child.skip)
+
+	paths := []string{
+		"/",
+		"/api",
+		"/api/v1",
+		"/api/v1/internal",
+		"/api/v1/internal/admin",
+		"/api/v1/internal/admin/users",
+		"/api/v1/internal/admin/users/5",
+		"/api/v1/internal/status",
+		"/api/v1/internal/list",
+		"/api/v1/other/list",
+		"/api/v2/internal/status",
+		"/files/static",
+		"/files/static/a/b.txt",
+		"/files/other/a.txt",
+		"/people/5/activities/collection/public",
+		"/people/5/activities/collection",
+	}
+	for _, path := range paths {
+		expectedHandler, expectedParams := lookup.Get(path)
+		handler, params := compressed.Get(path)
+
+		assert.Equal(t, expectedHandler, handler, path)
+		assert.Equal(t, expectedParams, params, path)
+	}
+}
+
+func TestLookupStatics(t *testing.T) {
+	lookup := newLookup()
+
+	handlers := registerRoutes(lookup, []string{
+		"/",
+		"/about",
+		"/user/:name",
+		"/user/:name/edit",
+		"/files",
+		"/files/*path",
+		"/docs/intro",
+	})
+
+	cloned := lookup.clone()
+
+	assert.Equal(t, map[string]Handler{
+		"/":           handlers["/"].(Handler),
+		"/about":      handlers["/about"].(Handler),
+		"/docs/intro": handlers["/docs/intro"].(Handler),
+	}, cloned.statics)
+
+	checkExpectations(t, cloned, []lookupExpectation{
+		{"/", handlers["/"], map[string]string{}},
+		{"/about/", handlers["/about"], map[string]string{}},
+		{"/docs/intro", handlers["/docs/intro"], map[string]string{}},
+		{"/files", handlers["/files/*path"], map[string]string{"path": ""}},
+		{"/user/john/edit", handlers["/user/:name/edit"], map[string]string{"name": "john"}},
+	})
+}
+
+func TestNextSegment(t *testing.T) {
+	cases := map[string][]string{
+		"/":         {""},
+		"/a":        {"a"},
+		"/a/b":      {"a", "b"},
+		"/a//b":     {"a", "", "b"},
+		"/a/b/":     {"a", "b", ""},
+		"/:name/*x": {":name", "*x"},
+	}
+
+	for path, expected := range cases {
+		var parts []string
+		for i := 1; i <= len(path); {
+			var part string
+			part, i = nextSegment(path, i)
+			parts = append(parts, part)
+		}
+
+		assert.Equal(t, expected, parts, path)
+	}
+}
+
+func TestLookupPacked(t *testing.T) {
+	lookup := newLookup()
+
+	var routes []string
+	for i := 0; i < maxPackedChildren+1; i++ {
+		routes = append(routes, "/many/"+string(rune('a'+i)))
+	}
+	routes = append(routes, "/few/b", "/few/a", "/few/c/:id")
+	handlers := registerRoutes(lookup, routes)
+
+	packed := lookup.clone()
+
+	many, _ := packed.root.child("many")
+	assert.NotNil(t, many.children)
+	assert.Nil(t, many.keys)
+
+	few, _ := packed.root.child("few")
+	assert.Nil(t, few.children)
+	assert.Equal(t, []string{"a", "b", "c"}, few.keys)
+
+	checkExpectations(t, packed, []lookupExpectation{
+		{"/many/c", handlers["/many/c"], map[string]string{}},
+		{"/few/a", handlers["/few/a"], map[string]string{}},
+		{"/few/c/1", handlers["/few/c/:id"], map[string]string{"id": "1"}},
+		{"/few/d", nil, map[string]string{}},
+		{"/few/0", nil, map[string]string{}},
+	})
+
+	// packed trees can be cloned again
+	checkExpectations(t, packed.clone(), []lookupExpectation{
+		{"/few/c/1", handlers["/few/c/:id"], map[string]string{"id": "1"}},
+	})
+}
+
+func TestLookupFixCase(t *testing.T) {
+	lookup := newLookup()
+
+	registerRoutes(lookup, []string{
+		"/",
+		"/about",
+		"/api/v1/Users/:name",
+		"/files/*path",
+		"/team/alice",
+		"/Team/alice",
+	})
+
+	for _, tree := range []*treeLookup{lookup, lookup.clone()} {
+		cases := map[string][]string{
+			"/ABOUT":             {"/about"},
+			"/about/":            {"/about"},
+			"/API/V1/users/John": {"/api/v1/Users/John"},
+			"/Files/A/b":         {"/files/A/b"},
+			"/TEAM/alice":        {"/team/alice", "/Team/alice"},
+			"/contact":           nil,
+		}
+
+		for path, expected := range cases {
+			assert.ElementsMatch(t, expected, tree.FixCase(path, nil), path)
+		}
+	}
+}
+
+func TestLookupValidate(t *testing.T) {
+	lookup := newLookup()
+	registerRoutes(lookup, []string{
+		"/",
+		"/api/v1/users",
+		"/api/v1/users/:id(\\d+)",
+		"/api/v1/users/:name",
+		"/files/*path",
+		"/v{version}/report.:format{csv,json}",
+	})
+
+	assert.Nil(t, lookup.Validate())
+	assert.Nil(t, lookup.clone().Validate())
+
+	lookup.Remove("/api/v1/users/:id(\\d+)")
+	assert.Nil(t, lookup.Validate())
+
+	lookup.root.children["empty"] = &node{children: map[string]*node{}}
+	lookup.root.children[":id"] = &node{children: map[string]*node{}, value: registeredHandler{}}
+	err := lookup.Validate()
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "route: /empty: node has no handler or edges")
+		assert.Contains(t, err.Error(), `route: /: parameter ":id" is an exact edge`)
+	}
+}
+
+// fuzzRoutes are the routes of the tree used by FuzzLookupGet.
+var fuzzRoutes = []string{
+	"/",
+	"/users",
+	"/users/:id(\\d+)",
+	"/users/:name",
+	"/users/:name/posts/:post<int>",
+	"/files/*path",
+	"/v{version}/reports/:period{daily,weekly}.json",
+	"/static/css/site.css",
+}
+
+func FuzzLookupGet(f *testing.F) {
+	for _, route := range fuzzRoutes {
+		f.Add(route)
+	}
+	f.Add("/users/5/posts/x")
+	f.Add("//users//")
+	f.Add("")
+
+	lookup := newLookup()
+	lookup.matchers["int"] = func(segment string) (string, bool) {
+		_, err := strconv.Atoi(segment)
+		return segment, err == nil
+	}
+	registerRoutes(lookup, fuzzRoutes)
+	compressed := lookup.clone()
+
+	if err := compressed.Validate(); err != nil {
+		f.Fatal(err)
+	}
+
+	f.Fuzz(func(t *testing.T, path string) {
+		handler, params := lookup.Get(path)
+		compressedHandler, compressedParams := compressed.Get(path)
+
+		assert.Equal(t, handler, compressedHandler)
+		assert.Equal(t, params, compressedParams)
+	})
+}
+
+func FuzzLookupAddGet(f *testing.F) {
+	f.Add("users", "5")
+	f.Add("a.b", "%2F")
+	f.Add("x", "..")
+
+	f.Fuzz(func(t *testing.T, static, value string) {
+		if static == "" || static == "files" || strings.ContainsAny(static, "/:*") {
+			t.Skip()
+		}
+		if value == "" || strings.Contains(value, "/") {
+			t.Skip()
+		}
+
+		lookup := newLookup()
+		handlers := registerRoutes(lookup, []string{
+			"/" + static,
+			"/" + static + "/:id",
+			"/" + static + "/:id/" + static,
+			"/files/*path",
+		})
+
+		for _, tree := range []*treeLookup{lookup, lookup.clone()} {
+			if err := tree.Validate(); err != nil {
+				t.Fatal(err)
+			}
+
+			found, params := tree.Get("/" + static)
+			assert.Equal(t, handlers["/"+static], found)
+			assert.Equal(t, map[string]string{}, params)
+
+			found, params = tree.Get("/" + static + "/" + value)
+			assert.Equal(t, handlers["/"+static+"/:id"], found)
+			assert.Equal(t, map[string]string{"id": value}, params)
+
+			found, params = tree.Get("/" + static + "/" + value + "/" + static)
+			assert.Equal(t, handlers["/"+static+"/:id/"+static], found)
+			assert.Equal(t, map[string]string{"id": value}, params)
+
+			found, params = tree.Get("/files/" + value + "/" + static)
+			assert.Equal(t, handlers["/files/*path"], found)
+			assert.Equal(t, map[string]string{"path": value + "/" + static}, params)
+		}
+	})
+}
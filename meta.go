@@ -0,0 +1,47 @@
+package route
+
+import "net/http"
+
+// Tag attaches metadata to the route, which can be read by the handler, or any
+// middleware wrapping it, with Meta and is listed by Walk and Routes. It can be
+// used to describe routes to cross-cutting middleware:
+//
+//   api := router.Group("/api", route.Tag("owner", "payments"))
+//   api.Handle("/refunds", requireAuth(refundsHandler), route.Tag("auth", "admin"))
+//
+//   func requireAuth(next http.Handler) http.Handler {
+//     return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+//       role, _ := route.MetaValue[string](r, "auth")
+//       ...
+//     })
+//   }
+//
+// The value may be of any type, a struct can be used to group related values.
+// Tagging a route again with the same key replaces the value.
+func Tag(key string, value any) Option {
+	return func(e *entry) {
+		if e.meta == nil {
+			e.meta = map[string]any{}
+		}
+		e.meta[key] = value
+	}
+}
+
+// Meta returns the metadata attached with Tag to the route matched by the
+// request, or nil if it has none. The map is shared by all requests for the
+// route so must not be changed.
+func Meta(r *http.Request) map[string]any {
+	if m := getMatch(r); m != nil {
+		return m.meta
+	}
+
+	return nil
+}
+
+// MetaValue returns the value attached with Tag for the key to the route
+// matched by the request. It returns false if the route has no value for the
+// key, or if the value is not a T.
+func MetaValue[T any](r *http.Request, key string) (T, bool) {
+	v, ok := Meta(r)[key].(T)
+	return v, ok
+}
@@ -0,0 +1,59 @@
+package route
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTag(t *testing.T) {
+	type limit struct{ PerMinute int }
+
+	var meta map[string]any
+	var pattern string
+	var ok bool
+	var rate limit
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		meta = Meta(r)
+		pattern = Pattern(r)
+		rate, ok = MetaValue[limit](r, "rate")
+	}
+
+	router := New()
+	api := router.Group("/api", Tag("owner", "payments"))
+	api.HandleFunc("/refunds", handler, Tag("auth", "admin"), Tag("rate", limit{10}))
+	api.HandleFunc("/refunds/:id", handler, Tag("auth", "user"))
+	router.HandleFunc("/plain", handler)
+
+	r := httptest.NewRequest("GET", "/api/refunds", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+
+	assert.Equal(t, map[string]any{"owner": "payments", "auth": "admin", "rate": limit{10}}, meta)
+	assert.Equal(t, "/api/refunds", pattern)
+	assert.True(t, ok)
+	assert.Equal(t, limit{10}, rate)
+
+	r = httptest.NewRequest("GET", "/api/refunds/5", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+
+	assert.Equal(t, map[string]any{"owner": "payments", "auth": "user"}, meta)
+	assert.False(t, ok)
+
+	r = httptest.NewRequest("GET", "/plain", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+
+	assert.Nil(t, meta)
+
+	m, err := router.MatchRequest(httptest.NewRequest("GET", "/api/refunds", nil))
+	assert.Nil(t, err)
+	assert.Equal(t, "admin", m.Meta["auth"])
+
+	for _, info := range router.Routes() {
+		if info.Pattern == "/api/refunds/:id" {
+			assert.Equal(t, map[string]any{"owner": "payments", "auth": "user"}, info.Meta)
+		}
+	}
+}
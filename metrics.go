@@ -0,0 +1,222 @@
+package route
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultDurationBuckets are the upper bounds, in seconds, of the buckets of the
+// request duration histogram.
+var DefaultDurationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// DefaultSizeBuckets are the upper bounds, in bytes, of the buckets of the
+// response size histogram.
+var DefaultSizeBuckets = []float64{100, 1000, 10000, 100000, 1e6, 1e7, 1e8}
+
+// Metrics records the number, duration and response size of requests, labelled
+// by method, matched pattern and status, and serves them in the Prometheus text
+// format. Labelling by pattern rather than path keeps the number of series
+// bounded however many distinct URLs are requested:
+//
+//   metrics := route.NewMetrics()
+//   router.Handle("GET /metrics", metrics)
+//
+//   http.ListenAndServe(":8080", metrics.Instrument(router))
+//
+// Which serves:
+//
+//   http_requests_total{method="GET",pattern="/users/:id",status="200"} 12
+//   http_request_duration_seconds_bucket{method="GET",pattern="/users/:id",status="200",le="0.005"} 9
+//   ...
+//
+// Requests that match no route are labelled with an empty pattern, and those
+// using a method other than the standard methods with the method "OTHER".
+type Metrics struct {
+	// DurationBuckets and SizeBuckets are the buckets of the histograms, they
+	// must not be changed once requests have been recorded. If nil, as for the
+	// zero value, histograms only have the "+Inf" bucket, so NewMetrics should
+	// be used for the default buckets.
+	DurationBuckets []float64
+	SizeBuckets     []float64
+
+	mu     sync.Mutex
+	series map[metricLabels]*metricSeries
+}
+
+// metricLabels identify a series of metrics.
+type metricLabels struct {
+	method, pattern, status string
+}
+
+// metricSeries are the metrics recorded for a set of labels.
+type metricSeries struct {
+	count    uint64
+	duration histogram
+	size     histogram
+}
+
+// histogram counts observations by bucket.
+type histogram struct {
+	counts []uint64
+	sum    float64
+}
+
+func (h *histogram) observe(buckets []float64, v float64) {
+	if h.counts == nil {
+		h.counts = make([]uint64, len(buckets))
+	}
+	for i, bound := range buckets {
+		if v <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+}
+
+// NewMetrics returns Metrics using the default buckets.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		DurationBuckets: DefaultDurationBuckets,
+		SizeBuckets:     DefaultSizeBuckets,
+		series:          map[metricLabels]*metricSeries{},
+	}
+}
+
+// Instrument wraps the handler, which should be a Router or contain one, so that
+// the requests it serves are recorded.
+func (m *Metrics) Instrument(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		r, o := observe(r, false)
+		rec := &responseRecorder{ResponseWriter: w}
+
+		handler.ServeHTTP(rec, r)
+
+		m.record(metricLabels{
+			method:  metricMethod(r.Method),
+			pattern: o.pattern,
+			status:  strconv.Itoa(rec.code()),
+		}, time.Since(start), rec.size)
+	})
+}
+
+// standardMethods are the methods used as labels, others are recorded as
+// "OTHER".
+var standardMethods = map[string]bool{
+	"GET": true, "HEAD": true, "POST": true, "PUT": true, "PATCH": true,
+	"DELETE": true, "CONNECT": true, "OPTIONS": true, "TRACE": true,
+}
+
+func metricMethod(method string) string {
+	if standardMethods[method] {
+		return method
+	}
+	return "OTHER"
+}
+
+func (m *Metrics) record(labels metricLabels, duration time.Duration, size int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.series == nil {
+		m.series = map[metricLabels]*metricSeries{}
+	}
+
+	s, ok := m.series[labels]
+	if !ok {
+		s = &metricSeries{}
+		m.series[labels] = s
+	}
+
+	s.count++
+	s.duration.observe(m.DurationBuckets, duration.Seconds())
+	s.size.observe(m.SizeBuckets, float64(size))
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteTo(w)
+}
+
+// WriteTo writes the metrics to w in the Prometheus text format.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	labels := make([]metricLabels, 0, len(m.series))
+	series := make(map[metricLabels]metricSeries, len(m.series))
+	for l, s := range m.series {
+		labels = append(labels, l)
+		series[l] = metricSeries{
+			count:    s.count,
+			duration: histogram{counts: append([]uint64(nil), s.duration.counts...), sum: s.duration.sum},
+			size:     histogram{counts: append([]uint64(nil), s.size.counts...), sum: s.size.sum},
+		}
+	}
+	m.mu.Unlock()
+
+	sort.Slice(labels, func(i, j int) bool {
+		a, b := labels[i], labels[j]
+		if a.pattern != b.pattern {
+			return a.pattern < b.pattern
+		}
+		if a.method != b.method {
+			return a.method < b.method
+		}
+		return a.status < b.status
+	})
+
+	var b strings.Builder
+
+	b.WriteString("# HELP http_requests_total Requests handled, by method, pattern and status.\n")
+	b.WriteString("# TYPE http_requests_total counter\n")
+	for _, l := range labels {
+		fmt.Fprintf(&b, "http_requests_total{%s} %d\n", l, series[l].count)
+	}
+
+	b.WriteString("# HELP http_request_duration_seconds Time taken to handle requests.\n")
+	b.WriteString("# TYPE http_request_duration_seconds histogram\n")
+	for _, l := range labels {
+		s := series[l]
+		writeHistogram(&b, "http_request_duration_seconds", l, m.DurationBuckets, s.duration, s.count)
+	}
+
+	b.WriteString("# HELP http_response_size_bytes Size of response bodies.\n")
+	b.WriteString("# TYPE http_response_size_bytes histogram\n")
+	for _, l := range labels {
+		s := series[l]
+		writeHistogram(&b, "http_response_size_bytes", l, m.SizeBuckets, s.size, s.count)
+	}
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// String formats the labels as in the Prometheus text format.
+func (l metricLabels) String() string {
+	return `method="` + escapeLabel(l.method) + `",pattern="` + escapeLabel(l.pattern) + `",status="` + l.status + `"`
+}
+
+func writeHistogram(b *strings.Builder, name string, l metricLabels, buckets []float64, h histogram, count uint64) {
+	for i, bound := range buckets {
+		var n uint64
+		if i < len(h.counts) {
+			n = h.counts[i]
+		}
+		fmt.Fprintf(b, "%s_bucket{%s,le=\"%s\"} %d\n", name, l, strconv.FormatFloat(bound, 'g', -1, 64), n)
+	}
+	fmt.Fprintf(b, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, l, count)
+	fmt.Fprintf(b, "%s_sum{%s} %s\n", name, l, strconv.FormatFloat(h.sum, 'g', -1, 64))
+	fmt.Fprintf(b, "%s_count{%s} %d\n", name, l, count)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
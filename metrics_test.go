@@ -0,0 +1,78 @@
+package route
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	metrics := NewMetrics()
+	metrics.SizeBuckets = []float64{1, 10}
+
+	router := New()
+	router.HandleFunc("GET /users/:id", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("user"))
+	})
+	router.HandleFunc("GET /about", func(w http.ResponseWriter, r *http.Request) {})
+	router.Handle("GET /metrics", metrics)
+
+	handler := metrics.Instrument(router)
+	for _, req := range []struct{ method, path string }{
+		{"GET", "/users/1"},
+		{"GET", "/users/2"},
+		{"GET", "/about"},
+		{"POST", "/about"},
+		{"GET", "/missing/1"},
+		{"BREW", "/about"},
+	} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(req.method, req.path, nil))
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	body := w.Body.String()
+
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", w.Header().Get("Content-Type"))
+
+	for _, line := range []string{
+		`http_requests_total{method="GET",pattern="/users/:id",status="200"} 2`,
+		`http_requests_total{method="GET",pattern="/about",status="200"} 1`,
+		`http_requests_total{method="POST",pattern="/about",status="405"} 1`,
+		`http_requests_total{method="OTHER",pattern="/about",status="405"} 1`,
+		`http_requests_total{method="GET",pattern="",status="404"} 1`,
+		`http_response_size_bytes_bucket{method="GET",pattern="/users/:id",status="200",le="1"} 0`,
+		`http_response_size_bytes_bucket{method="GET",pattern="/users/:id",status="200",le="10"} 2`,
+		`http_response_size_bytes_bucket{method="GET",pattern="/users/:id",status="200",le="+Inf"} 2`,
+		`http_response_size_bytes_sum{method="GET",pattern="/users/:id",status="200"} 8`,
+		`http_request_duration_seconds_count{method="GET",pattern="/about",status="200"} 1`,
+		`# TYPE http_request_duration_seconds histogram`,
+	} {
+		assert.Contains(t, strings.Split(body, "\n"), line)
+	}
+
+	assert.NotContains(t, body, "/users/1")
+}
+
+func TestMetricsZeroValue(t *testing.T) {
+	var metrics Metrics
+
+	router := New()
+	router.HandleFunc("GET /about", func(w http.ResponseWriter, r *http.Request) {})
+
+	handler := metrics.Instrument(router)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/about", nil))
+
+	var b strings.Builder
+	metrics.WriteTo(&b)
+
+	assert.Contains(t, b.String(), `http_requests_total{method="GET",pattern="/about",status="200"} 1`)
+	assert.Contains(t, b.String(), `http_request_duration_seconds_bucket{method="GET",pattern="/about",status="200",le="+Inf"} 1`)
+}
+
+func TestEscapeLabel(t *testing.T) {
+	assert.Equal(t, `a\"b\\c\nd`, escapeLabel("a\"b\\c\nd"))
+}
@@ -0,0 +1,316 @@
+// Package mux provides an API like that of github.com/gorilla/mux backed by a
+// route.Router, so that code using it can be migrated incrementally.
+//
+// Replacing the import of "github.com/gorilla/mux" with this package is enough
+// for most uses:
+//
+//   r := mux.NewRouter()
+//   r.HandleFunc("/products/{key}", productHandler).Methods("GET")
+//   r.PathPrefix("/static/").Handler(http.FileServer(http.Dir("public")))
+//
+//   s := r.PathPrefix("/users").Subrouter()
+//   s.HandleFunc("/{id:[0-9]+}", userHandler).Name("user")
+//
+// Unlike gorilla/mux routes are not matched in the order they were added, but
+// using the rules of route.Router, so the most specific route always wins.
+package mux
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"hawx.me/code/route"
+)
+
+// prefixVar is the name of the catch-all parameter used to implement
+// PathPrefix, it is removed from the map returned by Vars.
+const prefixVar = "mux.prefix"
+
+// Router registers routes to be matched and dispatches a handler.
+type Router struct {
+	// NotFoundHandler is called when no matching route is found. By default it is
+	// set to http.NotFoundHandler().
+	NotFoundHandler http.Handler
+
+	// MethodNotAllowedHandler is called when a route matches the path but not the
+	// method of the request. If not set the route.Router default is used.
+	MethodNotAllowedHandler http.Handler
+
+	root   *Router
+	prefix string
+
+	// mu guards registering routes, requests are served by router without it.
+	mu       sync.Mutex
+	router   *route.Router
+	patterns map[string][]*Route
+	named    map[string]*Route
+}
+
+// NewRouter returns a new Router.
+func NewRouter() *Router {
+	r := &Router{
+		NotFoundHandler: http.NotFoundHandler(),
+		router:          route.New(),
+		patterns:        map[string][]*Route{},
+		named:           map[string]*Route{},
+	}
+	r.root = r
+
+	methodNotAllowed := r.router.MethodNotAllowedHandler
+	r.router.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.NotFoundHandler.ServeHTTP(w, req)
+	})
+	r.router.MethodNotAllowedHandler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if r.MethodNotAllowedHandler != nil {
+			r.MethodNotAllowedHandler.ServeHTTP(w, req)
+		} else {
+			methodNotAllowed.ServeHTTP(w, req)
+		}
+	})
+
+	return r
+}
+
+// Route is a route registered with a Router. Its methods can be chained to
+// configure it further.
+type Route struct {
+	router  *Router
+	path    string
+	prefix  bool
+	methods []string
+	name    string
+	handler http.Handler
+
+	// registered is the pattern the route is registered to the route.Router
+	// with, if it has been.
+	registered string
+}
+
+// NewRoute returns a new Route, which must then be given a path.
+func (r *Router) NewRoute() *Route {
+	return &Route{router: r}
+}
+
+// Handle registers a new route with a matcher for the URL path.
+func (r *Router) Handle(path string, handler http.Handler) *Route {
+	return r.NewRoute().Path(path).Handler(handler)
+}
+
+// HandleFunc registers a new route with a matcher for the URL path.
+func (r *Router) HandleFunc(path string, f func(http.ResponseWriter, *http.Request)) *Route {
+	return r.NewRoute().Path(path).HandlerFunc(f)
+}
+
+// Path registers a new route with a matcher for the URL path.
+func (r *Router) Path(path string) *Route {
+	return r.NewRoute().Path(path)
+}
+
+// PathPrefix registers a new route with a matcher for the URL path prefix.
+func (r *Router) PathPrefix(prefix string) *Route {
+	return r.NewRoute().PathPrefix(prefix)
+}
+
+// Methods registers a new route with a matcher for HTTP methods.
+func (r *Router) Methods(methods ...string) *Route {
+	return r.NewRoute().Methods(methods...)
+}
+
+// Get returns the route registered with the given name, or nil.
+func (r *Router) Get(name string) *Route {
+	r.root.mu.Lock()
+	defer r.root.mu.Unlock()
+
+	return r.root.named[name]
+}
+
+// ServeHTTP dispatches the request to the handler of the matching route.
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.root.router.ServeHTTP(w, req)
+}
+
+// register registers the route to the route.Router once it has a handler,
+// replacing its previous registration if it has changed. It panics if the path
+// of the route is invalid.
+func (r *Router) register(rt *Route) {
+	if rt.registered != "" {
+		// routes for a pattern can only be removed together, so any others
+		// registered with it are added back
+		others := r.patterns[rt.registered]
+		for i, other := range others {
+			if other == rt {
+				others = append(others[:i:i], others[i+1:]...)
+				break
+			}
+		}
+
+		r.router.Remove(rt.registered)
+		for _, other := range others {
+			other.handle()
+		}
+
+		r.patterns[rt.registered] = others
+		rt.registered = ""
+	}
+
+	if rt.handler == nil {
+		return
+	}
+
+	rt.registered = rt.pattern()
+	rt.handle()
+	r.patterns[rt.registered] = append(r.patterns[rt.registered], rt)
+}
+
+// Path sets the path the route matches. It may contain variables in the form
+// {name} or {name:pattern}.
+func (rt *Route) Path(path string) *Route {
+	return rt.update(func() {
+		rt.path = rt.router.prefix + path
+	})
+}
+
+// PathPrefix sets a prefix of the path the route matches.
+func (rt *Route) PathPrefix(prefix string) *Route {
+	return rt.update(func() {
+		rt.path = rt.router.prefix + prefix
+		rt.prefix = true
+	})
+}
+
+// Methods restricts the route to requests with one of the methods.
+func (rt *Route) Methods(methods ...string) *Route {
+	return rt.update(func() {
+		rt.methods = append(rt.methods, methods...)
+	})
+}
+
+// Name sets the name of the route, so that it can be retrieved with
+// Router.Get.
+func (rt *Route) Name(name string) *Route {
+	return rt.update(func() {
+		rt.name = name
+		rt.router.root.named[name] = rt
+	})
+}
+
+// Handler sets the handler for the route.
+func (rt *Route) Handler(handler http.Handler) *Route {
+	return rt.update(func() {
+		rt.handler = handler
+	})
+}
+
+// HandlerFunc sets the handler function for the route.
+func (rt *Route) HandlerFunc(f func(http.ResponseWriter, *http.Request)) *Route {
+	return rt.Handler(http.HandlerFunc(f))
+}
+
+// GetName returns the name of the route.
+func (rt *Route) GetName() string {
+	return rt.name
+}
+
+// Subrouter returns a Router for the route, so that routes can be registered
+// below its path.
+func (rt *Route) Subrouter() *Router {
+	return &Router{root: rt.router.root, prefix: strings.TrimSuffix(rt.path, "/")}
+}
+
+// URL builds a URL for the route, pairs are alternating variable names and
+// values.
+func (rt *Route) URL(pairs ...string) (*url.URL, error) {
+	if rt.name == "" {
+		vars := map[string]string{}
+		for i := 0; i+1 < len(pairs); i += 2 {
+			vars[pairs[i]] = pairs[i+1]
+		}
+		return route.Build(rt.pattern(), vars, nil)
+	}
+
+	return rt.router.root.router.URL(rt.name, pairs...)
+}
+
+func (rt *Route) update(f func()) *Route {
+	root := rt.router.root
+	root.mu.Lock()
+	defer root.mu.Unlock()
+
+	f()
+	root.register(rt)
+	return rt
+}
+
+// handle registers the route to the route.Router with its pattern.
+func (rt *Route) handle() {
+	var opts []route.Option
+	if len(rt.methods) > 0 {
+		opts = append(opts, route.Methods(rt.methods...))
+	}
+	if rt.name != "" {
+		opts = append(opts, route.Name(rt.name))
+	}
+
+	rt.router.root.router.Handle(rt.registered, rt.handler, opts...)
+}
+
+// pattern returns the path of the route as a route.Router pattern.
+func (rt *Route) pattern() string {
+	path := strings.TrimSuffix(convertPath(rt.path), "/")
+	if rt.prefix {
+		path += "/*" + prefixVar
+	}
+	if path == "" {
+		path = "/"
+	}
+
+	return path
+}
+
+// convertPath converts the gorilla/mux variables in path to route.Router
+// parameters, so {name} becomes :name and {name:pattern} becomes
+// :name(pattern). A final variable with pattern ".*" becomes a catch-all
+// parameter.
+func convertPath(path string) string {
+	parts := strings.Split(path, "/")
+
+	for i, part := range parts {
+		if !strings.HasPrefix(part, "{") || !strings.HasSuffix(part, "}") {
+			continue
+		}
+
+		name, pattern := part[1:len(part)-1], ""
+		if j := strings.IndexByte(name, ':'); j >= 0 {
+			name, pattern = name[:j], name[j+1:]
+		}
+
+		switch {
+		case pattern == "":
+			parts[i] = ":" + name
+		case pattern == ".*" && i == len(parts)-1:
+			parts[i] = "*" + name
+		default:
+			parts[i] = ":" + name + "(" + pattern + ")"
+		}
+	}
+
+	return strings.Join(parts, "/")
+}
+
+// Vars returns the route variables for the current request, or an empty map if
+// there are none. Unlike route.Vars the map returned is a copy, so may be kept
+// after the request.
+func Vars(r *http.Request) map[string]string {
+	vars := route.Vars(r)
+
+	filtered := make(map[string]string, len(vars))
+	for k, v := range vars {
+		if k != prefixVar {
+			filtered[k] = v
+		}
+	}
+
+	return filtered
+}
@@ -0,0 +1,141 @@
+package mux
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func serve(router http.Handler, method, path string) *httptest.ResponseRecorder {
+	r, _ := http.NewRequest(method, path, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	return w
+}
+
+func varsHandler(vars *map[string]string) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		*vars = Vars(r)
+		w.WriteHeader(418)
+	}
+}
+
+func TestRouter(t *testing.T) {
+	var vars map[string]string
+
+	router := NewRouter()
+	router.HandleFunc("/products/{key}", varsHandler(&vars)).Methods("GET")
+
+	w := serve(router, "GET", "/products/shoe")
+	assert.Equal(t, 418, w.Code)
+	assert.Equal(t, map[string]string{"key": "shoe"}, vars)
+
+	w = serve(router, "POST", "/products/shoe")
+	assert.Equal(t, 405, w.Code)
+}
+
+func TestRouterWithPattern(t *testing.T) {
+	var idVars, slugVars map[string]string
+
+	router := NewRouter()
+	router.HandleFunc("/articles/{id:[0-9]+}", varsHandler(&idVars))
+	router.HandleFunc("/articles/{slug}", varsHandler(&slugVars))
+
+	serve(router, "GET", "/articles/12")
+	serve(router, "GET", "/articles/hello")
+
+	assert.Equal(t, map[string]string{"id": "12"}, idVars)
+	assert.Equal(t, map[string]string{"slug": "hello"}, slugVars)
+}
+
+func TestRouterPathPrefix(t *testing.T) {
+	var vars map[string]string
+
+	router := NewRouter()
+	router.PathPrefix("/static/").HandlerFunc(varsHandler(&vars))
+
+	w := serve(router, "GET", "/static/css/main.css")
+	assert.Equal(t, 418, w.Code)
+	assert.Equal(t, map[string]string{}, vars)
+
+	w = serve(router, "GET", "/other")
+	assert.Equal(t, 404, w.Code)
+}
+
+func TestRouterSubrouter(t *testing.T) {
+	var vars map[string]string
+
+	router := NewRouter()
+	s := router.PathPrefix("/users").Subrouter()
+	s.HandleFunc("/", varsHandler(&vars))
+	s.HandleFunc("/{id}", varsHandler(&vars)).Name("user")
+
+	w := serve(router, "GET", "/users/5")
+	assert.Equal(t, 418, w.Code)
+	assert.Equal(t, map[string]string{"id": "5"}, vars)
+
+	w = serve(router, "GET", "/users")
+	assert.Equal(t, 418, w.Code)
+
+	u, err := router.Get("user").URL("id", "6")
+	assert.Nil(t, err)
+	assert.Equal(t, "/users/6", u.String())
+}
+
+func TestRouterAddAfterServing(t *testing.T) {
+	router := NewRouter()
+	assert.Equal(t, 404, serve(router, "GET", "/late").Code)
+
+	router.HandleFunc("/late", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(418)
+	})
+	assert.Equal(t, 418, serve(router, "GET", "/late").Code)
+}
+
+func TestRouterMethodsForSamePath(t *testing.T) {
+	router := NewRouter()
+	router.HandleFunc("/items", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+	}).Methods("GET")
+	router.HandleFunc("/items", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(201)
+	}).Methods("POST").Name("items.create")
+
+	assert.Equal(t, 200, serve(router, "GET", "/items").Code)
+	assert.Equal(t, 201, serve(router, "POST", "/items").Code)
+	assert.Equal(t, 405, serve(router, "DELETE", "/items").Code)
+
+	u, err := router.Get("items.create").URL()
+	assert.Nil(t, err)
+	assert.Equal(t, "/items", u.String())
+}
+
+func TestRouterInvalidPathPanicsWhenRegistered(t *testing.T) {
+	router := NewRouter()
+
+	assert.Panics(t, func() {
+		router.HandleFunc("/a/{b:(}", func(w http.ResponseWriter, r *http.Request) {})
+	})
+}
+
+func TestVarsWithoutRoute(t *testing.T) {
+	r, _ := http.NewRequest("GET", "/", nil)
+
+	assert.Equal(t, map[string]string{}, Vars(r))
+}
+
+func TestConvertPath(t *testing.T) {
+	cases := map[string]string{
+		"/":                  "/",
+		"/a/{b}":             "/a/:b",
+		"/a/{b:[0-9]+}/c":    "/a/:b([0-9]+)/c",
+		"/files/{path:.*}":   "/files/*path",
+		"/files/{path:.*}/x": "/files/:path(.*)/x",
+	}
+
+	for path, expected := range cases {
+		assert.Equal(t, expected, convertPath(path))
+	}
+}
@@ -0,0 +1,133 @@
+package route
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+type observationKey struct{}
+
+// observation is filled in by the router with the route that matched a request,
+// so that middleware wrapping the router can describe the request by the route
+// rather than its path.
+type observation struct {
+	pattern string
+	name    string
+
+	// vars are the unescaped parameter values, only recorded if wantVars is
+	// set.
+	vars     map[string]string
+	wantVars bool
+
+	// err is the error returned by the handler, if any.
+	err error
+}
+
+// observe returns the request with an observation that will be filled in by
+// any router that serves it. If the request is already being observed the same
+// observation is returned.
+func observe(r *http.Request, wantVars bool) (*http.Request, *observation) {
+	if o, ok := r.Context().Value(observationKey{}).(*observation); ok {
+		o.wantVars = o.wantVars || wantVars
+		return r, o
+	}
+
+	o := &observation{wantVars: wantVars}
+	return r.WithContext(context.WithValue(r.Context(), observationKey{}, o)), o
+}
+
+// observed records the route matched by the request, if it is being observed.
+func observed(r *http.Request, ep *endpoint, e *entry, ps Params, decoded bool) {
+	o, ok := r.Context().Value(observationKey{}).(*observation)
+	if !ok {
+		return
+	}
+
+	o.pattern = ep.host + ep.pattern
+	if e != nil {
+		o.name = e.name
+	}
+
+	if o.wantVars && len(ps) > 0 {
+		o.vars = ps.Map()
+		if !decoded {
+			for k, v := range o.vars {
+				if unescaped, err := url.PathUnescape(v); err == nil {
+					o.vars[k] = unescaped
+				}
+			}
+		}
+	}
+}
+
+// observedError records the error returned by the handler for the request, if
+// it is being observed.
+func observedError(r *http.Request, err error) {
+	if o, ok := r.Context().Value(observationKey{}).(*observation); ok {
+		o.err = err
+	}
+}
+
+// responseRecorder records the status and size of the response written to it.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	size   int64
+}
+
+func (w *responseRecorder) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseRecorder) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Flush sends any buffered data to the client, if the ResponseWriter supports
+// it.
+func (w *responseRecorder) Flush() {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack lets the caller take over the connection, if the ResponseWriter, or
+// any it wraps, supports it.
+func (w *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	if w.status == 0 {
+		w.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, nil
+}
+
+// Unwrap returns the ResponseWriter, for use by http.ResponseController.
+func (w *responseRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// code returns the status of the response, which is 200 OK if nothing was
+// written.
+func (w *responseRecorder) code() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
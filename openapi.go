@@ -0,0 +1,362 @@
+package route
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// OpenAPI is an OpenAPI 3 document describing the routes of a Router, as
+// returned by Router.OpenAPI. It can be encoded as JSON.
+type OpenAPI struct {
+	OpenAPI string                                  `json:"openapi"`
+	Info    OpenAPIInfo                             `json:"info"`
+	Paths   map[string]map[string]*OpenAPIOperation `json:"paths"`
+}
+
+// OpenAPIInfo gives the title and version of the API.
+type OpenAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// An OpenAPIOperation describes the route for a path and method.
+type OpenAPIOperation struct {
+	OperationID string                     `json:"operationId,omitempty"`
+	Summary     string                     `json:"summary,omitempty"`
+	Description string                     `json:"description,omitempty"`
+	Parameters  []OpenAPIParameter         `json:"parameters,omitempty"`
+	Responses   map[string]OpenAPIResponse `json:"responses"`
+}
+
+// An OpenAPIParameter describes a path parameter.
+type OpenAPIParameter struct {
+	Name     string        `json:"name"`
+	In       string        `json:"in"`
+	Required bool          `json:"required"`
+	Schema   OpenAPISchema `json:"schema"`
+}
+
+// OpenAPISchema gives the type of the values of a parameter.
+type OpenAPISchema struct {
+	Type    string   `json:"type"`
+	Format  string   `json:"format,omitempty"`
+	Pattern string   `json:"pattern,omitempty"`
+	Enum    []string `json:"enum,omitempty"`
+}
+
+// OpenAPIResponse describes a response of an operation.
+type OpenAPIResponse struct {
+	Description string `json:"description"`
+}
+
+// openAPIMethods are the methods that OpenAPI can describe operations for.
+var openAPIMethods = map[string]bool{
+	"GET": true, "PUT": true, "POST": true, "DELETE": true,
+	"OPTIONS": true, "HEAD": true, "PATCH": true, "TRACE": true,
+}
+
+// integerPatterns are constraints that only match integers.
+var integerPatterns = map[string]bool{
+	`[0-9]+`: true, `\d+`: true, `-?[0-9]+`: true, `-?\d+`: true, `[1-9][0-9]*`: true,
+}
+
+// OpenAPI returns an OpenAPI 3 document describing the registered routes, so
+// that a specification can be served, or written out, without being kept in
+// sync by hand:
+//
+//   router.Handle("GET /users/:id([0-9]+)", showUser, route.Name("showUser"),
+//     route.Tag("summary", "Show a user"))
+//
+//   router.HandleFunc("GET /openapi.json", func(w http.ResponseWriter, r *http.Request) {
+//     json.NewEncoder(w).Encode(router.OpenAPI("Users", "1.0"))
+//   })
+//
+// Each route is described by an operation for each of its methods, or for GET
+// if it is not restricted to methods. The route's name is used as the
+// operationId, followed by the method in lowercase, as in "items.post", when
+// the route is described by more than one operation, and string metadata
+// attached with Tag for the keys "summary" and "description" as the summary and
+// description. Parameters have a type derived from their constraint, so
+// "(\d+)" is an integer, "{a,b}" an enum, other expressions a pattern and the
+// name of a matcher a format. Catch-all parameters are described as single path
+// parameters.
+//
+// Routes registered for a host are described by their path, where routes for
+// the same path and method conflict the first visited by Walk is used.
+func (r *Router) OpenAPI(title, version string) *OpenAPI {
+	doc := &OpenAPI{
+		OpenAPI: "3.0.3",
+		Info:    OpenAPIInfo{Title: title, Version: version},
+		Paths:   map[string]map[string]*OpenAPIOperation{},
+	}
+
+	for _, info := range r.Routes() {
+		path, params := openAPIPath(info.Pattern)
+
+		var methods []string
+		for _, method := range info.Methods {
+			if openAPIMethods[method] {
+				methods = append(methods, method)
+			}
+		}
+		if len(info.Methods) == 0 {
+			methods = []string{"GET"}
+		}
+
+		for _, method := range methods {
+			operationID := info.Name
+			if operationID != "" && len(methods) > 1 {
+				operationID += "." + strings.ToLower(method)
+			}
+
+			item, ok := doc.Paths[path]
+			if !ok {
+				item = map[string]*OpenAPIOperation{}
+				doc.Paths[path] = item
+			}
+			if _, ok := item[strings.ToLower(method)]; ok {
+				continue
+			}
+
+			summary, _ := info.Meta["summary"].(string)
+			description, _ := info.Meta["description"].(string)
+
+			item[strings.ToLower(method)] = &OpenAPIOperation{
+				OperationID: operationID,
+				Summary:     summary,
+				Description: description,
+				Parameters:  params,
+				Responses: map[string]OpenAPIResponse{
+					"default": {Description: "Response"},
+				},
+			}
+		}
+	}
+
+	return doc
+}
+
+// openAPIPath returns the OpenAPI path template for the pattern, with the
+// parameters it contains.
+func openAPIPath(pattern string) (string, []OpenAPIParameter) {
+	var params []OpenAPIParameter
+	parts := strings.Split(pattern, "/")
+
+	for i, part := range parts {
+		if strings.HasPrefix(part, "*") {
+			parts[i] = "{" + part[1:] + "}"
+			params = append(params, OpenAPIParameter{
+				Name:     part[1:],
+				In:       "path",
+				Required: true,
+				Schema:   OpenAPISchema{Type: "string"},
+			})
+			continue
+		}
+
+		seg, ok := parseSegment(part)
+		if !ok {
+			continue
+		}
+
+		parts[i] = seg.prefix + "{" + seg.name + "}" + seg.suffix
+		params = append(params, OpenAPIParameter{
+			Name:     seg.name,
+			In:       "path",
+			Required: true,
+			Schema:   constraintSchema(seg.constraint),
+		})
+	}
+
+	return strings.Join(parts, "/"), params
+}
+
+// constraintSchema returns the schema of values matching the constraint.
+func constraintSchema(constraint string) OpenAPISchema {
+	if constraint == "" {
+		return OpenAPISchema{Type: "string"}
+	}
+
+	inner := constraint[1 : len(constraint)-1]
+
+	switch constraint[0] {
+	case '{':
+		return OpenAPISchema{Type: "string", Enum: strings.Split(inner, ",")}
+	case '<':
+		return OpenAPISchema{Type: "string", Format: inner}
+	}
+
+	if integerPatterns[inner] {
+		return OpenAPISchema{Type: "integer"}
+	}
+
+	return OpenAPISchema{Type: "string", Pattern: "^(?:" + inner + ")$"}
+}
+
+// HandleOpenAPI registers a route for each operation of the OpenAPI 3 document,
+// read from spec as JSON, so the routes of an application can be written
+// first as a specification. The handler for each operation is taken from
+// handlers by its operationId, and may be any type accepted by Handle or
+// HandleFunc:
+//
+//   f, _ := os.Open("openapi.json")
+//   err := router.HandleOpenAPI(f, map[string]interface{}{
+//     "listUsers": listUsers,
+//     "showUser":  showUser,
+//   })
+//
+// Path templates such as "/users/{id}" are registered as "/users/:id", with a
+// constraint derived from the schema of the parameter: integers must be digits,
+// enums one of their values, patterns must match, and a format that names a
+// registered matcher uses it. Each route is given its operationId as its name,
+// and its summary and description as metadata as for Router.OpenAPI. The opts
+// are applied to every route.
+//
+// An error is returned if the document cannot be read, if any operation has no
+// operationId or no handler in handlers, or if a route cannot be registered. In
+// each case no routes are registered, unless they conflict with routes already
+// registered, as for Merge.
+func (r *Router) HandleOpenAPI(spec io.Reader, handlers map[string]interface{}, opts ...Option) error {
+	if r.frozen {
+		return ErrFrozen
+	}
+
+	ops, err := readOpenAPI(spec)
+	if err != nil {
+		return err
+	}
+
+	var unbound []string
+	for _, op := range ops {
+		if op.OperationID == "" {
+			unbound = append(unbound, op.method+" "+op.path)
+		} else if _, ok := handlers[op.OperationID]; !ok {
+			unbound = append(unbound, op.OperationID)
+		}
+	}
+	if len(unbound) > 0 {
+		return errors.New("route: unbound OpenAPI operations: " + strings.Join(unbound, ", "))
+	}
+
+	next := r.fork()
+
+	for _, op := range ops {
+		o := append([]Option{Name(op.OperationID)}, opts...)
+		if op.Summary != "" {
+			o = append(o, Tag("summary", op.Summary))
+		}
+		if op.Description != "" {
+			o = append(o, Tag("description", op.Description))
+		}
+
+		pattern := op.method + " " + routePath(op.path, op.params, next.tree.matchers)
+
+		var err error
+		switch handler := handlers[op.OperationID].(type) {
+		case Handler, http.Handler:
+			err = next.TryHandle(pattern, handler, o...)
+		default:
+			err = next.TryHandleFunc(pattern, handler, o...)
+		}
+		if err != nil {
+			return fmt.Errorf("route: registering OpenAPI operation %s: %w", op.OperationID, err)
+		}
+	}
+
+	return r.Merge(next)
+}
+
+// openAPIOperation is an operation read from an OpenAPI document, with the
+// parameters of its path item.
+type openAPIOperation struct {
+	OpenAPIOperation
+
+	method, path string
+	params       map[string]OpenAPISchema
+}
+
+// readOpenAPI reads the operations from an OpenAPI document, ordered by path
+// then method.
+func readOpenAPI(spec io.Reader) ([]openAPIOperation, error) {
+	var doc struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.NewDecoder(spec).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("route: reading OpenAPI document: %w", err)
+	}
+
+	var ops []openAPIOperation
+	for path, item := range doc.Paths {
+		var shared []OpenAPIParameter
+		if raw, ok := item["parameters"]; ok {
+			if err := json.Unmarshal(raw, &shared); err != nil {
+				return nil, fmt.Errorf("route: reading OpenAPI parameters of %s: %w", path, err)
+			}
+		}
+
+		for key, raw := range item {
+			method := strings.ToUpper(key)
+			if !openAPIMethods[method] {
+				continue
+			}
+
+			op := openAPIOperation{method: method, path: path, params: map[string]OpenAPISchema{}}
+			if err := json.Unmarshal(raw, &op.OpenAPIOperation); err != nil {
+				return nil, fmt.Errorf("route: reading OpenAPI operation %s %s: %w", method, path, err)
+			}
+
+			for _, param := range append(shared, op.Parameters...) {
+				if param.In == "path" {
+					op.params[param.Name] = param.Schema
+				}
+			}
+
+			ops = append(ops, op)
+		}
+	}
+
+	sort.Slice(ops, func(i, j int) bool {
+		if ops[i].path != ops[j].path {
+			return ops[i].path < ops[j].path
+		}
+		return ops[i].method < ops[j].method
+	})
+
+	return ops, nil
+}
+
+// templateParam matches a parameter in an OpenAPI path template.
+var templateParam = regexp.MustCompile(`\{([A-Za-z0-9_]+)\}`)
+
+// routePath returns the path pattern for an OpenAPI path template, with each
+// parameter constrained by its schema.
+func routePath(path string, params map[string]OpenAPISchema, matchers map[string]MatcherFunc) string {
+	return templateParam.ReplaceAllStringFunc(path, func(s string) string {
+		name := s[1 : len(s)-1]
+		return ":" + name + schemaConstraint(params[name], matchers)
+	})
+}
+
+// schemaConstraint returns the constraint for parameters with the schema.
+func schemaConstraint(schema OpenAPISchema, matchers map[string]MatcherFunc) string {
+	switch {
+	case len(schema.Enum) > 0:
+		return "{" + strings.Join(schema.Enum, ",") + "}"
+	case schema.Type == "integer":
+		return `(-?[0-9]+)`
+	case schema.Pattern != "":
+		return "(" + strings.TrimSuffix(strings.TrimPrefix(schema.Pattern, "^"), "$") + ")"
+	}
+
+	if _, ok := matchers[schema.Format]; ok && schema.Format != "" {
+		return "<" + schema.Format + ">"
+	}
+
+	return ""
+}
@@ -0,0 +1,172 @@
+package route
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouterOpenAPI(t *testing.T) {
+	router := New()
+	router.Matcher("slug", func(segment string) (string, bool) { return segment, true })
+	router.Handle("GET /users/:id([0-9]+)", &recordingHandler{}, Name("showUser"),
+		Tag("summary", "Show a user"), Tag("description", "Shows the user."))
+	router.Handle("PUT /users/:id([0-9]+)", &recordingHandler{})
+	router.Handle("/reports/:period{daily,weekly}/:slug<slug>", &recordingHandler{})
+	router.Handle("/v{version}/files/*path", &recordingHandler{}, Methods("GET", "CONNECT"))
+	router.Handle("/codes/:code([a-z]{3})", &recordingHandler{})
+
+	doc := router.OpenAPI("Users", "1.0")
+
+	assert.Equal(t, "3.0.3", doc.OpenAPI)
+	assert.Equal(t, OpenAPIInfo{Title: "Users", Version: "1.0"}, doc.Info)
+
+	if assert.Contains(t, doc.Paths, "/users/{id}") {
+		item := doc.Paths["/users/{id}"]
+		assert.Len(t, item, 2)
+
+		assert.Equal(t, &OpenAPIOperation{
+			OperationID: "showUser",
+			Summary:     "Show a user",
+			Description: "Shows the user.",
+			Parameters: []OpenAPIParameter{
+				{Name: "id", In: "path", Required: true, Schema: OpenAPISchema{Type: "integer"}},
+			},
+			Responses: map[string]OpenAPIResponse{"default": {Description: "Response"}},
+		}, item["get"])
+		assert.Contains(t, item, "put")
+	}
+
+	if assert.Contains(t, doc.Paths, "/reports/{period}/{slug}") {
+		assert.Equal(t, []OpenAPIParameter{
+			{Name: "period", In: "path", Required: true, Schema: OpenAPISchema{Type: "string", Enum: []string{"daily", "weekly"}}},
+			{Name: "slug", In: "path", Required: true, Schema: OpenAPISchema{Type: "string", Format: "slug"}},
+		}, doc.Paths["/reports/{period}/{slug}"]["get"].Parameters)
+	}
+
+	if assert.Contains(t, doc.Paths, "/v{version}/files/{path}") {
+		item := doc.Paths["/v{version}/files/{path}"]
+		assert.Len(t, item, 1)
+		assert.Len(t, item["get"].Parameters, 2)
+	}
+
+	if assert.Contains(t, doc.Paths, "/codes/{code}") {
+		assert.Equal(t, OpenAPISchema{Type: "string", Pattern: "^(?:[a-z]{3})$"},
+			doc.Paths["/codes/{code}"]["get"].Parameters[0].Schema)
+	}
+
+	_, err := json.Marshal(doc)
+	assert.Nil(t, err)
+}
+
+func TestRouterOpenAPIOperationIDForMethods(t *testing.T) {
+	router := New()
+	router.Handle("/items", &recordingHandler{}, Methods("GET", "POST"), Name("items"))
+	router.Handle("/items/:id", &recordingHandler{}, Methods("GET", "CONNECT"), Name("item"))
+
+	doc := router.OpenAPI("Items", "1.0")
+
+	assert.Equal(t, "items.get", doc.Paths["/items"]["get"].OperationID)
+	assert.Equal(t, "items.post", doc.Paths["/items"]["post"].OperationID)
+	assert.Equal(t, "item", doc.Paths["/items/{id}"]["get"].OperationID)
+}
+
+const usersSpec = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Users", "version": "1.0"},
+  "paths": {
+    "/users": {
+      "get": {"operationId": "listUsers", "summary": "List users", "responses": {}},
+      "post": {"operationId": "createUser", "responses": {}}
+    },
+    "/users/{id}": {
+      "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}}],
+      "summary": "A user",
+      "get": {"operationId": "showUser", "responses": {}}
+    },
+    "/reports/{period}/{code}": {
+      "get": {
+        "operationId": "showReport",
+        "parameters": [
+          {"name": "period", "in": "path", "required": true, "schema": {"type": "string", "enum": ["daily", "weekly"]}},
+          {"name": "code", "in": "path", "required": true, "schema": {"type": "string", "pattern": "^[a-z]{3}$"}},
+          {"name": "q", "in": "query", "schema": {"type": "string"}}
+        ],
+        "responses": {}
+      }
+    }
+  }
+}`
+
+func TestRouterHandleOpenAPI(t *testing.T) {
+	var called string
+	handler := func(name string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			called = name
+		}
+	}
+
+	router := New()
+	err := router.HandleOpenAPI(strings.NewReader(usersSpec), map[string]interface{}{
+		"listUsers":  handler("listUsers"),
+		"createUser": handler("createUser"),
+		"showUser": func(w http.ResponseWriter, r *http.Request) {
+			called = "showUser " + Vars(r)["id"]
+		},
+		"showReport": handler("showReport"),
+	})
+	assert.Nil(t, err)
+
+	cases := []struct {
+		method, path, called string
+		code                 int
+	}{
+		{"GET", "/users", "listUsers", 200},
+		{"POST", "/users", "createUser", 200},
+		{"DELETE", "/users", "", 405},
+		{"GET", "/users/5", "showUser 5", 200},
+		{"GET", "/users/me", "", 404},
+		{"GET", "/reports/daily/abc", "showReport", 200},
+		{"GET", "/reports/yearly/abc", "", 404},
+		{"GET", "/reports/daily/abcd", "", 404},
+	}
+
+	for _, tc := range cases {
+		called = ""
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+
+		assert.Equal(t, tc.code, w.Code, tc.method+" "+tc.path)
+		assert.Equal(t, tc.called, called, tc.method+" "+tc.path)
+	}
+
+	u, err := router.URL("showUser", "id", "7")
+	assert.Nil(t, err)
+	assert.Equal(t, "/users/7", u.String())
+
+	doc := router.OpenAPI("Users", "1.0")
+	assert.Equal(t, "List users", doc.Paths["/users"]["get"].Summary)
+	assert.Equal(t, OpenAPISchema{Type: "integer"}, doc.Paths["/users/{id}"]["get"].Parameters[0].Schema)
+}
+
+func TestRouterHandleOpenAPIUnbound(t *testing.T) {
+	router := New()
+	err := router.HandleOpenAPI(strings.NewReader(usersSpec), map[string]interface{}{
+		"listUsers": &recordingHandler{},
+		"showUser":  &recordingHandler{},
+	})
+
+	assert.EqualError(t, err, "route: unbound OpenAPI operations: showReport, createUser")
+	assert.Empty(t, router.Routes())
+}
+
+func TestRouterHandleOpenAPIInvalid(t *testing.T) {
+	router := New()
+	err := router.HandleOpenAPI(strings.NewReader(`{"paths": `), nil)
+
+	assert.NotNil(t, err)
+}
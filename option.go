@@ -0,0 +1,307 @@
+package route
+
+import (
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// An Option configures a route as it is registered with Handle or HandleFunc.
+type Option func(*entry)
+
+// entry holds the configuration of a route being registered.
+type entry struct {
+	name         string
+	methods      []string
+	conditions   []Condition
+	contentTypes []string
+	formats      []string
+	handler      Handler
+	source       string
+	slash        SlashPolicy
+	canonical    string
+	meta         map[string]any
+	stats        *routeStats
+
+	// links are the Link headers set by Preload.
+	links []string
+
+	// cacheControl and surrogateControl are the headers set by Caching.
+	cacheControl     string
+	surrogateControl string
+
+	// origins are the Origins accepted by routes registered with WebSocket.
+	origins []string
+
+	// files configure the handler of routes registered with Static.
+	files []func(*fileServer)
+}
+
+// conditional returns true if the route has any conditions, other than its
+// methods, that must be met.
+func (e *entry) conditional() bool {
+	return len(e.conditions) > 0 || len(e.contentTypes) > 0
+}
+
+// priority orders the routes of an endpoint, so that those that are more
+// specific are tried first.
+func (e *entry) priority() int {
+	p := 0
+	if len(e.methods) == 0 {
+		p += 2
+	}
+	if !e.conditional() {
+		p++
+	}
+
+	return p
+}
+
+// satisfied returns true if the request meets all of the conditions of the
+// route.
+func (e *entry) satisfied(r *http.Request) bool {
+	for _, condition := range e.conditions {
+		if !condition(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// accepts returns true if the route handles requests with the method.
+func (e *entry) accepts(method string) bool {
+	if len(e.methods) == 0 {
+		return true
+	}
+
+	for _, m := range e.methods {
+		if m == method {
+			return true
+		}
+	}
+
+	return false
+}
+
+// A Condition decides whether a route can handle a request, after the route has
+// been matched by path and method.
+type Condition func(r *http.Request) bool
+
+// When restricts the route to requests meeting the condition. It can be used to
+// choose between handlers registered for the same path:
+//
+//   router.Handle("/", betaHandler, route.When(func(r *http.Request) bool {
+//     _, err := r.Cookie("beta")
+//     return err == nil
+//   }))
+//   router.Handle("/", homeHandler)
+//
+// When the route does not meet the condition other routes for the same path are
+// tried, if none match NotFoundHandler is used.
+func When(condition Condition) Option {
+	return func(e *entry) {
+		e.conditions = append(e.conditions, condition)
+	}
+}
+
+// acceptsContentType returns true if the route handles requests with the
+// Content-Type of the request.
+func (e *entry) acceptsContentType(r *http.Request) bool {
+	if len(e.contentTypes) == 0 {
+		return true
+	}
+
+	mediatype, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+
+	for _, contentType := range e.contentTypes {
+		if contentType == mediatype ||
+			strings.HasSuffix(contentType, "/*") && strings.HasPrefix(mediatype, contentType[:len(contentType)-1]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Name gives the route a name, so that its URL can be built with Router.URL.
+// Names must be unique within a Router.
+func Name(name string) Option {
+	return func(e *entry) {
+		e.name = name
+	}
+}
+
+// Methods restricts the route to requests using one of the given methods. A
+// route without this option handles any method.
+func Methods(methods ...string) Option {
+	return func(e *entry) {
+		for _, method := range methods {
+			e.methods = append(e.methods, strings.ToUpper(method))
+		}
+	}
+}
+
+// Scheme restricts the route to requests made with one of the given schemes,
+// "http" or "https". The scheme is "https" if the request was received over
+// TLS, otherwise it is taken from the X-Forwarded-Proto header, so that
+// requests through a proxy terminating TLS are recognised. The header is
+// trusted, so should be set by the proxy.
+//
+// When the route does not match the scheme of a request other routes for the
+// same path are tried, if none match NotFoundHandler is used.
+func Scheme(schemes ...string) Option {
+	return When(func(r *http.Request) bool {
+		scheme := requestScheme(r)
+		for _, s := range schemes {
+			if strings.EqualFold(s, scheme) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// requestScheme returns the scheme used by the client to make the request.
+func requestScheme(r *http.Request) string {
+	if r.TLS != nil {
+		return "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		return strings.ToLower(strings.TrimSpace(strings.Split(proto, ",")[0]))
+	}
+
+	return "http"
+}
+
+// Header restricts the route to requests with the header set to value. If value
+// is empty the header only needs to be present.
+//
+//   router.Handle("/users", usersV2Handler, route.Header("X-API-Version", "2"))
+//   router.Handle("/users", usersHandler)
+//
+// When the route does not match the headers of a request other routes for the
+// same path are tried, if none match NotFoundHandler is used.
+func Header(key, value string) Option {
+	return When(func(r *http.Request) bool {
+		values, ok := r.Header[http.CanonicalHeaderKey(key)]
+		if !ok {
+			return false
+		}
+		if value == "" {
+			return true
+		}
+
+		for _, v := range values {
+			if v == value {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Query restricts the route to requests with the query parameter set to value.
+// If value is empty the parameter only needs to be present.
+//
+//   router.Handle("/search", rssHandler, route.Query("format", "rss"))
+//   router.Handle("/search", searchHandler)
+//
+// When the route does not match the query of a request other routes for the
+// same path are tried, if none match NotFoundHandler is used.
+func Query(key, value string) Option {
+	return When(func(r *http.Request) bool {
+		values, ok := r.URL.Query()[key]
+		if !ok {
+			return false
+		}
+		if value == "" {
+			return true
+		}
+
+		for _, v := range values {
+			if v == value {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// QueryRegexp restricts the route to requests with a value for the query
+// parameter that matches the regular expression. It panics if the expression is
+// invalid.
+func QueryRegexp(key, expr string) Option {
+	re := regexp.MustCompile(expr)
+
+	return When(func(r *http.Request) bool {
+		for _, v := range r.URL.Query()[key] {
+			if re.MatchString(v) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// ContentType restricts the route to requests with a body of one of the given
+// media types. A type may end in "/*" to accept any subtype.
+//
+//   router.Handle("POST /users", createUserJSON, route.ContentType("application/json"))
+//   router.Handle("POST /users", createUserForm, route.ContentType(
+//     "application/x-www-form-urlencoded", "multipart/form-data"))
+//
+// When the route does not accept the Content-Type of a request other routes for
+// the same path are tried, if none match the router responds with 415
+// Unsupported Media Type.
+func ContentType(types ...string) Option {
+	return func(e *entry) {
+		for _, t := range types {
+			e.contentTypes = append(e.contentTypes, strings.ToLower(t))
+		}
+	}
+}
+
+// Formats registers the route for paths ending in each of the extensions too,
+// setting the "format" parameter to the extension used, so a single route can
+// serve multiple representations:
+//
+//   router.Handle("/reports/:id", reportHandler, route.Formats("json", "csv"))
+//
+//   /reports/5          match: id="5"
+//   /reports/5.json     match: id="5", format="json"
+//   /reports/5.csv      match: id="5", format="csv"
+//   /reports/5.xml      match: id="5.xml"
+//
+// The path must not end in a catch-all parameter.
+func Formats(extensions ...string) Option {
+	return func(e *entry) {
+		e.formats = append(e.formats, extensions...)
+	}
+}
+
+// Preload adds a Link header to responses for the route, asking the browser to
+// preload the resource at href, so that the assets a page needs are declared
+// with its route:
+//
+//   router.Handle("GET /dashboard", dashboardHandler,
+//     route.Preload("/assets/dashboard.css", "style"),
+//     route.Preload("/assets/dashboard.js", "script"),
+//     route.Preload("/assets/inter.woff2", "font"))
+//
+// The as argument is the type of the resource, as "style", "script", "font" or
+// "image". Fonts are preloaded in anonymous CORS mode, as they are fetched in.
+func Preload(href, as string) Option {
+	link := "<" + href + ">; rel=preload; as=" + as
+	if as == "font" {
+		link += "; crossorigin"
+	}
+
+	return func(e *entry) {
+		e.links = append(e.links, link)
+	}
+}
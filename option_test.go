@@ -0,0 +1,282 @@
+package route
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheme(t *testing.T) {
+	router := New()
+
+	plainHandler := &recordingHandler{}
+	secureHandler := &recordingHandler{}
+	router.Handle("/.well-known/acme-challenge/:token", plainHandler, Scheme("http"))
+	router.Handle("/login", secureHandler, Scheme("https"))
+
+	r, _ := http.NewRequest("GET", "/.well-known/acme-challenge/abc", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	assert.True(t, plainHandler.Used)
+
+	r, _ = http.NewRequest("GET", "/login", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	assert.False(t, secureHandler.Used)
+	assert.Equal(t, 404, w.Code)
+
+	r, _ = http.NewRequest("GET", "/login", nil)
+	r.TLS = &tls.ConnectionState{}
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	assert.True(t, secureHandler.Used)
+}
+
+func TestSchemeWithForwardedProto(t *testing.T) {
+	router := New()
+
+	secureHandler := &recordingHandler{}
+	plainHandler := &recordingHandler{}
+	router.Handle("/", secureHandler, Scheme("HTTPS"))
+	router.Handle("/", plainHandler)
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	r.Header.Set("X-Forwarded-Proto", "https")
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	assert.True(t, secureHandler.Used)
+	assert.False(t, plainHandler.Used)
+
+	r, _ = http.NewRequest("GET", "/", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	assert.True(t, plainHandler.Used)
+}
+
+func TestHeader(t *testing.T) {
+	router := New()
+
+	v2Handler := &recordingHandler{}
+	hookHandler := &recordingHandler{}
+	defaultHandler := &recordingHandler{}
+	router.Handle("/users", v2Handler, Header("X-API-Version", "2"))
+	router.Handle("/users", hookHandler, Header("x-hook", ""))
+	router.Handle("/users", defaultHandler)
+
+	r, _ := http.NewRequest("GET", "/users", nil)
+	r.Header.Set("X-API-Version", "2")
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	assert.True(t, v2Handler.Used)
+	assert.False(t, hookHandler.Used)
+	assert.False(t, defaultHandler.Used)
+
+	r, _ = http.NewRequest("GET", "/users", nil)
+	r.Header.Set("X-Hook", "push")
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	assert.True(t, hookHandler.Used)
+	assert.False(t, defaultHandler.Used)
+
+	r, _ = http.NewRequest("GET", "/users", nil)
+	r.Header.Set("X-API-Version", "1")
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	assert.True(t, defaultHandler.Used)
+}
+
+func TestHeaderWithMethods(t *testing.T) {
+	router := New()
+	router.Handle("POST /hook", &recordingHandler{}, Header("X-Event", "push"))
+
+	r, _ := http.NewRequest("POST", "/hook", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	assert.Equal(t, 404, w.Code)
+
+	r, _ = http.NewRequest("GET", "/hook", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	assert.Equal(t, 405, w.Code)
+}
+
+func TestQuery(t *testing.T) {
+	router := New()
+
+	rssHandler := &recordingHandler{}
+	pageHandler := &recordingHandler{}
+	searchHandler := &recordingHandler{}
+	router.Handle("/search", rssHandler, Query("format", "rss"))
+	router.Handle("/search", pageHandler, QueryRegexp("page", "^[0-9]+$"))
+	router.Handle("/search", searchHandler)
+
+	r, _ := http.NewRequest("GET", "/search?q=go&format=rss", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	assert.True(t, rssHandler.Used)
+	assert.False(t, searchHandler.Used)
+
+	r, _ = http.NewRequest("GET", "/search?q=go&page=2", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	assert.True(t, pageHandler.Used)
+	assert.False(t, searchHandler.Used)
+
+	r, _ = http.NewRequest("GET", "/search?q=go&format=atom&page=two", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	assert.True(t, searchHandler.Used)
+}
+
+func TestQueryPresence(t *testing.T) {
+	router := New()
+
+	debugHandler := &recordingHandler{}
+	router.Handle("/status", debugHandler, Query("debug", ""))
+
+	r, _ := http.NewRequest("GET", "/status?debug", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	assert.True(t, debugHandler.Used)
+
+	r, _ = http.NewRequest("GET", "/status", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	assert.Equal(t, 404, w.Code)
+}
+
+func TestWhen(t *testing.T) {
+	router := New()
+
+	betaHandler := &recordingHandler{}
+	homeHandler := &recordingHandler{}
+	router.Handle("/", betaHandler, When(func(r *http.Request) bool {
+		_, err := r.Cookie("beta")
+		return err == nil
+	}))
+	router.Handle("/", homeHandler)
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	r.AddCookie(&http.Cookie{Name: "beta", Value: "1"})
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	assert.True(t, betaHandler.Used)
+	assert.False(t, homeHandler.Used)
+
+	r, _ = http.NewRequest("GET", "/", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	assert.True(t, homeHandler.Used)
+}
+
+func TestWhenMultipleConditions(t *testing.T) {
+	router := New()
+
+	handler := &recordingHandler{}
+	router.Handle("/", handler,
+		When(func(r *http.Request) bool { return r.RemoteAddr == "10.0.0.1:1234" }),
+		Header("X-Internal", "1"))
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	assert.Equal(t, 404, w.Code)
+
+	r.Header.Set("X-Internal", "1")
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	assert.True(t, handler.Used)
+}
+
+func TestContentType(t *testing.T) {
+	router := New()
+
+	jsonHandler := &recordingHandler{}
+	formHandler := &recordingHandler{}
+	router.Handle("POST /users", jsonHandler, ContentType("application/json"))
+	router.Handle("POST /users", formHandler, ContentType("application/x-www-form-urlencoded", "multipart/*"))
+
+	r, _ := http.NewRequest("POST", "/users", nil)
+	r.Header.Set("Content-Type", "application/json; charset=utf-8")
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	assert.True(t, jsonHandler.Used)
+	assert.False(t, formHandler.Used)
+
+	r, _ = http.NewRequest("POST", "/users", nil)
+	r.Header.Set("Content-Type", "multipart/form-data; boundary=xyz")
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	assert.True(t, formHandler.Used)
+
+	r, _ = http.NewRequest("POST", "/users", nil)
+	r.Header.Set("Content-Type", "text/plain")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	assert.Equal(t, 415, w.Code)
+
+	r, _ = http.NewRequest("PUT", "/users", nil)
+	r.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	assert.Equal(t, 405, w.Code)
+}
+
+func TestContentTypeWithFallback(t *testing.T) {
+	router := New()
+
+	jsonHandler := &recordingHandler{}
+	otherHandler := &recordingHandler{}
+	router.Handle("/upload", jsonHandler, ContentType("application/json"))
+	router.Handle("/upload", otherHandler)
+
+	r, _ := http.NewRequest("POST", "/upload", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	assert.False(t, jsonHandler.Used)
+	assert.True(t, otherHandler.Used)
+}
+
+func TestFormats(t *testing.T) {
+	router := New()
+
+	reportHandler := &recordingHandler{}
+	router.Handle("/reports/:id", reportHandler, Formats("json", "csv"))
+
+	cases := map[string]map[string]string{
+		"/reports/5":      {"id": "5"},
+		"/reports/5.json": {"id": "5", "format": "json"},
+		"/reports/5.csv":  {"id": "5", "format": "csv"},
+		"/reports/5.xml":  {"id": "5.xml"},
+	}
+
+	for path, vars := range cases {
+		r, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(httptest.NewRecorder(), r)
+		assert.Equal(t, vars, reportHandler.Vars)
+	}
+}
+
+func TestFormatsWithStaticPath(t *testing.T) {
+	router := New()
+
+	reportHandler := &recordingHandler{}
+	router.Handle("/report", reportHandler, Formats("json"))
+
+	r, _ := http.NewRequest("GET", "/report.json", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	assert.True(t, reportHandler.Used)
+	assert.Equal(t, map[string]string{"format": "json"}, reportHandler.Vars)
+
+	checkPanics(t, func() {
+		router.Handle("/files/*path", reportHandler, Formats("json"))
+	})
+}
+
+func TestPreload(t *testing.T) {
+	router := New()
+	pages := router.Group("/", Preload("/assets/app.css", "style"))
+	pages.HandleFunc("/dashboard", func(w http.ResponseWriter, r *http.Request) {},
+		Preload("/assets/dashboard.js", "script"),
+		Preload("/assets/inter.woff2", "font"))
+	router.HandleFunc("/plain", func(w http.ResponseWriter, r *http.Request) {})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/dashboard", nil))
+	assert.Equal(t, []string{
+		"</assets/app.css>; rel=preload; as=style",
+		"</assets/dashboard.js>; rel=preload; as=script",
+		"</assets/inter.woff2>; rel=preload; as=font; crossorigin",
+	}, w.Header()["Link"])
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/plain", nil))
+	assert.Empty(t, w.Header()["Link"])
+}
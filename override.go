@@ -0,0 +1,52 @@
+package route
+
+import (
+	"net/http"
+	"strings"
+)
+
+// MethodOverride returns a handler that lets POST requests use another method,
+// so that HTML forms can reach routes for PUT or DELETE. The method is taken
+// from the X-HTTP-Method-Override header or, failing that, the "_method" form
+// field, and is only used if it is one of methods. If no methods are given PUT,
+// PATCH and DELETE are allowed.
+//
+//   http.ListenAndServe(":8080", route.MethodOverride(router))
+//
+// Reading the form field parses the request body, so the form remains available
+// to handlers through r.PostForm.
+func MethodOverride(h http.Handler, methods ...string) http.Handler {
+	if len(methods) == 0 {
+		methods = []string{"PUT", "PATCH", "DELETE"}
+	}
+
+	allowed := map[string]bool{}
+	for _, method := range methods {
+		allowed[strings.ToUpper(method)] = true
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			method := r.Header.Get("X-HTTP-Method-Override")
+			if method == "" && isForm(r) {
+				method = r.PostFormValue("_method")
+			}
+
+			if method = strings.ToUpper(method); allowed[method] {
+				r2 := new(http.Request)
+				*r2 = *r
+				r2.Method = method
+				r = r2
+			}
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+func isForm(r *http.Request) bool {
+	contentType := r.Header.Get("Content-Type")
+
+	return strings.HasPrefix(contentType, "application/x-www-form-urlencoded") ||
+		strings.HasPrefix(contentType, "multipart/form-data")
+}
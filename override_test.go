@@ -0,0 +1,64 @@
+package route
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMethodOverride(t *testing.T) {
+	router := New()
+
+	deleteHandler := &recordingHandler{}
+	router.Handle("DELETE /posts/:id", deleteHandler)
+
+	handler := MethodOverride(router)
+
+	r, _ := http.NewRequest("POST", "/posts/5", strings.NewReader("_method=delete"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	assert.True(t, deleteHandler.Used)
+	assert.Equal(t, map[string]string{"id": "5"}, deleteHandler.Vars)
+}
+
+func TestMethodOverrideWithHeader(t *testing.T) {
+	router := New()
+
+	putHandler := &recordingHandler{}
+	router.Handle("PUT /posts/:id", putHandler)
+
+	handler := MethodOverride(router)
+
+	r, _ := http.NewRequest("POST", "/posts/5", nil)
+	r.Header.Set("X-HTTP-Method-Override", "PUT")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	assert.True(t, putHandler.Used)
+}
+
+func TestMethodOverrideNotAllowed(t *testing.T) {
+	router := New()
+	router.HandleFunc("/posts/:id", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Method))
+	})
+
+	handler := MethodOverride(router, "DELETE")
+
+	cases := []struct {
+		method, override, expected string
+	}{
+		{"POST", "DELETE", "DELETE"},
+		{"POST", "PUT", "POST"},
+		{"GET", "DELETE", "GET"},
+	}
+
+	for _, tc := range cases {
+		r, _ := http.NewRequest(tc.method, "/posts/5", nil)
+		r.Header.Set("X-HTTP-Method-Override", tc.override)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		assert.Equal(t, tc.expected, w.Body.String())
+	}
+}
@@ -0,0 +1,92 @@
+package route
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Param is a single parameter match.
+type Param struct {
+	Key   string
+	Value string
+}
+
+// Params is a list of parameter matches, in the order they appear in the path.
+// It is passed to handlers registered as a ParamsFunc or ParamsHandlerFunc, for
+// easier migration of handlers written for httprouter.
+type Params []Param
+
+// Get returns the value of the first parameter with the name, and whether there
+// was one.
+func (ps Params) Get(name string) (string, bool) {
+	for _, p := range ps {
+		if p.Key == name {
+			return p.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// Map returns the parameters as a map of name to value.
+func (ps Params) Map() map[string]string {
+	m := make(map[string]string, len(ps))
+	for _, p := range ps {
+		m[p.Key] = p.Value
+	}
+
+	return m
+}
+
+// unescape decodes the percent-encoded values of the parameters in place,
+// leaving any that are not validly encoded as they are.
+func (ps Params) unescape() {
+	for i, p := range ps {
+		if strings.IndexByte(p.Value, '%') < 0 {
+			continue
+		}
+		if value, err := url.PathUnescape(p.Value); err == nil {
+			ps[i].Value = value
+		}
+	}
+}
+
+// ByName returns the value of the first parameter with the name, or an empty
+// string if there is not one.
+func (ps Params) ByName(name string) string {
+	for _, p := range ps {
+		if p.Key == name {
+			return p.Value
+		}
+	}
+
+	return ""
+}
+
+// GetParams retrieves the parameter matches for the given request, in the order
+// they appear in the matched route. Unlike Vars no map is made, so this is the
+// cheaper way to read parameters. As with Vars the Params are reused for later
+// requests once the handler returns, so must not be kept or changed.
+func GetParams(r *http.Request) Params {
+	if m := getMatch(r); m != nil {
+		return m.params
+	}
+
+	return nil
+}
+
+// ParamsFunc is a handler function that is passed the parameter matches
+// explicitly, like those used with httprouter.
+type ParamsFunc func(w http.ResponseWriter, r *http.Request, ps Params)
+
+func (h ParamsFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h(w, r, GetParams(r))
+}
+
+// ParamsHandlerFunc is a ParamsFunc that can return an error.
+type ParamsHandlerFunc func(w http.ResponseWriter, r *http.Request, ps Params) error
+
+func (h ParamsHandlerFunc) ServeErrorHTTP(w http.ResponseWriter, r *http.Request) error {
+	return h(w, r, GetParams(r))
+}
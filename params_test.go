@@ -0,0 +1,88 @@
+package route
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParamsByName(t *testing.T) {
+	ps := Params{{"name", "gopher"}, {"id", "5"}}
+
+	assert.Equal(t, "gopher", ps.ByName("name"))
+	assert.Equal(t, "5", ps.ByName("id"))
+	assert.Equal(t, "", ps.ByName("missing"))
+}
+
+func TestParamsGet(t *testing.T) {
+	ps := Params{{"name", "gopher"}, {"empty", ""}}
+
+	value, ok := ps.Get("name")
+	assert.True(t, ok)
+	assert.Equal(t, "gopher", value)
+
+	value, ok = ps.Get("empty")
+	assert.True(t, ok)
+	assert.Equal(t, "", value)
+
+	_, ok = ps.Get("missing")
+	assert.False(t, ok)
+}
+
+func TestParamsMap(t *testing.T) {
+	ps := Params{{"name", "gopher"}, {"id", "5"}}
+
+	assert.Equal(t, map[string]string{"name": "gopher", "id": "5"}, ps.Map())
+	assert.Equal(t, map[string]string{}, Params(nil).Map())
+}
+
+func TestRouterWithParamsFunc(t *testing.T) {
+	var params Params
+
+	router := New()
+	router.HandleFunc("/user/:name/posts/:id/*rest", func(w http.ResponseWriter, r *http.Request, ps Params) {
+		params = append(Params{}, ps...)
+	})
+
+	r, _ := http.NewRequest("GET", "/user/gopher/posts/5/a/b", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, Params{{"name", "gopher"}, {"id", "5"}, {"rest", "a/b"}}, params)
+}
+
+func TestRouterWithParamsHandlerFunc(t *testing.T) {
+	expectedErr := errors.New("what")
+	var handledErr error
+
+	router := New()
+	router.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		handledErr = err
+	}
+	router.HandleFunc("/user/:name", func(w http.ResponseWriter, r *http.Request, ps Params) error {
+		assert.Equal(t, "gopher", ps.ByName("name"))
+		return expectedErr
+	})
+
+	r, _ := http.NewRequest("GET", "/user/gopher", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, expectedErr, handledErr)
+}
+
+func TestGetParamsWithoutMatch(t *testing.T) {
+	r, _ := http.NewRequest("GET", "/user/gopher", nil)
+
+	assert.Nil(t, GetParams(r))
+}
+
+func TestParamsUnescape(t *testing.T) {
+	ps := Params{{"name", "john%20doe"}, {"id", "5"}, {"bad", "100%"}}
+	ps.unescape()
+
+	assert.Equal(t, Params{{"name", "john doe"}, {"id", "5"}, {"bad", "100%"}}, ps)
+}
@@ -0,0 +1,98 @@
+package route
+
+import "strings"
+
+// parsePattern splits a pattern, as given to Handle, into its method, host and
+// path. It accepts both the router's own syntax and that of http.ServeMux, so
+// "GET example.com/users/{id}" becomes method "GET", host "example.com" and
+// path "/users/:id", and "/files/{path...}" becomes path "/files/*path". As
+// with http.ServeMux a path ending in '/', other than "/", matches every path
+// below it, so "/static/" becomes "/static/*rest".
+func parsePattern(pattern string) (method, host, path string) {
+	if i := strings.IndexAny(pattern, " \t"); i >= 0 {
+		method, pattern = pattern[:i], strings.TrimLeft(pattern[i:], " \t")
+	}
+
+	if i := strings.IndexByte(pattern, '/'); i > 0 {
+		host, pattern = strings.ToLower(pattern[:i]), pattern[i:]
+	}
+
+	if pattern == "" || pattern[0] != '/' {
+		panic("path must begin with '/'")
+	}
+
+	if len(pattern) > 1 && pattern[len(pattern)-1] == '/' {
+		pattern += "*rest"
+	}
+
+	if !strings.Contains(pattern, "{") {
+		return method, host, pattern
+	}
+
+	parts := strings.Split(pattern, "/")
+	for i, part := range parts {
+		if !strings.HasPrefix(part, "{") || !strings.HasSuffix(part, "}") {
+			continue
+		}
+
+		name := part[1 : len(part)-1]
+		switch {
+		case name == "$":
+			if i != len(parts)-1 {
+				panic("{$} must be at the end of the path")
+			}
+			parts = parts[:i]
+
+		case strings.HasSuffix(name, "..."):
+			parts[i] = "*" + strings.TrimSuffix(name, "...")
+
+		case isName(name):
+			parts[i] = ":" + name
+		}
+	}
+
+	path = strings.Join(parts, "/")
+	if path == "" {
+		path = "/"
+	}
+
+	return method, host, path
+}
+
+// greedyParam returns the name of the catch-all parameter that the path
+// fragment is, written either as "*name" or "{name...}", or false if it is not
+// one.
+func greedyParam(part string) (string, bool) {
+	if strings.HasPrefix(part, "*") {
+		return part[1:], true
+	}
+	if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "...}") {
+		return part[1 : len(part)-4], true
+	}
+
+	return "", false
+}
+
+func isName(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isNameByte(s[i]) {
+			return false
+		}
+	}
+
+	return s != ""
+}
+
+// withExtension returns the path with the last segment only matching when it
+// ends in the extension. It panics if the path ends in a catch-all parameter.
+func withExtension(path, extension string) string {
+	i := strings.LastIndexByte(path, '/')
+	if i == len(path)-1 {
+		panic("cannot add extension to path: " + path)
+	}
+	if path[i+1] == '*' {
+		panic("cannot add extension to greedy parameter: " + path)
+	}
+
+	return path + "." + extension
+}
@@ -0,0 +1,103 @@
+package route
+
+import (
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePattern(t *testing.T) {
+	cases := []struct {
+		pattern, method, host, path string
+	}{
+		{"/", "", "", "/"},
+		{"/user/:name", "", "", "/user/:name"},
+		{"GET /user/:name", "GET", "", "/user/:name"},
+		{"POST  /user", "POST", "", "/user"},
+		{"/user/{name}", "", "", "/user/:name"},
+		{"DELETE /user/{name}/posts/{id}", "DELETE", "", "/user/:name/posts/:id"},
+		{"/files/{path...}", "", "", "/files/*path"},
+		{"/{$}", "", "", "/"},
+		{"/user/{$}", "", "", "/user"},
+		{"/static/", "", "", "/static/*rest"},
+		{"GET /users/{id}/files/", "GET", "", "/users/:id/files/*rest"},
+		{"/reports/:period{daily,weekly}", "", "", "/reports/:period{daily,weekly}"},
+		{"api.example.com/user", "", "api.example.com", "/user"},
+		{"GET API.example.com:8080/user/{name}", "GET", "api.example.com:8080", "/user/:name"},
+	}
+
+	for _, tc := range cases {
+		method, host, path := parsePattern(tc.pattern)
+
+		assert.Equal(t, tc.method, method)
+		assert.Equal(t, tc.host, host)
+		assert.Equal(t, tc.path, path)
+	}
+}
+
+func TestParsePatternInvalid(t *testing.T) {
+	patterns := []string{
+		"",
+		"user",
+		"GET user",
+		"example.com",
+		"/{$}/user",
+	}
+
+	for _, pattern := range patterns {
+		checkPanics(t, func() {
+			parsePattern(pattern)
+		})
+	}
+}
+
+func TestRouterServeMuxSubtreePattern(t *testing.T) {
+	var vars map[string]string
+
+	router := New()
+	router.HandleFunc("/static/", func(w http.ResponseWriter, r *http.Request) {
+		vars = maps.Clone(Vars(r))
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/static/css/site.css", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, map[string]string{"rest": "css/site.css"}, vars)
+}
+
+func TestRouterFallsBackToRouteAcceptingRequest(t *testing.T) {
+	var matched string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		matched = Pattern(r)
+	}
+
+	router := New()
+	router.HandleFunc("GET /users/new", handler)
+	router.HandleFunc("POST /users/{id}", handler)
+	router.HandleFunc("/search", handler, Query("format", "rss"))
+	router.HandleFunc("/:page", handler)
+
+	testCases := []struct {
+		method, path, pattern string
+		code                  int
+	}{
+		{"GET", "/users/new", "/users/new", http.StatusOK},
+		{"POST", "/users/new", "/users/:id", http.StatusOK},
+		{"DELETE", "/users/new", "", http.StatusMethodNotAllowed},
+		{"GET", "/search?format=rss", "/search", http.StatusOK},
+		{"GET", "/search", "/:page", http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		matched = ""
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, nil))
+
+		assert.Equal(t, tc.code, rec.Code, tc.method+" "+tc.path)
+		assert.Equal(t, tc.pattern, matched, tc.method+" "+tc.path)
+	}
+}
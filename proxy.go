@@ -0,0 +1,74 @@
+package route
+
+import (
+	"net/http/httputil"
+	"net/url"
+	"strings"
+)
+
+// Proxy registers a reverse proxy to the target for requests to the path, which
+// must end in a catch-all parameter. The value of the parameter replaces the
+// matched prefix of the path, and is joined to the path of the target:
+//
+//   backend, _ := url.Parse("http://users.internal:8080/v1")
+//   router.Proxy("/api/users/*path", backend)
+//
+//   /api/users/5?full=1         proxied to http://users.internal:8080/v1/5?full=1
+//
+// The request is proxied as by httputil.ReverseProxy, with X-Forwarded headers
+// set. Use NewProxy to change how the proxy behaves.
+func (r *Router) Proxy(path string, target *url.URL, opts ...Option) {
+	r.Group("").Proxy(path, target, opts...)
+}
+
+// Proxy registers a reverse proxy to the target for requests to the path,
+// relative to the Group, as Router.Proxy does.
+func (g *Group) Proxy(path string, target *url.URL, opts ...Option) {
+	g.Handle(path, NewProxy(target, proxyParam(path)), opts...)
+}
+
+// proxyParam returns the name of the catch-all parameter the pattern ends in.
+// It panics if the pattern does not end in one.
+func proxyParam(pattern string) string {
+	_, _, p := parsePattern(pattern)
+
+	i := strings.LastIndex(p, "/*")
+	if i < 0 {
+		panic("route: Proxy requires a path ending in a catch-all parameter: " + pattern)
+	}
+
+	return p[i+2:]
+}
+
+// NewProxy returns a reverse proxy to the target for a route with the named
+// parameter, as registered by Router.Proxy. The value of the parameter is
+// joined to the path of the target to give the path of proxied requests.
+//
+//   proxy := route.NewProxy(backend, "path")
+//   proxy.ErrorHandler = backendErrorHandler
+//   router.Handle("/api/*path", proxy)
+func NewProxy(target *url.URL, param string) *httputil.ReverseProxy {
+	return &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(target)
+			pr.SetXForwarded()
+
+			value, _ := GetParams(pr.In).Get(param)
+
+			raw, decoded := value, value
+			if m := getMatch(pr.In); m != nil && m.decoded {
+				raw = escapeGreedy(value)
+			} else if unescaped, err := url.PathUnescape(value); err == nil {
+				decoded = unescaped
+			}
+
+			pr.Out.URL.Path = joinPath(target.Path, decoded)
+			pr.Out.URL.RawPath = joinPath(target.EscapedPath(), raw)
+		},
+	}
+}
+
+// joinPath joins the path to the base with a single slash.
+func joinPath(base, path string) string {
+	return strings.TrimSuffix(base, "/") + "/" + strings.TrimPrefix(path, "/")
+}
@@ -0,0 +1,72 @@
+package route
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouterProxy(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Method", r.Method)
+		w.Header().Set("X-Forwarded-Host", r.Header.Get("X-Forwarded-Host"))
+		io.WriteString(w, r.URL.RequestURI())
+	}))
+	defer backend.Close()
+
+	target, _ := url.Parse(backend.URL + "/v1/")
+
+	router := New()
+	router.Proxy("/api/users/*path", target)
+	router.Group("/v2").Proxy("/things/{rest...}", target, Methods("GET"))
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve("GET", "/api/users/5?full=1")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "/v1/5?full=1", w.Body.String())
+	assert.Equal(t, "example.com", w.Header().Get("X-Forwarded-Host"))
+
+	w = serve("DELETE", "/api/users/a%2Fb/c%20d")
+	assert.Equal(t, "DELETE", w.Header().Get("X-Method"))
+	assert.Equal(t, "/v1/a%2Fb/c%20d", w.Body.String())
+
+	w = serve("GET", "/api/users")
+	assert.Equal(t, "/v1/", w.Body.String())
+
+	w = serve("GET", "/v2/things/x")
+	assert.Equal(t, "/v1/x", w.Body.String())
+	assert.Equal(t, 405, serve("POST", "/v2/things/x").Code)
+
+	assert.Panics(t, func() {
+		router.Proxy("/other", target)
+	})
+}
+
+func TestNewProxyDecoded(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.URL.EscapedPath())
+	}))
+	defer backend.Close()
+
+	target, _ := url.Parse(backend.URL)
+
+	router := New()
+	router.UnescapeVars = true
+	router.Handle("/api/*path", NewProxy(target, "path"))
+
+	r := httptest.NewRequest("GET", "/api/c%20d/e%3Ff", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, "/c%20d/e%3Ff", w.Body.String())
+}
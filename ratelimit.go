@@ -0,0 +1,167 @@
+package route
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// rateLimitKey is the metadata key of the Limit given by RateLimit.
+const rateLimitKey = "ratelimit"
+
+// A Limit allows a number of requests per period, refilled continuously, with
+// bursts of up to Burst requests. If Burst is zero it is taken to be Requests.
+type Limit struct {
+	Requests int
+	Per      time.Duration
+	Burst    int
+}
+
+// rate returns the number of requests allowed per second.
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Per.Seconds()
+}
+
+func (l Limit) burst() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return float64(l.Requests)
+}
+
+// RateLimit sets the Limit applied to the route by a RateLimiter, replacing its
+// Default. It is stored as metadata, so is listed by Routes.
+func RateLimit(limit Limit) Option {
+	return Tag(rateLimitKey, limit)
+}
+
+// A RateLimiter limits the rate of requests each client can make to each route,
+// using a token bucket for each pair of matched pattern and client. Requests
+// over the limit are sent a 429 Too Many Requests response with a Retry-After
+// header. It is added to a Router with Before:
+//
+//   limiter := &route.RateLimiter{Default: route.Limit{Requests: 60, Per: time.Minute}}
+//   router.Before(limiter.Before)
+//
+//   router.Handle("POST /login", loginHandler, route.RateLimit(route.Limit{Requests: 5, Per: time.Minute}))
+//
+// As requests are keyed by pattern a client requesting "/users/1" then
+// "/users/2" uses the same bucket.
+type RateLimiter struct {
+	// Default is the limit for routes without the RateLimit option. If it is
+	// zero those routes are not limited.
+	Default Limit
+
+	// Key returns the identity of the client making the request, such as its IP
+	// address or API key. Requests for which it returns an empty string are not
+	// limited. By default the IP address of the remote end of the connection is
+	// used.
+	Key func(r *http.Request) string
+
+	mu      sync.Mutex
+	buckets map[rateBucketKey]*tokenBucket
+	calls   int
+}
+
+type rateBucketKey struct {
+	pattern, client string
+}
+
+// tokenBucket holds the tokens available to a client at a time.
+type tokenBucket struct {
+	limit  Limit
+	tokens float64
+	at     time.Time
+}
+
+// take refills the bucket up to the time now then takes a token, if there is
+// not one it returns the time until there will be.
+func (b *tokenBucket) take(now time.Time) (bool, time.Duration) {
+	b.tokens = b.available(now)
+	b.at = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	return false, time.Duration((1 - b.tokens) / b.limit.rate() * float64(time.Second))
+}
+
+// available returns the number of tokens in the bucket at the time now.
+func (b *tokenBucket) available(now time.Time) float64 {
+	return math.Min(b.limit.burst(), b.tokens+now.Sub(b.at).Seconds()*b.limit.rate())
+}
+
+// sweepEvery is the number of requests between removing buckets that are full,
+// and so would be the same if made again.
+const sweepEvery = 1024
+
+// Before takes a token for the request, if there are none it sends a 429
+// response and returns false.
+func (l *RateLimiter) Before(w http.ResponseWriter, r *http.Request) bool {
+	limit, ok := MetaValue[Limit](r, rateLimitKey)
+	if !ok {
+		limit = l.Default
+	}
+	if limit.Requests <= 0 || limit.Per <= 0 {
+		return true
+	}
+
+	key := l.Key
+	if key == nil {
+		key = remoteIP
+	}
+	client := key(r)
+	if client == "" {
+		return true
+	}
+
+	allowed, wait := l.take(rateBucketKey{pattern: Pattern(r), client: client}, limit, time.Now())
+	if !allowed {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+	}
+
+	return allowed
+}
+
+func (l *RateLimiter) take(key rateBucketKey, limit Limit, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.buckets == nil {
+		l.buckets = map[rateBucketKey]*tokenBucket{}
+	}
+
+	l.calls++
+	if l.calls%sweepEvery == 0 {
+		for k, b := range l.buckets {
+			if b.available(now) >= b.limit.burst() {
+				delete(l.buckets, k)
+			}
+		}
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: limit.burst(), at: now}
+		l.buckets[key] = b
+	}
+	b.limit = limit
+
+	return b.take(now)
+}
+
+// remoteIP returns the IP address of the remote end of the connection the
+// request was received on.
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
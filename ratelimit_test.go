@@ -0,0 +1,91 @@
+package route
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	limiter := &RateLimiter{Default: Limit{Requests: 2, Per: time.Minute}}
+
+	router := New()
+	router.Before(limiter.Before)
+	router.HandleFunc("/users/:id", func(w http.ResponseWriter, r *http.Request) {})
+	router.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {},
+		RateLimit(Limit{Requests: 1, Per: time.Hour}))
+	router.HandleFunc("/about", func(w http.ResponseWriter, r *http.Request) {})
+
+	request := func(path, addr string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", path, nil)
+		r.RemoteAddr = addr
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	assert.Equal(t, 200, request("/users/1", "10.0.0.1:1000").Code)
+	assert.Equal(t, 200, request("/users/2", "10.0.0.1:1001").Code)
+
+	w := request("/users/3", "10.0.0.1:1002")
+	assert.Equal(t, 429, w.Code)
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+
+	assert.Equal(t, 200, request("/users/3", "10.0.0.2:1000").Code)
+	assert.Equal(t, 200, request("/about", "10.0.0.1:1000").Code)
+
+	assert.Equal(t, 200, request("/login", "10.0.0.1:1000").Code)
+	w = request("/login", "10.0.0.1:1000")
+	assert.Equal(t, 429, w.Code)
+	assert.Equal(t, "3600", w.Header().Get("Retry-After"))
+}
+
+func TestRateLimiterKey(t *testing.T) {
+	limiter := &RateLimiter{
+		Default: Limit{Requests: 1, Per: time.Minute},
+		Key: func(r *http.Request) string {
+			return r.Header.Get("X-API-Key")
+		},
+	}
+
+	router := New()
+	router.Before(limiter.Before)
+	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {})
+
+	request := func(key string) int {
+		r := httptest.NewRequest("GET", "/", nil)
+		if key != "" {
+			r.Header.Set("X-API-Key", key)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	assert.Equal(t, 200, request("a"))
+	assert.Equal(t, 429, request("a"))
+	assert.Equal(t, 200, request("b"))
+	assert.Equal(t, 200, request(""))
+	assert.Equal(t, 200, request(""))
+}
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Now()
+	b := &tokenBucket{limit: Limit{Requests: 10, Per: time.Second, Burst: 2}, tokens: 2, at: now}
+
+	ok, _ := b.take(now)
+	assert.True(t, ok)
+	ok, _ = b.take(now)
+	assert.True(t, ok)
+	ok, wait := b.take(now)
+	assert.False(t, ok)
+	assert.Equal(t, 100*time.Millisecond, wait)
+
+	ok, _ = b.take(now.Add(100 * time.Millisecond))
+	assert.True(t, ok)
+
+	assert.Equal(t, 2.0, b.available(now.Add(time.Hour)))
+}
@@ -0,0 +1,117 @@
+package route
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Redirect registers a redirect from the path to the target with the status
+// code, which must be a 3xx code. Parameters in the target are replaced with
+// the values they matched in the path, and the query of the request is kept:
+//
+//   router.Redirect("/old/:id", "/new/:id", http.StatusMovedPermanently)
+//   router.Redirect("/blog/*path", "https://blog.example.com/*path", http.StatusFound)
+//
+//   /old/5?page=2               redirected to /new/5?page=2
+//   /blog/2024/hello            redirected to https://blog.example.com/2024/hello
+//
+// Redirect panics if the target is not a valid URL, or uses a parameter that
+// the path does not have.
+func (r *Router) Redirect(path, target string, code int, opts ...Option) {
+	r.Group("").Redirect(path, target, code, opts...)
+}
+
+// Redirect registers a redirect from the path, relative to the Group, to the
+// target as Router.Redirect does. The target is not relative to the Group.
+func (g *Group) Redirect(path, target string, code int, opts ...Option) {
+	g.Handle(path, newRedirectHandler(g.pattern(path), target, code), opts...)
+}
+
+// redirectHandler redirects requests to a URL built from the parameters of the
+// route.
+type redirectHandler struct {
+	target *url.URL
+	code   int
+
+	// greedy is the catch-all parameter of the path that the target ends in, if
+	// any, whose value is kept escaped as it was in the request.
+	greedy string
+}
+
+// newRedirectHandler returns a handler redirecting requests for the pattern to
+// the target. It panics if the code or target are invalid.
+func newRedirectHandler(pattern, target string, code int) *redirectHandler {
+	if code < 300 || code > 399 {
+		panic("route: Redirect requires a 3xx status code, got " + strconv.Itoa(code))
+	}
+
+	u, err := url.Parse(target)
+	if err != nil {
+		panic("route: invalid redirect target: " + err.Error())
+	}
+
+	_, _, p := parsePattern(pattern)
+	params := map[string]bool{}
+	for _, param := range patternParams(p) {
+		params[param] = true
+	}
+	for _, param := range patternParams(u.Path) {
+		if !params[param] {
+			panic("route: redirect target uses parameter not in path: " + param)
+		}
+	}
+
+	h := &redirectHandler{target: u, code: code}
+	if name, ok := greedyParam(p[strings.LastIndexByte(p, '/')+1:]); ok {
+		if last, _ := greedyParam(u.Path[strings.LastIndexByte(u.Path, '/')+1:]); last == name {
+			h.greedy = name
+		}
+	}
+
+	return h
+}
+
+func (h *redirectHandler) ServeErrorHTTP(w http.ResponseWriter, r *http.Request) error {
+	ps := GetParams(r)
+
+	vars := make(map[string]string, len(ps))
+	decoded := false
+	if m := getMatch(r); m != nil {
+		decoded = m.decoded
+	}
+	for _, p := range ps {
+		value := p.Value
+		if !decoded && p.Key != h.greedy {
+			if unescaped, err := url.PathUnescape(value); err == nil {
+				value = unescaped
+			}
+		}
+		vars[p.Key] = value
+	}
+
+	u := *h.target
+	if u.Path != "" {
+		built, err := build(h.target.Path, vars, nil, nil, !decoded && h.greedy != "")
+		if err != nil {
+			return err
+		}
+		u.Path, u.RawPath = built.Path, built.RawPath
+
+		// a path beginning "//" or "/\" is taken by browsers as naming another
+		// host, so must not be built from the request
+		if escaped := u.EscapedPath(); u.Host == "" && (strings.HasPrefix(escaped, "//") || strings.HasPrefix(escaped, "/\\")) {
+			u.Path, u.RawPath = "/"+strings.TrimLeft(u.Path, "/\\"), ""
+		}
+	}
+	if u.RawQuery == "" {
+		u.RawQuery = r.URL.RawQuery
+	} else if r.URL.RawQuery != "" {
+		u.RawQuery += "&" + r.URL.RawQuery
+	}
+
+	http.Redirect(w, r, u.String(), h.code)
+	return nil
+}
+
@@ -0,0 +1,79 @@
+package route
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouterRedirect(t *testing.T) {
+	router := New()
+	router.Redirect("/old/:id", "/new/:id", http.StatusMovedPermanently)
+	router.Redirect("/blog/*path", "https://blog.example.com/posts/*path?from=site", http.StatusFound)
+	router.Redirect("GET /about", "/company/about", http.StatusPermanentRedirect)
+	router.Group("/v1").Redirect("/users/:name/posts/:id", "/v2/posts/:id?author=1", http.StatusMovedPermanently)
+
+	cases := []struct {
+		method, path string
+		code         int
+		location     string
+	}{
+		{"GET", "/old/5?page=2", 301, "/new/5?page=2"},
+		{"POST", "/old/a%20b", 301, "/new/a%20b"},
+		{"GET", "/blog/2024/hello", 302, "https://blog.example.com/posts/2024/hello?from=site"},
+		{"GET", "/blog/a?x=1", 302, "https://blog.example.com/posts/a?from=site&x=1"},
+		{"GET", "/about", 308, "/company/about"},
+		{"POST", "/about", 405, ""},
+		{"GET", "/v1/users/john/posts/3", 301, "/v2/posts/3?author=1"},
+	}
+
+	for _, tc := range cases {
+		r := httptest.NewRequest(tc.method, tc.path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+
+		assert.Equal(t, tc.code, w.Code, tc.path)
+		assert.Equal(t, tc.location, w.Header().Get("Location"), tc.path)
+	}
+}
+
+func TestRouterRedirectEncodedSlash(t *testing.T) {
+	for _, decode := range []bool{false, true} {
+		router := New()
+		router.DecodePath = decode
+		router.Redirect("/go/*path", "/*path", http.StatusFound)
+
+		for _, path := range []string{"/go/%2Fevil.com", "/go/%2F%2Fevil.com", "/go/%5Cevil.com", "/go/%2F%5Cevil.com"} {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+
+			location := w.Header().Get("Location")
+			assert.False(t, strings.HasPrefix(location, "//"), path+" redirected to "+location)
+			assert.False(t, strings.HasPrefix(location, "/\\"), path+" redirected to "+location)
+		}
+	}
+
+	router := New()
+	router.Redirect("/go/*path", "/*path", http.StatusFound)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/go/%2Fevil.com", nil))
+	assert.Equal(t, "/%2Fevil.com", w.Header().Get("Location"))
+}
+
+func TestRouterRedirectInvalid(t *testing.T) {
+	router := New()
+
+	assert.Panics(t, func() {
+		router.Redirect("/a", "/b", http.StatusOK)
+	})
+	assert.Panics(t, func() {
+		router.Redirect("/old/:id", "/new/:name", http.StatusFound)
+	})
+	assert.Panics(t, func() {
+		router.Redirect("/old/:id", "http://%zz/:id", http.StatusFound)
+	})
+}
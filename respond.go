@@ -0,0 +1,152 @@
+package route
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// templates holds the templates registered with RegisterTemplate, by the type
+// of value they render.
+var templates = struct {
+	sync.RWMutex
+	m map[reflect.Type]*template.Template
+}{m: map[reflect.Type]*template.Template{}}
+
+// RegisterTemplate registers the template Respond uses to render values of type
+// T as HTML. It replaces any template previously registered for T.
+//
+//   route.RegisterTemplate[User](template.Must(template.ParseFiles("user.html")))
+func RegisterTemplate[T any](tmpl *template.Template) {
+	templates.Lock()
+	defer templates.Unlock()
+
+	templates.m[reflect.TypeFor[T]()] = tmpl
+}
+
+// Respond writes value as the response with the status code, encoded in the
+// format the request accepts best. Values are written as JSON, as HTML if a
+// template has been registered for their type with RegisterTemplate, or as
+// plain text formatted by fmt.Print. When the Accept header of the request
+// prefers no format, or is missing, JSON is used.
+//
+//   func showUser(w http.ResponseWriter, r *http.Request) error {
+//     user, err := users.Find(route.Vars(r)["name"])
+//     if err != nil {
+//       return err
+//     }
+//
+//     return route.Respond(w, r, http.StatusOK, user)
+//   }
+//
+// If the request accepts none of the formats nothing is written, and an
+// HTTPError responding with 406 Not Acceptable is returned.
+func Respond(w http.ResponseWriter, r *http.Request, code int, value any) error {
+	offers := []string{"application/json", "text/plain"}
+
+	templates.RLock()
+	tmpl, ok := templates.m[reflect.TypeOf(value)]
+	templates.RUnlock()
+	if ok {
+		offers = []string{"application/json", "text/html", "text/plain"}
+	}
+
+	switch negotiate(r.Header.Get("Accept"), offers) {
+	case "application/json":
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		return json.NewEncoder(w).Encode(value)
+
+	case "text/html":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(code)
+		return tmpl.Execute(w, value)
+
+	case "text/plain":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(code)
+		_, err := fmt.Fprint(w, value)
+		return err
+	}
+
+	return Error(http.StatusNotAcceptable, "")
+}
+
+// negotiate returns the offered media type the Accept header gives the highest
+// quality, preferring earlier offers when equal, or an empty string if none are
+// acceptable. More specific media ranges in the header take precedence, so
+// "text/*;q=0.5, text/plain" accepts "text/plain" with quality 1.
+func negotiate(accept string, offers []string) string {
+	if accept == "" {
+		return offers[0]
+	}
+
+	ranges := parseAccept(accept)
+
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		q, specificity := 0.0, -1
+		for _, mr := range ranges {
+			if s := mediaRangeSpecificity(mr.mediatype, offer); s > specificity {
+				specificity, q = s, mr.q
+			}
+		}
+
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+
+	return best
+}
+
+// mediaRange is a media range listed in an Accept header, with its quality.
+type mediaRange struct {
+	mediatype string
+	q         float64
+}
+
+// parseAccept returns the media ranges listed in the Accept header, in order,
+// leaving out any that are invalid. A range without a valid quality has quality
+// 1.
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mediatype, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		ranges = append(ranges, mediaRange{mediatype, q})
+	}
+
+	return ranges
+}
+
+// mediaRangeSpecificity returns how specifically the media range matches the
+// media type: 2 for an exact match, 1 for "type/*", 0 for "*/*", and -1 if it
+// does not match.
+func mediaRangeSpecificity(mediarange, mediatype string) int {
+	switch {
+	case mediarange == mediatype:
+		return 2
+	case mediarange == "*/*":
+		return 0
+	case strings.HasSuffix(mediarange, "/*") && strings.HasPrefix(mediatype, mediarange[:len(mediarange)-1]):
+		return 1
+	}
+
+	return -1
+}
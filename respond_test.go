@@ -0,0 +1,94 @@
+package route
+
+import (
+	"html/template"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type greeting struct {
+	Name string `json:"name"`
+}
+
+func (g greeting) String() string {
+	return "Hello, " + g.Name
+}
+
+func TestNegotiate(t *testing.T) {
+	offers := []string{"application/json", "text/html", "text/plain"}
+
+	cases := map[string]string{
+		"":    "application/json",
+		"*/*": "application/json",
+		"text/html,application/xhtml+xml,*/*;q=0.8": "text/html",
+		"text/plain, application/json;q=0.5":        "text/plain",
+		"text/*, text/html;q=0.1":                   "text/plain",
+		"application/json;q=0, */*":                 "text/html",
+		"image/png":                                 "",
+		"text/plain;q=0":                            "",
+	}
+
+	for accept, expected := range cases {
+		assert.Equal(t, expected, negotiate(accept, offers), accept)
+	}
+}
+
+func TestAcceptParsedConsistently(t *testing.T) {
+	cases := []struct {
+		accept                   string
+		acceptsJSON, prefersJSON bool
+	}{
+		{"", true, false},
+		{"*/*", true, false},
+		{"application/json", true, true},
+		{"application/json;q=0.0", false, false},
+		{"text/html;q=0.5, application/json", true, true},
+		{"text/html, application/json", true, false},
+		{"application/*;q=0.1, text/html;q=0.2", true, false},
+		{"text/html", false, false},
+	}
+
+	for _, tc := range cases {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Accept", tc.accept)
+
+		assert.Equal(t, tc.acceptsJSON, acceptsJSON(r), tc.accept)
+		assert.Equal(t, tc.prefersJSON, prefersJSON(r), tc.accept)
+	}
+}
+
+func TestRespond(t *testing.T) {
+	respond := func(accept string, value any) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", "/", nil)
+		if accept != "" {
+			r.Header.Set("Accept", accept)
+		}
+
+		w := httptest.NewRecorder()
+		if err := Respond(w, r, 201, value); err != nil {
+			w.Code = err.(*HTTPError).Code
+		}
+		return w
+	}
+
+	w := respond("", greeting{"John"})
+	assert.Equal(t, 201, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"name": "John"}`, w.Body.String())
+
+	w = respond("text/plain", greeting{"John"})
+	assert.Equal(t, 201, w.Code)
+	assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "Hello, John", w.Body.String())
+
+	assert.Equal(t, 406, respond("text/html", greeting{"John"}).Code)
+
+	RegisterTemplate[greeting](template.Must(template.New("").Parse(`<h1>Hello, {{.Name}}</h1>`)))
+
+	w = respond("text/html", greeting{"<John>"})
+	assert.Equal(t, 201, w.Code)
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "<h1>Hello, &lt;John&gt;</h1>", w.Body.String())
+}
@@ -14,10 +14,44 @@
 //
 //  Requests:
 //   /blog/go/request-routers            match: category="go", post="request-routers"
-//   /blog/go/request-routers/           redirect to /blog/go/request-routers
+//   /blog/go/request-routers/           match: category="go", post="request-routers"
 //   /blog/go/                           no match
 //   /blog/go/request-routers/comments   no match
 //
+// A named parameter may be constrained by a regular expression, given in
+// parentheses after the name, which must match the whole path segment. If it
+// does not match the route is skipped, so other routes can be tried:
+//
+//  Path: /posts/:id([0-9]+)
+//
+//  Requests:
+//   /posts/123                          match: id="123"
+//   /posts/hello-world                  no match
+//
+// A fixed set of allowed values can be listed in braces instead:
+//
+//  Path: /reports/:period{daily,weekly,monthly}
+//
+//  Requests:
+//   /reports/weekly                     match: period="weekly"
+//   /reports/yearly                     no match
+//
+// Other constraints can be written as a MatcherFunc and registered with the
+// router, see Router.Matcher.
+//
+// A named parameter can also take up only part of a segment, in which case the
+// parameter name ends at the first character that is not a letter, digit or
+// underscore. The rest of the segment must then match exactly. Part way through
+// a segment the ':' must follow one of the characters ". - ~ @ + ,", otherwise
+// it is matched as is, so "/v1/things:batchGet" has no parameters. To begin a
+// parameter after other text write its name in braces instead:
+//
+//  Path: /v{version}/files/:name.json
+//
+//  Requests:
+//   /v2/files/report.json               match: version="2", name="report"
+//   /v2/files/report.csv                no match
+//
 // Catch-all
 //
 // Catch-all parameters match anything until the path end. Since they match
@@ -32,6 +66,20 @@
 //   /files/templates/article.html       match: filepath="templates/article.html"
 //   /files                              match: filepath=""
 //
+// Routes can be restricted to a method by beginning the path with it, as in
+// "GET /blog/:category/:post", or by passing the Methods option. As with
+// http.ServeMux, when the routes for the most specific path matching a request
+// do not accept its method or conditions, less specific paths are tried, so
+// with "GET /users/new" and "POST /users/:id" a POST to /users/new is handled
+// by the second. Only when no path has a route for the request is the
+// MethodNotAllowedHandler used, for the most specific. The wildcard syntax of
+// http.ServeMux, such as "/user/{name}" and "/files/{path...}", is also
+// accepted.
+//
+// Routes can also be restricted to a host by beginning the path with it, as in
+// "api.example.com/users", or by registering them with a Group from
+// Router.Host. These are tried before routes registered for any host.
+//
 // The value of parameters is saved as a map[string]string against the
 // request. To retrieve the parameters for a request use the Vars function:
 //
@@ -41,8 +89,17 @@ package route
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"net/url"
+	"reflect"
+	"runtime"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type Handler interface {
@@ -61,6 +118,14 @@ func (h HandlerFunc) ServeErrorHTTP(w http.ResponseWriter, r *http.Request) erro
 	return h(w, r)
 }
 
+// ContextHandlerFunc is a HandlerFunc that is passed the context of the request
+// explicitly, for codebases where functions take a context first.
+type ContextHandlerFunc func(ctx context.Context, w http.ResponseWriter, r *http.Request) error
+
+func (h ContextHandlerFunc) ServeErrorHTTP(w http.ResponseWriter, r *http.Request) error {
+	return h(r.Context(), w, r)
+}
+
 type nilErrorHandler struct {
 	http.Handler
 }
@@ -74,28 +139,158 @@ func (h nilErrorHandler) ServeErrorHTTP(w http.ResponseWriter, r *http.Request)
 // handler functions via configurable routes
 type Router struct {
 	// NotFoundHandler is called when no matching route is found. By default it is
-	// set to http.NotFoundHandler().
+	// set to http.NotFoundHandler(). Use Chain to pass such requests on to
+	// another Router or handler instead.
 	NotFoundHandler http.Handler
 
-	// ErrorHandler is called if an error is raised by any handler.
+	// MethodNotAllowedHandler is called when a route matches the path but not
+	// the method of the request. The Allow header is set before it is called. By
+	// default it responds with 405 Method Not Allowed.
+	MethodNotAllowedHandler http.Handler
+
+	// ErrorHandler is called if an error is raised by any handler. By default it
+	// responds with the status code of errors implementing StatusCoder, and the
+	// message of an HTTPError, or otherwise logs the error to ErrorLog and
+	// responds with 500 Internal Server Error. To ignore errors instead set it to
+	// IgnoreErrors.
 	ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
 
-	mu   sync.RWMutex
-	tree *treeLookup
+	// RouteErrorHandler, if set, is called instead of ErrorHandler when an error
+	// is raised by a handler. It is also passed details of the route that
+	// matched, so that errors can be logged or counted by route.
+	RouteErrorHandler func(w http.ResponseWriter, r *http.Request, err error, m RouteMatch)
+
+	// ErrorLog is used by the default ErrorHandler to log errors. If nil, errors
+	// are logged using the log package's standard logger.
+	ErrorLog *log.Logger
+
+	// SkipClean stops requests for paths containing "//", "/./" or "/../"
+	// elements being redirected to the clean path, so that they are matched
+	// against the routes as received. This is useful when the path is part of a
+	// signature that rewriting it would break.
+	SkipClean bool
+
+	// DecodePath matches routes against the decoded path of requests, rather
+	// than the path as sent, so that parameters have decoded values. Routes
+	// must then be registered with decoded paths too, and an encoded '/' in a
+	// request separates path segments as any other '/' does.
+	DecodePath bool
+
+	// UnescapeVars decodes the values of parameters before they are given to
+	// handlers, so that for the route "/users/:name" a request for
+	// "/users/john%20doe" has the name "john doe". Constraints are still checked
+	// against the values as they are in the path. It has no effect when
+	// DecodePath is set, as the values are decoded already.
+	UnescapeVars bool
+
+	// RedirectStatus is the status code used to redirect requests to the clean
+	// path, or for RedirectSlash to the path without the trailing slash. By
+	// default, or if zero, GET and HEAD requests are redirected with 301 Moved
+	// Permanently and others with 308 Permanent Redirect, so that their method
+	// and body are kept.
+	RedirectStatus int
+
+	// RedirectFixedPath, when set, redirects requests for a path that no route
+	// matches to the path of a route that matches it ignoring case, if there
+	// is exactly one. So that a request for "/About" is redirected to "/about".
+	RedirectFixedPath bool
+
+	// TrailingSlash sets how requests for the path of a route with a trailing
+	// slash are handled, for routes not given the TrailingSlash option. By
+	// default, or if zero, they are matched as MatchSlash.
+	TrailingSlash SlashPolicy
+
+	// Tracer, if set, is used to start a span for each request, see Tracer.
+	Tracer Tracer
+
+	// RecordStats, when set, records the number of requests handled by each
+	// route, the errors returned and the time taken, see Stats.
+	RecordStats bool
+
+	// SuggestRoutes, when set, finds the routes similar to requests that no
+	// route matches, for the NotFoundHandler to suggest, see Suggestions.
+	SuggestRoutes bool
+
+	// CanonicalHost sets the form of the host requests must be made for, those
+	// for other forms are redirected to it. By default, or if zero, requests
+	// are accepted for the host as given.
+	CanonicalHost HostPolicy
+
+	// MaxPathLength and MaxPathSegments, when not zero, limit the length in
+	// bytes and the number of segments of the paths of requests. Requests for
+	// longer paths are responded to with 414 URI Too Long before being routed.
+	MaxPathLength   int
+	MaxPathSegments int
+
+	mu        sync.RWMutex
+	tree      *treeLookup
+	hosts     map[string]*treeLookup
+	notFounds map[string]*treeLookup
+	endpoints map[string]*endpoint
+	names     map[string]*endpoint
+	mappers   []ErrorMapper
+	before    []BeforeRequestFunc
+	after     []AfterFunc
+
+	// snap is the snapshot of the routes that requests are routed with, or nil
+	// if it needs to be made again.
+	snap atomic.Pointer[snapshot]
+
+	// active is the number of requests being served, and idle is signalled
+	// when it falls to zero once Drain has been called.
+	active atomic.Int64
+	idle   atomic.Pointer[chan struct{}]
+
+	// frozen is set for routers returned by Freeze, which can't be changed.
+	frozen bool
 }
 
 // Default is the router instance used by the Handle and HandleFunc functions.
 var Default = New()
 
 // Handle registers the handler for the given path to the Default router.
-func Handle(path string, handler interface{}) {
-	Default.Handle(path, handler)
+func Handle(path string, handler interface{}, opts ...Option) {
+	Default.Handle(path, handler, opts...)
 }
 
 // HandleFunc registers the handler function for the given path to the Default
 // router.
-func HandleFunc(path string, handler interface{}) {
-	Default.HandleFunc(path, handler)
+func HandleFunc(path string, handler interface{}, opts ...Option) {
+	Default.HandleFunc(path, handler, opts...)
+}
+
+// TryHandle registers the handler for the given path to the Default router,
+// returning an error if it cannot be.
+func TryHandle(path string, handler interface{}, opts ...Option) error {
+	return Default.TryHandle(path, handler, opts...)
+}
+
+// TryHandleFunc registers the handler function for the given path to the
+// Default router, returning an error if it cannot be.
+func TryHandleFunc(path string, handler interface{}, opts ...Option) error {
+	return Default.TryHandleFunc(path, handler, opts...)
+}
+
+// A MatcherFunc decides whether a path segment is matched by a parameter, and
+// if it is returns the value to give the parameter.
+type MatcherFunc func(segment string) (value string, ok bool)
+
+// Matcher registers a MatcherFunc with the router under name. Named parameters
+// can then be constrained to match only path segments it accepts by following
+// the parameter name with the matcher name in angle brackets:
+//
+//   router.Matcher("lower", func(segment string) (string, bool) {
+//     return strings.ToLower(segment), true
+//   })
+//   router.Handle("/tenants/:tenant<lower>", tenantHandler)
+//
+// Matchers must be registered before the routes that use them.
+func (r *Router) Matcher(name string, matcher MatcherFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checkFrozen()
+
+	r.tree.matchers[name] = matcher
 }
 
 // Make sure the Router conforms with the http.Handler interface
@@ -103,81 +298,904 @@ var _ http.Handler = New()
 
 // New returns an initialized Router.
 func New() *Router {
-	return &Router{
-		NotFoundHandler: http.NotFoundHandler(),
-		ErrorHandler:    func(w http.ResponseWriter, r *http.Request, err error) {},
-		tree:            newLookup(),
+	r := &Router{
+		NotFoundHandler:         http.NotFoundHandler(),
+		MethodNotAllowedHandler: http.HandlerFunc(methodNotAllowed),
+		tree:                    newLookup(),
+		hosts:                   map[string]*treeLookup{},
+		notFounds:               map[string]*treeLookup{},
+		endpoints:               map[string]*endpoint{},
+		names:                   map[string]*endpoint{},
 	}
+	r.ErrorHandler = r.logError
+
+	return r
 }
 
+// logError is the default ErrorHandler.
+func (r *Router) logError(w http.ResponseWriter, req *http.Request, err error) {
+	code, msg := errorResponse(w, err)
+	if msg == "" {
+		msg = http.StatusText(code)
+	}
+
+	if code >= 500 {
+		if r.ErrorLog != nil {
+			r.ErrorLog.Printf("route: %s %s: %v", req.Method, req.URL.Path, err)
+		} else {
+			log.Printf("route: %s %s: %v", req.Method, req.URL.Path, err)
+		}
+	}
+
+	http.Error(w, msg, code)
+}
+
+// IgnoreErrors is an ErrorHandler that does nothing, leaving handlers to write
+// their own response when returning an error.
+func IgnoreErrors(w http.ResponseWriter, r *http.Request, err error) {}
+
 // Handle registers the handler for the given path to the router.
-func (r *Router) Handle(path string, handle interface{}) {
+//
+// The path may be preceded by a method, as in "GET /user/:name", to only handle
+// requests with that method. It may also be preceded by a host, as in
+// "api.example.com/user/:name", to only handle requests for that host. The
+// parameter syntax of http.ServeMux is also accepted, so "/user/{name}" is the
+// same as "/user/:name", "/files/{path...}" the same as "/files/*path" and
+// "/static/" the same as "/static/*rest".
+func (r *Router) Handle(path string, handle interface{}, opts ...Option) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if path[0] != '/' {
-		panic("path must begin with '/'")
+	method, host, path := parsePattern(path)
+
+	e := &entry{source: callSite()}
+	if method != "" {
+		Methods(method)(e)
 	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	e.handler = toHandler(handle)
+
+	r.add(host, path, e)
+}
 
+// add registers the route for the host and path. It must be called with the
+// lock held.
+func (r *Router) add(host, path string, e *entry) {
+	r.checkFrozen()
+	r.changed()
+
+	if e.name != "" {
+		if existing, ok := r.names[e.name]; ok {
+			msg := "route with name already registered: " + e.name + " for " + existing.host + existing.pattern
+			if source := existing.source(e.name); source != "" {
+				msg += " at " + source
+			}
+			panic(msg)
+		}
+	}
+
+	formatPaths := make([]string, len(e.formats))
+	for i, format := range e.formats {
+		formatPaths[i] = withExtension(path, format)
+	}
+
+	ep := r.endpoint(host, path)
+	e.stats = &routeStats{}
+	ep.add(e)
+
+	for i, format := range e.formats {
+		formatEp := r.endpoint(host, formatPaths[i])
+		formatEp.vars = map[string]string{"format": format}
+		formatEp.add(e)
+	}
+
+	if e.name != "" {
+		r.names[e.name] = ep
+	}
+}
+
+// toHandler returns handle as a Handler, it must be either a Handler or an
+// http.Handler.
+func toHandler(handle interface{}) Handler {
 	switch v := handle.(type) {
 	case Handler:
-		r.tree.Add(path, v)
+		return v
 	case http.Handler:
-		r.tree.Add(path, nilErrorHandler{v})
+		return nilErrorHandler{v}
 	default:
 		panic("tried to register unhandleable type with Handle")
 	}
 }
 
+// endpoint returns the endpoint for the path, adding it to the tree if it does
+// not exist.
+func (r *Router) endpoint(host, path string) *endpoint {
+	defer func() {
+		if v := recover(); v != nil {
+			if err, ok := v.(*conflictError); ok {
+				if existing, ok := r.endpoints[host+err.existing]; ok {
+					err.source = existing.source("")
+				}
+			}
+			panic(v)
+		}
+	}()
+
+	ep, ok := r.endpoints[host+path]
+	if !ok {
+		ep = &endpoint{pattern: path, host: host}
+		r.lookup(host).Add(path, ep)
+		r.endpoints[host+path] = ep
+	}
+
+	return ep
+}
+
+// lookup returns the tree for routes registered to host, creating it if it does
+// not exist.
+func (r *Router) lookup(host string) *treeLookup {
+	if host == "" {
+		return r.tree
+	}
+
+	tree, ok := r.hosts[host]
+	if !ok {
+		tree = newLookup()
+		tree.matchers = r.tree.matchers
+		r.hosts[host] = tree
+	}
+
+	return tree
+}
+
 // HandleFunc registers the handler function (either `func(http.ResponseWriter,
-// *http.Request)` or `func(http.ResponseWriter, *http.Request) error`) for the
-// given path to the Default router.
-func (r *Router) HandleFunc(path string, handler interface{}) {
+// *http.Request)` or `func(http.ResponseWriter, *http.Request) error`, or either
+// of these taking an extra Params argument, or `func(context.Context,
+// http.ResponseWriter, *http.Request) error`, or a HandlerFactory) for the given
+// path to the Default router.
+func (r *Router) HandleFunc(path string, handler interface{}, opts ...Option) {
 	switch v := handler.(type) {
 	case func(http.ResponseWriter, *http.Request) error:
-		r.Handle(path, HandlerFunc(v))
+		r.Handle(path, HandlerFunc(v), opts...)
 	case func(http.ResponseWriter, *http.Request):
-		r.Handle(path, http.HandlerFunc(v))
+		r.Handle(path, http.HandlerFunc(v), opts...)
+	case func(http.ResponseWriter, *http.Request, Params) error:
+		r.Handle(path, ParamsHandlerFunc(v), opts...)
+	case func(http.ResponseWriter, *http.Request, Params):
+		r.Handle(path, ParamsFunc(v), opts...)
+	case func(context.Context, http.ResponseWriter, *http.Request) error:
+		r.Handle(path, ContextHandlerFunc(v), opts...)
+	case func(*http.Request) (Handler, error):
+		r.Handle(path, HandlerFactory(v), opts...)
 	default:
 		panic("tried to register unhandleable func type with HandleFunc")
 	}
 }
 
+// TryHandle registers the handler for the given path as Handle does, but
+// returns an error instead of panicking if the path is invalid or conflicts
+// with a route already registered. This is useful when routes come from
+// configuration or plugins, rather than being fixed in code. Nothing is
+// registered if an error is returned.
+func (r *Router) TryHandle(path string, handle interface{}, opts ...Option) (err error) {
+	defer recoverRegistration(&err)
+
+	r.Handle(path, handle, opts...)
+	return nil
+}
+
+// TryHandleFunc registers the handler function for the given path as
+// HandleFunc does, but returns an error instead of panicking, as for TryHandle.
+func (r *Router) TryHandleFunc(path string, handler interface{}, opts ...Option) (err error) {
+	defer recoverRegistration(&err)
+
+	r.HandleFunc(path, handler, opts...)
+	return nil
+}
+
+// Remove removes the routes registered for the pattern, returning false if there
+// were none. If the pattern includes a method, as in "GET /users", only the
+// routes restricted to exactly that method are removed, otherwise all routes
+// for the path are. Any names given to the routes can then be reused.
+//
+//   router.Handle("/plugins/stats", statsHandler)
+//   router.Remove("/plugins/stats")
+func (r *Router) Remove(pattern string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checkFrozen()
+	r.changed()
+
+	method, host, path := parsePattern(pattern)
+
+	ep, ok := r.endpoints[host+path]
+	if !ok || ep.vars != nil {
+		return false
+	}
+
+	removed := ep.remove(forMethod(method))
+
+	for _, e := range removed {
+		if e.name != "" {
+			delete(r.names, e.name)
+		}
+
+		for _, format := range e.formats {
+			if formatEp, ok := r.endpoints[host+withExtension(path, format)]; ok {
+				formatEp.remove(func(other *entry) bool { return other == e })
+				r.prune(formatEp)
+			}
+		}
+	}
+	r.prune(ep)
+
+	return len(removed) > 0
+}
+
+// Replace replaces the handler of the routes registered for the pattern, leaving
+// their options unchanged. The pattern selects routes as for Remove. Requests
+// being served concurrently are handled by either the old or new handler. An
+// error is returned if there is no route for the pattern.
+//
+//   router.Replace("GET /", newHomeHandler)
+func (r *Router) Replace(pattern string, handle interface{}) error {
+	handler := toHandler(handle)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.frozen {
+		return ErrFrozen
+	}
+
+	method, host, path := parsePattern(pattern)
+
+	replaced := false
+	if ep, ok := r.endpoints[host+path]; ok && ep.vars == nil {
+		match := forMethod(method)
+		for _, e := range ep.routes {
+			if match(e) {
+				e.handler = handler
+				replaced = true
+				r.changed()
+			}
+		}
+	}
+
+	if !replaced {
+		return errors.New("route: no route registered for " + pattern)
+	}
+
+	return nil
+}
+
+// Swap replaces all of the routes of the router with those registered by build.
+// The routes are registered to a new Router, starting with the same matchers,
+// which is then swapped in at once, so requests are never served by a partially
+// built table. Only routes, not-found handlers and matchers are taken from the
+// new Router, other fields such as ErrorHandler are kept.
+//
+// If build panics registering a route the error is returned and the router is
+// left unchanged.
+//
+//   err := router.Swap(func(r *route.Router) {
+//     for _, page := range config.Pages {
+//       r.Handle(page.Path, pageHandler(page))
+//     }
+//   })
+func (r *Router) Swap(build func(*Router)) (err error) {
+	if r.frozen {
+		return ErrFrozen
+	}
+
+	next := r.fork()
+
+	defer recoverRegistration(&err)
+	build(next)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tree = next.tree
+	r.hosts = next.hosts
+	r.notFounds = next.notFounds
+	r.endpoints = next.endpoints
+	r.names = next.names
+	r.changed()
+
+	return nil
+}
+
+// fork returns a new Router with the same matchers, so that routes can be
+// registered to it before being added to the router at once.
+func (r *Router) fork() *Router {
+	next := New()
+
+	r.mu.RLock()
+	for name, matcher := range r.tree.matchers {
+		next.tree.matchers[name] = matcher
+	}
+	r.mu.RUnlock()
+
+	return next
+}
+
+// Merge registers the routes of other to the router, so that routers built
+// separately, for instance by each package of an application, can be combined.
+// The routes keep their options, and any matchers they use are copied. Routes
+// that conflict with one already registered, by having the same name or being
+// for the same methods and pattern without conditions, are not merged and an
+// error listing them is returned.
+//
+//   router := route.New()
+//   router.Merge(users.Routes())
+//   router.Merge(billing.Routes())
+func (r *Router) Merge(other *Router) error {
+	if r.frozen {
+		return ErrFrozen
+	}
+	if other == r {
+		return nil
+	}
+
+	type route struct {
+		host, pattern string
+		e             *entry
+		info          RouteInfo
+	}
+
+	other.mu.RLock()
+	var routes []route
+	for _, ep := range other.sortedEndpoints() {
+		for i, e := range ep.routes {
+			copied := *e
+			routes = append(routes, route{ep.host, ep.pattern, &copied, ep.info(i)})
+		}
+	}
+	matchers := map[string]MatcherFunc{}
+	for name, matcher := range other.tree.matchers {
+		matchers[name] = matcher
+	}
+	other.mu.RUnlock()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for name, matcher := range matchers {
+		if _, ok := r.tree.matchers[name]; !ok {
+			r.tree.matchers[name] = matcher
+		}
+	}
+
+	var conflicts []string
+	for _, route := range routes {
+		if existing, ok := r.endpoints[route.host+route.pattern]; ok && !route.e.conditional() {
+			if i := existing.unconditional(route.e.methods); i >= 0 {
+				conflicts = append(conflicts, describeRoute(route.info)+" conflicts with "+describeRoute(existing.info(i)))
+				continue
+			}
+		}
+
+		var err error
+		func() {
+			defer recoverRegistration(&err)
+			r.add(route.host, route.pattern, route.e)
+		}()
+		if err != nil {
+			conflicts = append(conflicts, describeRoute(route.info)+": "+strings.TrimPrefix(err.Error(), "route: "))
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return errors.New("route: could not merge " + strings.Join(conflicts, "; "))
+	}
+
+	return nil
+}
+
+// forMethod returns a function reporting whether a route is restricted to
+// exactly the method, or if method is empty always true.
+func forMethod(method string) func(*entry) bool {
+	match := &entry{}
+	if method != "" {
+		Methods(method)(match)
+	}
+
+	return func(e *entry) bool {
+		return method == "" || sameMethods(e.methods, match.methods)
+	}
+}
+
+// prune removes the endpoint from the router if it has no routes.
+func (r *Router) prune(ep *endpoint) {
+	if len(ep.routes) > 0 {
+		return
+	}
+
+	tree := r.lookup(ep.host)
+	tree.Remove(ep.pattern)
+	delete(r.endpoints, ep.host+ep.pattern)
+
+	if ep.host != "" && tree.root.empty() {
+		delete(r.hosts, ep.host)
+	}
+}
+
+// recoverRegistration recovers from a panic caused by registering an invalid
+// route, setting err to describe it. Runtime errors are not recovered.
+func recoverRegistration(err *error) {
+	v := recover()
+	if v == nil {
+		return
+	}
+
+	if v == ErrFrozen {
+		*err = ErrFrozen
+		return
+	}
+
+	switch v := v.(type) {
+	case runtime.Error:
+		panic(v)
+	case error:
+		*err = fmt.Errorf("route: %w", v)
+	default:
+		*err = fmt.Errorf("route: %v", v)
+	}
+}
+
 // ServeHTTP dispatches the request to appropriate handler, if none can be found
 // NotFoundHandler is used.
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	path := req.URL.EscapedPath()
+	r.active.Add(1)
+	defer r.release()
 
-	if req.Method != "CONNECT" {
+	if r.idle.Load() != nil {
+		refuseDraining(w)
+		return
+	}
+
+	if r.Tracer != nil {
+		r.serveTraced(w, req)
+		return
+	}
+
+	r.serve(w, req)
+}
+
+// serve dispatches the request as ServeHTTP does.
+func (r *Router) serve(w http.ResponseWriter, req *http.Request) {
+	path := r.requestPath(req)
+
+	if (r.MaxPathLength > 0 && len(path) > r.MaxPathLength) ||
+		(r.MaxPathSegments > 0 && strings.Count(path, "/") > r.MaxPathSegments) {
+		http.Error(w, http.StatusText(http.StatusRequestURITooLong), http.StatusRequestURITooLong)
+		return
+	}
+
+	if r.CanonicalHost != AnyHost && req.Method != "CONNECT" && r.redirectHost(w, req) {
+		return
+	}
+
+	if req.Method != "CONNECT" && !r.SkipClean {
 		if cleanpath := cleanPath(path); cleanpath != path {
 			url := *req.URL
 			url.Path = cleanpath
-			http.RedirectHandler(url.String(), http.StatusMovedPermanently).ServeHTTP(w, req)
+			if r.DecodePath {
+				url.RawPath = ""
+			}
+			r.redirect(w, req, url.String())
 			return
 		}
 	}
 
-	r.mu.RLock()
-	defer r.mu.RUnlock()
+	s := r.load()
 
-	if handle, ps := r.tree.Get(path); handle != nil {
-		req = req.WithContext(context.WithValue(req.Context(), varsKey{}, ps))
-		err := handle.ServeErrorHTTP(w, req)
-		if err != nil {
-			r.ErrorHandler(w, req, err)
+	m := matchPool.Get().(*match)
+	defer m.release()
+
+	ep, e, status := s.find(req, path, &m.params)
+	if ep != nil {
+		observed(req, ep, e, m.params, r.DecodePath)
+	}
+	if ep != nil && hasTrailingSlash(path) {
+		switch r.slashPolicy(ep, e) {
+		case RedirectSlash:
+			r.redirectSlash(w, req)
+			return
+		case StrictSlash:
+			status = http.StatusNotFound
 		}
+	}
+
+	switch status {
+	case http.StatusNotFound:
+		if ep == nil && r.RedirectFixedPath && r.redirectFixedPath(w, req, s, path) {
+			return
+		}
+		if r.SuggestRoutes {
+			req = req.WithContext(context.WithValue(req.Context(), suggestionsKey{}, r.suggest(req.Host, path)))
+		}
+		r.notFound(s, req, path).ServeHTTP(w, req)
+		return
+	case http.StatusMethodNotAllowed:
+		w.Header().Set("Allow", strings.Join(ep.allowed(), ", "))
+		r.MethodNotAllowedHandler.ServeHTTP(w, req)
 		return
+	case http.StatusUnsupportedMediaType:
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	if r.UnescapeVars && !r.DecodePath {
+		m.params.unescape()
+	}
+
+	m.pattern = ep.pattern
+	m.meta = e.meta
+	m.decoded = r.DecodePath || r.UnescapeVars
+
+	// requests for routes without parameters are passed on unchanged, as adding
+	// the match to the context costs more than routing them, so their match is
+	// kept by the router until the handler returns, unless there are functions
+	// added by Before or BeforeRequest that may replace the request
+	if len(m.params) > 0 || len(s.before) > 0 {
+		req = req.WithContext(context.WithValue(req.Context(), matchKey{}, m))
+		defer unbindMatch(req.URL, bindMatch(req.URL, nil))
+	} else {
+		defer unbindMatch(req.URL, bindMatch(req.URL, m))
+	}
+
+	for _, before := range s.before {
+		var ok bool
+		if req, ok = before(w, req); !ok {
+			return
+		}
+	}
+
+	for _, link := range e.links {
+		w.Header().Add("Link", link)
+	}
+
+	var cw *cachingWriter
+	if e.cacheControl != "" || e.surrogateControl != "" {
+		cw = &cachingWriter{ResponseWriter: w, cacheControl: e.cacheControl, surrogateControl: e.surrogateControl}
+		w = cw
+	}
+
+	var rec *responseRecorder
+	if len(s.after) > 0 {
+		rec = &responseRecorder{ResponseWriter: w}
+		w = rec
+	}
+
+	var start time.Time
+	if r.RecordStats || rec != nil {
+		start = time.Now()
+	}
+
+	err := e.handler.ServeErrorHTTP(w, req)
+	if r.RecordStats {
+		e.stats.record(time.Since(start), err != nil)
+	}
+	if err != nil {
+		observedError(req, err)
+		if r.RouteErrorHandler != nil {
+			r.RouteErrorHandler(w, req, s.mapError(err), RouteMatch{
+				Pattern: ep.pattern,
+				Host:    ep.host,
+				Name:    e.name,
+				Vars:    m.params.Map(),
+				Meta:    e.meta,
+			})
+		} else {
+			r.ErrorHandler(w, req, s.mapError(err))
+		}
+	}
+
+	// a successful response with no body is sent by the server once the
+	// handler returns, so needs the headers set now
+	if cw != nil && !cw.wroteHeader && err == nil {
+		cw.setHeaders()
+	}
+
+	if rec != nil {
+		result := Result{
+			Pattern:  ep.pattern,
+			Host:     ep.host,
+			Name:     e.name,
+			Status:   rec.code(),
+			Size:     rec.size,
+			Duration: time.Since(start),
+			Err:      err,
+		}
+		for _, after := range s.after {
+			after(req, result)
+		}
+	}
+}
+
+// requestPath returns the path of the request to match routes against.
+func (r *Router) requestPath(req *http.Request) string {
+	if r.DecodePath {
+		return req.URL.Path
+	}
+
+	return req.URL.EscapedPath()
+}
+
+// redirect redirects the request to the url with RedirectStatus.
+func (r *Router) redirect(w http.ResponseWriter, req *http.Request, url string) {
+	code := r.RedirectStatus
+	if code == 0 {
+		code = http.StatusPermanentRedirect
+		if req.Method == "GET" || req.Method == "HEAD" {
+			code = http.StatusMovedPermanently
+		}
+	}
+
+	http.Redirect(w, req, url, code)
+}
+
+// redirectFixedPath redirects the request to the path of the route matching it
+// ignoring case, returning false if there is not exactly one.
+func (r *Router) redirectFixedPath(w http.ResponseWriter, req *http.Request, s *snapshot, path string) bool {
+	fixed, ok := s.fixCase(req.Host, path)
+	if !ok || fixed == path {
+		return false
+	}
+	if hasTrailingSlash(path) {
+		fixed += "/"
+	}
+
+	u := *req.URL
+	if r.DecodePath {
+		u.Path, u.RawPath = fixed, ""
+	} else {
+		unescaped, err := url.PathUnescape(fixed)
+		if err != nil {
+			return false
+		}
+		u.Path, u.RawPath = unescaped, fixed
+	}
+	r.redirect(w, req, u.String())
+	return true
+}
+
+// Match finds the route that would handle a request with the method and path,
+// without serving it. This is the same as MatchRequest with a request that has
+// no headers or body.
+func (r *Router) Match(method, path string) (RouteMatch, error) {
+	req, err := http.NewRequest(method, path, nil)
+	if err != nil {
+		return RouteMatch{}, err
+	}
+
+	return r.MatchRequest(req)
+}
+
+// MatchRequest finds the route that would handle the request, without serving
+// it. If there is no route an HTTPError is returned with the status code that
+// would be responded with: 404 Not Found, 405 Method Not Allowed, or 415
+// Unsupported Media Type. Allow is set even when there is an error if the path
+// matched a route.
+func (r *Router) MatchRequest(req *http.Request) (RouteMatch, error) {
+	var ps Params
+	path := r.requestPath(req)
+	ep, e, status := r.load().find(req, path, &ps)
+	if ep != nil && hasTrailingSlash(path) && r.slashPolicy(ep, e) == StrictSlash {
+		return RouteMatch{}, Error(http.StatusNotFound, "")
+	}
+
+	var m RouteMatch
+	if ep != nil {
+		m.Pattern = ep.pattern
+		m.Host = ep.host
+		m.Allow = ep.allowed()
+	}
+	if e == nil {
+		return m, Error(status, "")
+	}
+
+	if r.UnescapeVars && !r.DecodePath {
+		ps.unescape()
 	}
 
-	r.NotFoundHandler.ServeHTTP(w, req)
+	m.Name = e.name
+	m.Vars = ps.Map()
+	m.Meta = e.meta
+	m.Handler = e.handler
+	return m, nil
 }
 
-type varsKey struct{}
+// notFound returns the handler to use when no route matches the path. This is
+// the handler set for the deepest Group containing the path, or if there is not
+// one the next handler of the Chain serving the request, or NotFoundHandler.
+func (r *Router) notFound(s *snapshot, req *http.Request, path string) http.Handler {
+	if handler := s.notFound(req.Host, path); handler != nil {
+		return handler
+	}
+	if next := r.fallthroughHandler(req); next != nil {
+		return next
+	}
+
+	return r.NotFoundHandler
+}
+
+// stripPort removes any port from the host.
+func stripPort(host string) string {
+	if i := strings.LastIndexByte(host, ':'); i >= 0 && !strings.HasSuffix(host, "]") {
+		return host[:i]
+	}
+
+	return host
+}
+
+func methodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+}
+
+// RouteMatch describes the route matched by a request.
+type RouteMatch struct {
+	// Pattern is the path pattern of the route, as "/user/:name".
+	Pattern string
+
+	// Host is the host the route was registered for, if any.
+	Host string
+
+	// Name is the name of the route, if any.
+	Name string
+
+	// Vars are the parameter matches for the request.
+	Vars map[string]string
+
+	// Meta is the metadata attached to the route with Tag, if any.
+	Meta map[string]any
+
+	// Handler is the handler for the route. It is only set by MatchRequest.
+	Handler Handler
+
+	// Allow lists the methods that routes for the path handle, if any of them are
+	// restricted to methods. It is only set by MatchRequest.
+	Allow []string
+}
+
+type matchKey struct{}
+
+// match is stored in the request context when a route is matched. They are
+// reused for later requests once the handler returns.
+type match struct {
+	pattern string
+	params  Params
+
+	// decoded is set if the parameter values have been percent-decoded.
+	decoded bool
+
+	// meta is the metadata of the route.
+	meta map[string]any
+
+	// vars is filled from params when Vars is first called, it is kept when the
+	// match is released so the map is reused.
+	vars     map[string]string
+	varsOnce sync.Once
+}
+
+var matchPool = sync.Pool{
+	New: func() interface{} { return &match{} },
+}
+
+// release resets the match and returns it to the pool, keeping the slice of
+// parameters and the map of vars to be reused.
+func (m *match) release() {
+	clear(m.vars)
+	*m = match{params: m.params[:0], vars: m.vars}
+	matchPool.Put(m)
+}
+
+func getMatch(r *http.Request) *match {
+	if m := boundMatch(r.URL); m != nil {
+		return m
+	}
+	if rv := r.Context().Value(matchKey{}); rv != nil {
+		return rv.(*match)
+	}
+
+	return nil
+}
+
+// boundMatches holds the matches of requests being served for routes without
+// parameters, keyed by the URL of the request so that they are found for
+// requests made from it with WithContext. Requests for routes with parameters
+// are bound to nil, so that their match is taken from the context instead. The
+// matches are split into shards by the address of the URL to spread locking.
+var boundMatches [64]matchShard
+
+type matchShard struct {
+	sync.Mutex
+	m map[*url.URL]*match
+}
+
+// binding is the match, if any, bound to a URL before bindMatch.
+type binding struct {
+	m  *match
+	ok bool
+}
+
+func shardFor(u *url.URL) *matchShard {
+	return &boundMatches[(reflect.ValueOf(u).Pointer()>>4)%uintptr(len(boundMatches))]
+}
+
+// bindMatch binds the match to requests with the URL until unbindMatch is
+// called with the binding it returns.
+func bindMatch(u *url.URL, m *match) binding {
+	shard := shardFor(u)
+	shard.Lock()
+	defer shard.Unlock()
+
+	if shard.m == nil {
+		shard.m = map[*url.URL]*match{}
+	}
+	prev, ok := shard.m[u]
+	shard.m[u] = m
+
+	return binding{prev, ok}
+}
+
+// unbindMatch restores the match bound to the URL before bindMatch.
+func unbindMatch(u *url.URL, prev binding) {
+	shard := shardFor(u)
+	shard.Lock()
+	defer shard.Unlock()
+
+	if prev.ok {
+		shard.m[u] = prev.m
+	} else {
+		delete(shard.m, u)
+	}
+}
+
+// boundMatch returns the match bound to the URL, if any.
+func boundMatch(u *url.URL) *match {
+	shard := shardFor(u)
+	shard.Lock()
+	defer shard.Unlock()
+
+	return shard.m[u]
+}
+
+// Pattern returns the pattern of the route matched by the given request, such as
+// "/user/:name", or an empty string if no route was matched. Unlike the path of
+// the request it does not vary with parameter values, so is suitable for
+// labelling logs and metrics.
+//
+// Requests for routes without parameters are passed to the handler unchanged,
+// to avoid allocating, with the match kept by the router until the handler
+// returns. It is found for requests made from them with WithContext, but not
+// with Clone.
+func Pattern(r *http.Request) string {
+	if m := getMatch(r); m != nil {
+		return m.pattern
+	}
+
+	return ""
+}
 
-// Vars retrieves the parameter matches for the given request.
+// Vars retrieves the parameter matches for the given request. The map is
+// request-scoped: it is pooled and reused for later requests once the handler
+// returns, so must not be kept or changed, copy it if the values are needed
+// afterwards.
 func Vars(r *http.Request) map[string]string {
-	if rv := r.Context().Value(varsKey{}); rv != nil {
-		return rv.(map[string]string)
+	if m := getMatch(r); m != nil {
+		m.varsOnce.Do(func() {
+			if m.vars == nil {
+				m.vars = make(map[string]string, len(m.params))
+			}
+			for _, p := range m.params {
+				m.vars[p.Key] = p.Value
+			}
+		})
+		return m.vars
 	}
 
 	return nil
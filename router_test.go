@@ -1,9 +1,15 @@
 package route
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"log"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -18,7 +24,12 @@ type recordingHandler struct {
 
 func (h *recordingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	h.Used = true
-	h.Vars = Vars(r)
+
+	// Vars are only valid while handling the request
+	h.Vars = map[string]string{}
+	for k, v := range Vars(r) {
+		h.Vars[k] = v
+	}
 }
 
 func TestRouter(t *testing.T) {
@@ -35,6 +46,60 @@ func TestRouter(t *testing.T) {
 	assert.Equal(t, map[string]string{"name": "gopher"}, handler.Vars)
 }
 
+func TestPattern(t *testing.T) {
+	var pattern string
+
+	router := New()
+	router.HandleFunc("GET /user/{name}/*rest", func(w http.ResponseWriter, r *http.Request) {
+		pattern = Pattern(r)
+	})
+
+	r, _ := http.NewRequest("GET", "/user/gopher/a/b", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+
+	assert.Equal(t, "/user/:name/*rest", pattern)
+	assert.Equal(t, "", Pattern(r))
+}
+
+func TestRouterMatch(t *testing.T) {
+	router := New()
+
+	handler := &recordingHandler{}
+	router.Handle("GET /user/:name", handler, Name("user"))
+	router.Handle("POST /user/:name", handler)
+	router.Handle("PUT /upload", handler, ContentType("application/json"))
+
+	m, err := router.Match("GET", "/user/gopher")
+	assert.Nil(t, err)
+	assert.Equal(t, "/user/:name", m.Pattern)
+	assert.Equal(t, "user", m.Name)
+	assert.Equal(t, map[string]string{"name": "gopher"}, m.Vars)
+	assert.Equal(t, []string{"GET", "HEAD", "POST"}, m.Allow)
+	assert.Equal(t, nilErrorHandler{handler}, m.Handler)
+
+	assert.False(t, handler.Used)
+
+	cases := []struct {
+		method, path string
+		code         int
+	}{
+		{"GET", "/nowhere", 404},
+		{"DELETE", "/user/gopher", 405},
+		{"PUT", "/upload", 415},
+	}
+
+	for _, tc := range cases {
+		_, err := router.Match(tc.method, tc.path)
+
+		if assert.IsType(t, &HTTPError{}, err) {
+			assert.Equal(t, tc.code, err.(*HTTPError).Code)
+		}
+	}
+
+	m, _ = router.Match("DELETE", "/user/gopher")
+	assert.Equal(t, []string{"GET", "HEAD", "POST"}, m.Allow)
+}
+
 func TestRouterRegisterWithHttpHandleFunc(t *testing.T) {
 	router := New()
 	router.HandleFunc("/HandlerFunc", func(w http.ResponseWriter, r *http.Request) {
@@ -62,6 +127,151 @@ func TestRouterRegisterWithHandleFunc(t *testing.T) {
 	assert.Equal(t, 418, w.Code)
 }
 
+func TestRouterRegisterWithContextHandleFunc(t *testing.T) {
+	type key struct{}
+	failure := errors.New("failed")
+	var handled error
+
+	router := New()
+	router.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		handled = err
+	}
+	router.HandleFunc("/users/:id", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		assert.Equal(t, "value", ctx.Value(key{}))
+		assert.Equal(t, "5", Vars(r)["id"])
+		return failure
+	})
+
+	r, _ := http.NewRequest("GET", "/users/5", nil)
+	r = r.WithContext(context.WithValue(r.Context(), key{}, "value"))
+	router.ServeHTTP(httptest.NewRecorder(), r)
+
+	assert.Equal(t, failure, handled)
+}
+
+func TestRouterRegisterConflict(t *testing.T) {
+	router := New()
+	router.Handle("/user/:name", &recordingHandler{})
+
+	recv := checkPanics(t, func() {
+		router.Handle("/user/:id/edit", &recordingHandler{})
+	})
+
+	assert.Regexp(t, `^wildedge with different name already registered: /user/:id/edit conflicts with /user/:name registered at router_test\.go:\d+$`,
+		recv.(error).Error())
+}
+
+func TestRouterWithServeMuxPattern(t *testing.T) {
+	router := New()
+
+	userHandler := &recordingHandler{}
+	fileHandler := &recordingHandler{}
+	router.Handle("GET /user/{name}", userHandler)
+	router.Handle("/files/{path...}", fileHandler)
+
+	r, _ := http.NewRequest("GET", "/user/gopher", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	assert.True(t, userHandler.Used)
+	assert.Equal(t, map[string]string{"name": "gopher"}, userHandler.Vars)
+
+	r, _ = http.NewRequest("GET", "/files/a/b.txt", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	assert.True(t, fileHandler.Used)
+	assert.Equal(t, map[string]string{"path": "a/b.txt"}, fileHandler.Vars)
+}
+
+func TestRouterWithMethods(t *testing.T) {
+	router := New()
+
+	getHandler := &recordingHandler{}
+	postHandler := &recordingHandler{}
+	anyHandler := &recordingHandler{}
+	router.Handle("GET /user/:name", getHandler)
+	router.Handle("/user/:name", postHandler, Methods("post", "PUT"))
+	router.Handle("/thing", anyHandler)
+
+	r, _ := http.NewRequest("GET", "/user/gopher", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	assert.True(t, getHandler.Used)
+	assert.False(t, postHandler.Used)
+
+	r, _ = http.NewRequest("PUT", "/user/gopher", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	assert.True(t, postHandler.Used)
+	assert.Equal(t, map[string]string{"name": "gopher"}, postHandler.Vars)
+
+	r, _ = http.NewRequest("DELETE", "/thing", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	assert.True(t, anyHandler.Used)
+}
+
+func TestRouterHeadUsesGet(t *testing.T) {
+	router := New()
+	router.HandleFunc("GET /thing", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(418)
+	})
+
+	r, _ := http.NewRequest("HEAD", "/thing", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, 418, w.Code)
+}
+
+func TestRouterMethodNotAllowed(t *testing.T) {
+	router := New()
+	router.Handle("GET /user/:name", &recordingHandler{})
+	router.Handle("POST /user/:name", &recordingHandler{})
+
+	r, _ := http.NewRequest("DELETE", "/user/gopher", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, 405, w.Code)
+	assert.Equal(t, "GET, HEAD, POST", w.Header().Get("Allow"))
+}
+
+func TestRouterMethodNotAllowedHandlerSet(t *testing.T) {
+	router := New()
+	router.MethodNotAllowedHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(418)
+	})
+	router.Handle("GET /user/:name", &recordingHandler{})
+
+	r, _ := http.NewRequest("DELETE", "/user/gopher", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, 418, w.Code)
+	assert.Equal(t, "GET, HEAD", w.Header().Get("Allow"))
+}
+
+func TestRouterReplacesRouteForSameMethods(t *testing.T) {
+	router := New()
+
+	first := &recordingHandler{}
+	second := &recordingHandler{}
+	router.Handle("GET /thing", first)
+	router.Handle("GET /thing", second)
+
+	r, _ := http.NewRequest("GET", "/thing", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	assert.False(t, first.Used)
+	assert.True(t, second.Used)
+}
+
 func TestRouterWithEncodedPath(t *testing.T) {
 	arg := ""
 
@@ -79,6 +289,75 @@ func TestRouterWithEncodedPath(t *testing.T) {
 	assert.Equal(t, "Something+%2B+Something", arg)
 }
 
+func TestRouterDecodePath(t *testing.T) {
+	router := New()
+	router.DecodePath = true
+
+	handler := &recordingHandler{}
+	router.Handle("/Handle++/:arg", handler)
+
+	r, _ := http.NewRequest("GET", "/Handle+%2B/Something%20+%2B+Something", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	assert.True(t, handler.Used)
+	assert.Equal(t, map[string]string{"arg": "Something +++Something"}, handler.Vars)
+
+	// an encoded '/' separates segments
+	r, _ = http.NewRequest("GET", "/Handle++/a%2Fb", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, 404, w.Code)
+
+	m, err := router.Match("GET", "/Handle%2B%2B/a%20b")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"arg": "a b"}, m.Vars)
+}
+
+func TestRouterUnescapeVars(t *testing.T) {
+	router := New()
+	router.UnescapeVars = true
+
+	handler := &recordingHandler{}
+	router.Handle("/users/:name/*path", handler)
+
+	r, _ := http.NewRequest("GET", "/users/john%20doe/a%2Fb/c", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	assert.True(t, handler.Used)
+	assert.Equal(t, map[string]string{"name": "john doe", "path": "a/b/c"}, handler.Vars)
+
+	m, err := router.Match("GET", "/users/a%2Bb/c")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"name": "a+b", "path": "c"}, m.Vars)
+}
+
+func TestRouterMatcher(t *testing.T) {
+	router := New()
+	router.Matcher("hex", func(segment string) (string, bool) {
+		_, err := strconv.ParseUint(segment, 16, 64)
+		return strings.ToLower(segment), err == nil
+	})
+
+	handler := &recordingHandler{}
+	router.Handle("/colour/:hex<hex>", handler)
+
+	r, _ := http.NewRequest("GET", "/colour/FF00AA", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	assert.True(t, handler.Used)
+	assert.Equal(t, map[string]string{"hex": "ff00aa"}, handler.Vars)
+
+	r, _ = http.NewRequest("GET", "/colour/red", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, 404, w.Code)
+}
+
 func TestRouterErrorHandler(t *testing.T) {
 	errCh := make(chan error, 1)
 	expectedErr := errors.New("what")
@@ -104,6 +383,69 @@ func TestRouterErrorHandler(t *testing.T) {
 	}
 }
 
+func TestRouterRouteErrorHandler(t *testing.T) {
+	expectedErr := errors.New("what")
+	var handledErr error
+	var handledMatch RouteMatch
+
+	router := New()
+	router.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		assert.Fail(t, "ErrorHandler should not be called")
+	}
+	router.RouteErrorHandler = func(w http.ResponseWriter, r *http.Request, err error, m RouteMatch) {
+		handledErr = err
+		handledMatch = m
+	}
+
+	router.HandleFunc("/user/:name", func(w http.ResponseWriter, r *http.Request) error {
+		return expectedErr
+	}, Name("user"))
+
+	r, _ := http.NewRequest("GET", "/user/gopher", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, expectedErr, handledErr)
+	assert.Equal(t, RouteMatch{
+		Pattern: "/user/:name",
+		Name:    "user",
+		Vars:    map[string]string{"name": "gopher"},
+	}, handledMatch)
+}
+
+func TestRouterDefaultErrorHandler(t *testing.T) {
+	var buf bytes.Buffer
+
+	router := New()
+	router.ErrorLog = log.New(&buf, "", 0)
+	router.HandleFunc("/HandlerFunc", func(w http.ResponseWriter, r *http.Request) error {
+		return errors.New("what")
+	})
+
+	r, _ := http.NewRequest("GET", "/HandlerFunc", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, 500, w.Code)
+	assert.Equal(t, "Internal Server Error\n", w.Body.String())
+	assert.Equal(t, "route: GET /HandlerFunc: what\n", buf.String())
+}
+
+func TestRouterIgnoreErrors(t *testing.T) {
+	router := New()
+	router.ErrorHandler = IgnoreErrors
+	router.HandleFunc("/HandlerFunc", func(w http.ResponseWriter, r *http.Request) error {
+		return errors.New("what")
+	})
+
+	r, _ := http.NewRequest("GET", "/HandlerFunc", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "", w.Body.String())
+}
+
 func TestRouterWithOverlappingRoutes(t *testing.T) {
 	router := New()
 
@@ -162,6 +504,89 @@ func TestRouterUncleanPathRedirectDoesNotClearQuery(t *testing.T) {
 	assert.Equal(t, "/?val=5&thing=yeah", w.Header().Get("Location"))
 }
 
+func TestRouterUncleanPathRedirectStatus(t *testing.T) {
+	router := New()
+
+	serve := func(method string) int {
+		r, _ := http.NewRequest(method, "/a/../b", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	assert.Equal(t, 301, serve("GET"))
+	assert.Equal(t, 301, serve("HEAD"))
+	assert.Equal(t, 308, serve("POST"))
+	assert.Equal(t, 308, serve("PUT"))
+	assert.Equal(t, 308, serve("DELETE"))
+
+	router.RedirectStatus = http.StatusFound
+	assert.Equal(t, 302, serve("GET"))
+	assert.Equal(t, 302, serve("POST"))
+}
+
+func TestRouterSkipClean(t *testing.T) {
+	router := New()
+	router.SkipClean = true
+
+	handler := &recordingHandler{}
+	router.Handle("/files/*path", handler)
+
+	r, _ := http.NewRequest("GET", "/files//signed/./a/../b", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, 200, w.Code)
+	assert.True(t, handler.Used)
+	assert.Equal(t, map[string]string{"path": "/signed/./a/../b"}, handler.Vars)
+
+	r, _ = http.NewRequest("GET", "/other/../files/a", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, 404, w.Code)
+}
+
+func TestRouterRedirectFixedPath(t *testing.T) {
+	router := New()
+	router.Handle("/about", &recordingHandler{})
+	router.Handle("/users/:name", &recordingHandler{})
+	router.Handle("/team/alice", &recordingHandler{})
+	router.Handle("/Team/alice", &recordingHandler{})
+
+	serve := func(method, path string) (int, string) {
+		r, _ := http.NewRequest(method, path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w.Code, w.Header().Get("Location")
+	}
+
+	code, _ := serve("GET", "/About")
+	assert.Equal(t, 404, code)
+
+	router.RedirectFixedPath = true
+
+	cases := []struct {
+		method, path string
+		code         int
+		location     string
+	}{
+		{"GET", "/About", 301, "/about"},
+		{"GET", "/ABOUT/?a=b", 301, "/about/?a=b"},
+		{"POST", "/About", 308, "/about"},
+		{"GET", "/Users/John%20Smith", 301, "/users/John%20Smith"},
+		{"GET", "/TEAM/alice", 404, ""},
+		{"GET", "/contact", 404, ""},
+	}
+
+	for _, tc := range cases {
+		code, location := serve(tc.method, tc.path)
+
+		assert.Equal(t, tc.code, code, tc.path)
+		assert.Equal(t, tc.location, location, tc.path)
+	}
+}
+
 func TestRouterUncleanPathDoNotRedirectConnectRequests(t *testing.T) {
 	router := New()
 
@@ -220,3 +645,403 @@ func TestRouterConcurrentRegisterAndRouting(t *testing.T) {
 	wg.Wait()
 	assert.True(t, handler.Used)
 }
+
+func TestRouterTryHandle(t *testing.T) {
+	router := New()
+	router.Handle("/user/:name", &recordingHandler{}, Name("user"))
+
+	cases := []string{
+		"user",
+		"/user/:id/edit",
+		"/files/:id/*path/more",
+		"/posts/:id(",
+	}
+	for _, pattern := range cases {
+		err := router.TryHandle(pattern, &recordingHandler{})
+		if assert.NotNil(t, err, pattern) {
+			assert.Regexp(t, "^route: ", err.Error())
+		}
+	}
+
+	err := router.TryHandle("/users/:name", &recordingHandler{}, Name("user"))
+	assert.NotNil(t, err)
+
+	err = router.TryHandleFunc("/user/:name", func() {})
+	assert.NotNil(t, err)
+
+	var conflict *conflictError
+	err = router.TryHandle("/user/:id/edit", &recordingHandler{})
+	assert.True(t, errors.As(err, &conflict))
+
+	// nothing was left behind by the failed registrations
+	assert.Len(t, router.Routes(), 1)
+	assert.Nil(t, router.TryHandle("/files/:name", &recordingHandler{}))
+	assert.Nil(t, router.TryHandleFunc("/posts/:slug", func(w http.ResponseWriter, r *http.Request) {}))
+	assert.Len(t, router.Routes(), 3)
+}
+
+func TestRouterRemove(t *testing.T) {
+	router := New()
+
+	getHandler := &recordingHandler{}
+	anyHandler := &recordingHandler{}
+	router.Handle("GET /user/:name", getHandler, Name("user"))
+	router.Handle("/user/:name", anyHandler)
+	router.Handle("/reports/:id", anyHandler, Formats("json"))
+	router.Handle("api.example.com/users", anyHandler)
+
+	serve := func(method, path string) int {
+		r, _ := http.NewRequest(method, path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	assert.True(t, router.Remove("GET /user/:name"))
+	assert.False(t, router.Remove("GET /user/:name"))
+	assert.Equal(t, 200, serve("GET", "/user/john"))
+	assert.False(t, getHandler.Used)
+	assert.True(t, anyHandler.Used)
+
+	// the name is free again
+	router.Handle("/people/:name", getHandler, Name("user"))
+
+	assert.True(t, router.Remove("/user/:name"))
+	assert.Equal(t, 404, serve("GET", "/user/john"))
+
+	assert.True(t, router.Remove("/reports/:id"))
+	assert.Equal(t, 404, serve("GET", "/reports/1"))
+	assert.Equal(t, 404, serve("GET", "/reports/1.json"))
+
+	assert.True(t, router.Remove("api.example.com/users"))
+	assert.Len(t, router.hosts, 0)
+
+	assert.False(t, router.Remove("/missing"))
+	assert.Len(t, router.Routes(), 1)
+}
+
+func TestRouterReplace(t *testing.T) {
+	router := New()
+
+	oldHandler := &recordingHandler{}
+	postHandler := &recordingHandler{}
+	router.Handle("GET /reports/:id", oldHandler, Formats("json"))
+	router.Handle("POST /reports/:id", postHandler)
+
+	newHandler := &recordingHandler{}
+	assert.Nil(t, router.Replace("GET /reports/:id", newHandler))
+
+	for _, path := range []string{"/reports/1", "/reports/1.json"} {
+		r, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+	}
+
+	assert.False(t, oldHandler.Used)
+	assert.False(t, postHandler.Used)
+	assert.True(t, newHandler.Used)
+	assert.Equal(t, map[string]string{"id": "1", "format": "json"}, newHandler.Vars)
+
+	assert.NotNil(t, router.Replace("PUT /reports/:id", newHandler))
+	assert.NotNil(t, router.Replace("/missing", newHandler))
+}
+
+func TestRouterSwap(t *testing.T) {
+	router := New()
+	router.Matcher("short", func(segment string) (string, bool) {
+		return segment, len(segment) <= 3
+	})
+
+	oldHandler := &recordingHandler{}
+	router.Handle("/old", oldHandler, Name("old"))
+
+	newHandler := &recordingHandler{}
+	err := router.Swap(func(r *Router) {
+		r.Handle("/new/:code<short>", newHandler, Name("new"))
+	})
+	assert.Nil(t, err)
+
+	serve := func(path string) int {
+		r, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	assert.Equal(t, 404, serve("/old"))
+	assert.Equal(t, 200, serve("/new/abc"))
+	assert.True(t, newHandler.Used)
+
+	_, err = router.URL("old")
+	assert.NotNil(t, err)
+	_, err = router.URL("new", "code", "abc")
+	assert.Nil(t, err)
+
+	err = router.Swap(func(r *Router) {
+		r.Handle("/other", oldHandler)
+		r.Handle("bad", oldHandler)
+	})
+	assert.NotNil(t, err)
+	assert.Equal(t, 404, serve("/other"))
+	assert.Equal(t, 200, serve("/new/abc"))
+}
+
+func TestRouterMerge(t *testing.T) {
+	short := func(segment string) (string, bool) {
+		return segment, len(segment) <= 3
+	}
+
+	users := New()
+	users.Matcher("short", short)
+	userHandler := &recordingHandler{}
+	users.Handle("GET /users/:id<short>", userHandler, Name("user"))
+	users.Handle("/reports/:id", &recordingHandler{}, Formats("json"))
+
+	billing := New()
+	billing.Matcher("short", short)
+	billing.Handle("GET /invoices", &recordingHandler{})
+	billing.Handle("GET /users/:id<short>", &recordingHandler{})
+	billing.Handle("/people/:id", &recordingHandler{}, Name("user"))
+	billing.Handle("/files/:name", &recordingHandler{})
+
+	router := New()
+	router.Handle("/files/:path", &recordingHandler{})
+
+	assert.Nil(t, router.Merge(users))
+
+	err := router.Merge(billing)
+	if assert.NotNil(t, err) {
+		assert.Regexp(t, `^route: could not merge /files/:name \(router_test\.go:\d+\): wildedge with different name`, err.Error())
+		assert.Regexp(t, `; /people/:id \(router_test\.go:\d+\): route with name already registered: user`, err.Error())
+		assert.Regexp(t, `; GET /users/:id<short> \(router_test\.go:\d+\) conflicts with GET /users/:id<short> \(router_test\.go:\d+\)$`, err.Error())
+	}
+
+	r, _ := http.NewRequest("GET", "/users/abc", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	assert.True(t, userHandler.Used)
+
+	m, err := router.Match("GET", "/reports/1.json")
+	assert.Nil(t, err)
+	assert.Equal(t, "/reports/:id.json", m.Pattern)
+
+	m, err = router.Match("GET", "/invoices")
+	assert.Nil(t, err)
+	assert.Equal(t, "/invoices", m.Pattern)
+
+	assert.Len(t, router.Routes(), 4)
+}
+
+func TestRouterMergeCopiesRoutes(t *testing.T) {
+	users := New()
+	users.RecordStats = true
+	users.HandleFunc("GET /users", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("old"))
+	})
+
+	r, _ := http.NewRequest("GET", "/users", nil)
+	users.ServeHTTP(httptest.NewRecorder(), r)
+
+	router := New()
+	assert.Nil(t, router.Merge(users))
+
+	assert.Equal(t, uint64(1), users.Stats()[0].Hits)
+
+	assert.Nil(t, users.Replace("GET /users", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("new"))
+	})))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	assert.Equal(t, "old", w.Body.String())
+
+	w = httptest.NewRecorder()
+	users.ServeHTTP(w, r)
+	assert.Equal(t, "new", w.Body.String())
+}
+
+func TestRouterRegisterWhileServing(t *testing.T) {
+	router := New()
+	router.HandleFunc("/register", func(w http.ResponseWriter, r *http.Request) {
+		router.Handle("/registered", &recordingHandler{})
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+			r, _ := http.NewRequest("GET", "/register", nil)
+			router.ServeHTTP(httptest.NewRecorder(), r)
+		}()
+
+		go func(i int) {
+			defer wg.Done()
+			router.Handle("/other/"+strconv.Itoa(i), &recordingHandler{})
+		}(i)
+	}
+	wg.Wait()
+
+	r, _ := http.NewRequest("GET", "/registered", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Len(t, router.Routes(), 12)
+}
+
+func TestRouterVarsNotReused(t *testing.T) {
+	router := New()
+
+	userHandler := &recordingHandler{}
+	staticHandler := &recordingHandler{}
+	router.Handle("/user/:name", userHandler)
+	router.Handle("/static", staticHandler)
+
+	for _, path := range []string{"/user/gopher", "/static", "/user/john"} {
+		r, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	assert.Equal(t, map[string]string{}, staticHandler.Vars)
+	assert.Equal(t, map[string]string{"name": "john"}, userHandler.Vars)
+}
+
+func TestRouterStaticRouteRequestUnchanged(t *testing.T) {
+	var served *http.Request
+	var pattern string
+
+	router := New()
+	router.HandleFunc("/static", func(w http.ResponseWriter, r *http.Request) {
+		served = r
+		pattern = Pattern(r.WithContext(context.Background()))
+	})
+
+	r, _ := http.NewRequest("GET", "/static", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+
+	assert.True(t, r == served)
+	assert.Equal(t, "/static", pattern)
+	assert.Equal(t, "", Pattern(r))
+
+	w := &headerWriter{header: http.Header{}}
+	allocs := testing.AllocsPerRun(100, func() {
+		router.ServeHTTP(w, r)
+	})
+	assert.Equal(t, 0.0, allocs)
+}
+
+func TestRouterNestedPattern(t *testing.T) {
+	var pattern string
+	record := func(w http.ResponseWriter, r *http.Request) {
+		pattern = Pattern(r)
+	}
+
+	inner := New()
+	inner.HandleFunc("/a/b", record)
+	inner.HandleFunc("/:name", record)
+
+	outer := New()
+	outer.Handle("/a/*path", inner)
+	outer.Handle("/x", inner)
+
+	outer.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/a/b", nil))
+	assert.Equal(t, "/a/b", pattern)
+
+	outer.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/x", nil))
+	assert.Equal(t, "/:name", pattern)
+}
+
+// headerWriter is a ResponseWriter that discards what is written to it.
+type headerWriter struct {
+	header http.Header
+}
+
+func (w *headerWriter) Header() http.Header         { return w.header }
+func (w *headerWriter) Write(p []byte) (int, error) { return len(p), nil }
+func (w *headerWriter) WriteHeader(int)             {}
+
+func TestRouterStaticRoutePattern(t *testing.T) {
+	var pattern string
+	var meta map[string]any
+
+	router := New()
+	router.HandleFunc("/static", func(w http.ResponseWriter, r *http.Request) {
+		pattern = Pattern(r)
+		meta = Meta(r)
+	})
+	router.HandleFunc("/tagged", func(w http.ResponseWriter, r *http.Request) {
+		pattern = Pattern(r)
+		meta = Meta(r)
+	}, Tag("team", "web"))
+
+	r, _ := http.NewRequest("GET", "/static", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+
+	assert.Equal(t, "/static", pattern)
+	assert.Nil(t, meta)
+
+	r, _ = http.NewRequest("GET", "/tagged", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+
+	assert.Equal(t, "/tagged", pattern)
+	assert.Equal(t, map[string]any{"team": "web"}, meta)
+}
+
+func TestRouterVarsBuiltOnce(t *testing.T) {
+	router := New()
+	router.HandleFunc("/files/:owner/*path", func(w http.ResponseWriter, r *http.Request) {
+		vars := Vars(r)
+		assert.Equal(t, map[string]string{"owner": "gopher", "path": "a/b/c.txt"}, vars)
+
+		assert.Equal(t, reflect.ValueOf(vars).Pointer(), reflect.ValueOf(Vars(r)).Pointer())
+		assert.Equal(t, Params{{"owner", "gopher"}, {"path", "a/b/c.txt"}}, GetParams(r))
+	})
+
+	r, _ := http.NewRequest("GET", "/files/gopher/a/b/c.txt", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+}
+
+func TestRouterPathLimits(t *testing.T) {
+	router := New()
+	router.MaxPathLength = 20
+	router.MaxPathSegments = 3
+	router.HandleFunc("/*path", func(w http.ResponseWriter, r *http.Request) {})
+
+	testCases := map[string]int{
+		"/a/b/c":                 http.StatusOK,
+		"/a/b/c/":                http.StatusRequestURITooLong,
+		"/a/b/c/d":               http.StatusRequestURITooLong,
+		"/abcdefghijklmnopqrs":   http.StatusOK,
+		"/abcdefghijklmnopqrst":  http.StatusRequestURITooLong,
+		"/a/../../../../b":       http.StatusRequestURITooLong,
+		"/%61%62%63%64%65%66%67": http.StatusRequestURITooLong,
+	}
+
+	for path, code := range testCases {
+		t.Run(path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+
+			assert.Equal(t, code, rec.Code)
+		})
+	}
+}
+
+func TestRouterVarsMapReused(t *testing.T) {
+	router := New()
+	router.HandleFunc("/user/:name", func(w http.ResponseWriter, r *http.Request) {
+		Vars(r)
+	})
+
+	r, _ := http.NewRequest("GET", "/user/gopher", nil)
+	w := &headerWriter{header: http.Header{}}
+	router.ServeHTTP(w, r)
+
+	// only the context and copy of the request are allocated
+	allocs := testing.AllocsPerRun(100, func() {
+		router.ServeHTTP(w, r)
+	})
+	assert.Equal(t, 2.0, allocs)
+}
@@ -0,0 +1,106 @@
+// Package routetest provides helpers for testing the routes registered with a
+// route.Router.
+package routetest
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"hawx.me/code/route"
+)
+
+var update = flag.Bool("routetest.update", false, "update golden route tables")
+
+// Golden compares the RouteTable of the router to the golden file at path,
+// failing the test with a diff of the routes that were added and removed if
+// they differ:
+//
+//   func TestRoutes(t *testing.T) {
+//     routetest.Golden(t, newRouter(), "testdata/routes.golden")
+//   }
+//
+//   --- testdata/routes.golden
+//   +++ routes
+//    GET /users name=users
+//   -DELETE /users/:id
+//   +GET /users/:id
+//
+// Running the tests with the flag -routetest.update writes the file instead,
+// creating it if needed, so that intended changes can be accepted.
+func Golden(t testing.TB, router *route.Router, path string) {
+	t.Helper()
+
+	table := router.RouteTable()
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("routetest: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(table), 0o644); err != nil {
+			t.Fatalf("routetest: %v", err)
+		}
+		return
+	}
+
+	golden, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("routetest: %v; run with -routetest.update to create it", err)
+	}
+
+	if string(golden) != table {
+		t.Errorf("routetest: routes differ from %s; run with -routetest.update to accept them\n--- %s\n+++ routes\n%s",
+			path, path, diff(lines(string(golden)), lines(table)))
+	}
+}
+
+// lines splits s into lines, ignoring a final newline.
+func lines(s string) []string {
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// diff returns the lines of a and b, prefixing those only in a with "-", those
+// only in b with "+" and those in both with " ". Lines in both are found as
+// the longest common subsequence of a and b.
+func diff(a, b []string) string {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and
+	// b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			out.WriteString(" " + a[i] + "\n")
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			out.WriteString("-" + a[i] + "\n")
+			i++
+		default:
+			out.WriteString("+" + b[j] + "\n")
+			j++
+		}
+	}
+
+	return out.String()
+}
@@ -0,0 +1,74 @@
+package routetest
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"hawx.me/code/route"
+)
+
+// recorder is a testing.TB that records failures rather than reporting them.
+type recorder struct {
+	testing.TB
+	errors []string
+	fatal  bool
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+	r.fatal = true
+}
+
+func TestGolden(t *testing.T) {
+	noop := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	path := filepath.Join(t.TempDir(), "routes.golden")
+
+	router := route.New()
+	router.Get("/users", noop, route.Name("users"))
+	router.Delete("/users/:id", noop)
+
+	rec := &recorder{TB: t}
+	Golden(rec, router, path)
+	if assert.True(t, rec.fatal) {
+		assert.Contains(t, rec.errors[0], "run with -routetest.update to create it")
+	}
+
+	*update = true
+	Golden(t, router, path)
+	*update = false
+
+	golden, _ := os.ReadFile(path)
+	assert.Equal(t, "GET /users name=users\nDELETE /users/:id\n", string(golden))
+
+	Golden(t, router, path)
+
+	changed := route.New()
+	changed.Get("/users", noop, route.Name("users"))
+	changed.Get("/users/:id", noop)
+	changed.Get("/users/:id/posts", noop)
+
+	rec = &recorder{TB: t}
+	Golden(rec, changed, path)
+	if assert.Len(t, rec.errors, 1) {
+		assert.Contains(t, rec.errors[0], "--- "+path+"\n+++ routes\n"+
+			" GET /users name=users\n"+
+			"-DELETE /users/:id\n"+
+			"+GET /users/:id\n"+
+			"+GET /users/:id/posts\n")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	assert.Equal(t, " a\n-b\n+x\n c\n+d\n", diff([]string{"a", "b", "c"}, []string{"a", "x", "c", "d"}))
+	assert.Equal(t, "", diff(nil, nil))
+}
@@ -0,0 +1,72 @@
+package route
+
+import (
+	"net/http"
+	"strings"
+)
+
+// A SlashPolicy decides how requests for a path with a trailing slash are
+// handled by a route registered without one.
+type SlashPolicy int
+
+const (
+	// MatchSlash handles the request with the route, as if the trailing slash
+	// was not there. This is the default.
+	MatchSlash SlashPolicy = iota + 1
+
+	// RedirectSlash redirects the request to the path without the trailing
+	// slash.
+	RedirectSlash
+
+	// StrictSlash does not match the request, so it is handled as if there was
+	// no route for the path.
+	StrictSlash
+)
+
+// TrailingSlash sets how the route handles requests for its path with a
+// trailing slash, overriding the TrailingSlash of the Router. Routes ending in a
+// catch-all parameter always match such requests. The option can be given
+// to a Group to set the policy for all of its routes:
+//
+//   api := router.Group("/api", route.TrailingSlash(route.StrictSlash))
+//   api.Get("/users", listUsers)
+//
+//   /api/users      match
+//   /api/users/     no match
+func TrailingSlash(policy SlashPolicy) Option {
+	return func(e *entry) {
+		e.slash = policy
+	}
+}
+
+// slashPolicy returns the SlashPolicy for the route of the endpoint, which may
+// be nil if no route accepted the request. Paths ending in a catch-all are
+// always matched, as the slash is part of the parameter.
+func (r *Router) slashPolicy(ep *endpoint, e *entry) SlashPolicy {
+	if strings.Contains(ep.pattern, "/*") {
+		return MatchSlash
+	}
+	if e != nil && e.slash != 0 {
+		return e.slash
+	}
+	if r.TrailingSlash != 0 {
+		return r.TrailingSlash
+	}
+
+	return MatchSlash
+}
+
+// hasTrailingSlash returns true if the path, which is not the root, ends in a
+// slash.
+func hasTrailingSlash(path string) bool {
+	return path != "/" && strings.HasSuffix(path, "/")
+}
+
+// redirectSlash redirects the request to its path without the trailing slash.
+func (r *Router) redirectSlash(w http.ResponseWriter, req *http.Request) {
+	url := *req.URL
+	url.Path = strings.TrimSuffix(url.Path, "/")
+	url.RawPath = strings.TrimSuffix(url.RawPath, "/")
+
+	r.redirect(w, req, url.String())
+}
@@ -0,0 +1,79 @@
+package route
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouterTrailingSlash(t *testing.T) {
+	router := New()
+	router.Handle("/site/about", &recordingHandler{})
+	router.Handle("/site/files/*path", &recordingHandler{}, TrailingSlash(StrictSlash))
+	router.Handle("/moved", &recordingHandler{}, TrailingSlash(RedirectSlash))
+
+	api := router.Group("/api", TrailingSlash(StrictSlash))
+	api.Get("/users", &recordingHandler{})
+	api.Get("/users/:name", &recordingHandler{}, TrailingSlash(MatchSlash))
+
+	cases := []struct {
+		path     string
+		code     int
+		location string
+	}{
+		{"/site/about", 200, ""},
+		{"/site/about/", 200, ""},
+		{"/site/files/", 200, ""},
+		{"/site/files/a/", 200, ""},
+		{"/moved", 200, ""},
+		{"/moved/?page=2", 301, "/moved?page=2"},
+		{"/api/users", 200, ""},
+		{"/api/users/", 404, ""},
+		{"/api/users/john/", 200, ""},
+	}
+
+	for _, tc := range cases {
+		r, _ := http.NewRequest("GET", tc.path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+
+		assert.Equal(t, tc.code, w.Code, tc.path)
+		assert.Equal(t, tc.location, w.Header().Get("Location"), tc.path)
+	}
+
+	r, _ := http.NewRequest("POST", "/moved/", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, 308, w.Code)
+}
+
+func TestRouterTrailingSlashDefault(t *testing.T) {
+	router := New()
+	router.TrailingSlash = StrictSlash
+	router.Handle("/about", &recordingHandler{})
+	router.Handle("/contact", &recordingHandler{}, TrailingSlash(MatchSlash))
+
+	serve := func(path string) int {
+		r, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	assert.Equal(t, 200, serve("/about"))
+	assert.Equal(t, 404, serve("/about/"))
+	assert.Equal(t, 200, serve("/contact/"))
+
+	_, err := router.Match("GET", "/about/")
+	assert.Equal(t, Error(404, ""), err)
+
+	m, err := router.Match("GET", "/contact/")
+	assert.Nil(t, err)
+	assert.Equal(t, "/contact", m.Pattern)
+
+	router = router.Freeze()
+	assert.Equal(t, 404, serve("/about/"))
+}
@@ -0,0 +1,180 @@
+package route
+
+import (
+	"net/http"
+	"strings"
+)
+
+// snapshot holds copies of the lookup trees of a Router, which requests are
+// routed with. A snapshot is never changed once made, so can be read without
+// locking. Instead when the routes change the router's snapshot is discarded,
+// and a new one made for the next request.
+type snapshot struct {
+	tree      *treeLookup
+	hosts     map[string]*treeLookup
+	notFounds map[string]*treeLookup
+	mappers   []ErrorMapper
+	before    []BeforeRequestFunc
+	after     []AfterFunc
+}
+
+// load returns the current snapshot of the router's routes, making it if the
+// routes have changed since the last was made.
+func (r *Router) load() *snapshot {
+	if s := r.snap.Load(); s != nil {
+		return s
+	}
+
+	// The snapshot is made and stored while holding the read lock, so that it
+	// can't be stored after a change has discarded it.
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if s := r.snap.Load(); s != nil {
+		return s
+	}
+
+	s := &snapshot{
+		tree:      r.tree.clone(),
+		hosts:     make(map[string]*treeLookup, len(r.hosts)),
+		notFounds: make(map[string]*treeLookup, len(r.notFounds)),
+		mappers:   r.mappers,
+		before:    r.before,
+		after:     r.after,
+	}
+	for host, tree := range r.hosts {
+		s.hosts[host] = tree.clone()
+	}
+	for host, tree := range r.notFounds {
+		s.notFounds[host] = tree.clone()
+	}
+
+	r.snap.Store(s)
+	return s
+}
+
+// changed discards the snapshot of the router's routes. It must be called with
+// the lock held whenever the routes are changed.
+func (r *Router) changed() {
+	r.snap.Store(nil)
+}
+
+// clone returns a compressed and packed copy of the tree, with copies of the
+// endpoints it holds, that finds routes without parameters in a map before
+// walking the tree.
+func (look *treeLookup) clone() *treeLookup {
+	root := look.root.clone(cloneEndpoint)
+
+	statics := map[string]Handler{}
+	root.addStatics("", statics)
+	root.compress()
+	root.pack()
+
+	return &treeLookup{root: root, matchers: look.matchers, statics: statics}
+}
+
+// cloneEndpoint returns a copy of the handler if it is an endpoint.
+func cloneEndpoint(h Handler) Handler {
+	if ep, ok := h.(*endpoint); ok {
+		return ep.clone()
+	}
+
+	return h
+}
+
+// clone returns a copy of the endpoint, with copies of its routes.
+func (ep *endpoint) clone() *endpoint {
+	c := *ep
+	c.routes = make([]*entry, len(ep.routes))
+	for i, e := range ep.routes {
+		copied := *e
+		c.routes[i] = &copied
+	}
+
+	return &c
+}
+
+// find returns the route to handle the request, appending its parameters to
+// ps. If there is no route the status that should be responded with is
+// returned, and if the path matched the endpoint.
+func (s *snapshot) find(req *http.Request, path string, ps *Params) (*endpoint, *entry, int) {
+	// as with http.ServeMux a path whose routes do not accept the request gives
+	// way to any other it matches that do, only if there is none is the most
+	// specific path responded to with 405 or 404
+	handle := s.get(req.Host, path, ps, req)
+	if handle == nil {
+		handle = s.get(req.Host, path, ps, nil)
+	}
+	if handle == nil {
+		return nil, nil, http.StatusNotFound
+	}
+
+	ep := handle.(*endpoint)
+	for k, v := range ep.vars {
+		*ps = append(*ps, Param{Key: k, Value: v})
+	}
+
+	e, status := ep.match(req)
+	return ep, e, status
+}
+
+// get finds the handler for the path that accepts req, as GetRequest does,
+// trying routes registered for the host before those registered for any host.
+func (s *snapshot) get(host, path string, ps *Params, req *http.Request) Handler {
+	if len(s.hosts) > 0 {
+		host = strings.ToLower(host)
+
+		tree, ok := s.hosts[host]
+		if !ok {
+			tree, ok = s.hosts[stripPort(host)]
+		}
+		if ok {
+			if handle := tree.GetRequest(path, ps, req); handle != nil {
+				return handle
+			}
+		}
+	}
+
+	return s.tree.GetRequest(path, ps, req)
+}
+
+// fixCase returns the path of the only route matching the path when case is
+// ignored, or false if there is not exactly one.
+func (s *snapshot) fixCase(host, path string) (string, bool) {
+	var found []string
+	if len(s.hosts) > 0 {
+		host = strings.ToLower(host)
+
+		tree, ok := s.hosts[host]
+		if !ok {
+			tree, ok = s.hosts[stripPort(host)]
+		}
+		if ok {
+			found = tree.FixCase(path, found)
+		}
+	}
+	found = s.tree.FixCase(path, found)
+
+	if len(found) != 1 {
+		return "", false
+	}
+	return found[0], true
+}
+
+// notFound returns the handler set for the deepest Group containing the path,
+// or nil if there is not one.
+func (s *snapshot) notFound(host, path string) http.Handler {
+	if len(s.notFounds) > 0 {
+		host = strings.ToLower(host)
+
+		for _, key := range []string{host, stripPort(host), ""} {
+			if tree, ok := s.notFounds[key]; ok {
+				if handler, _ := tree.Get(path); handler != nil {
+					return handler.(nilErrorHandler).Handler
+				}
+			}
+		}
+	}
+
+	return nil
+}
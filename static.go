@@ -0,0 +1,416 @@
+package route
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Static registers a handler serving files from root for GET requests to the
+// path, which must end in a catch-all parameter giving the name of the file:
+//
+//   router.Static("/assets/*path", http.Dir("public"))
+//
+//   /assets/css/site.css        serves public/css/site.css
+//   /assets/                    serves public/index.html
+//   /assets/../secrets          redirects to /secrets
+//
+// Files are sent with a strong ETag and their modification time, so requests
+// with If-None-Match or If-Modified-Since headers are responded to with 304 Not
+// Modified when the file has not changed.
+//
+// Requests can't reach files outside of root. Requests for a directory without
+// a trailing slash redirect to the path with one, and those for its index file
+// redirect to the directory. Directories are not listed unless the
+// DirectoryListing option is given, see also IndexFiles, FileNotFound and
+// CacheControl for changing how files are served.
+func (r *Router) Static(path string, root http.FileSystem, opts ...Option) {
+	r.Group("").Static(path, root, opts...)
+}
+
+// Static registers a handler serving files from root for GET requests to the
+// path, relative to the Group, as Router.Static does.
+func (g *Group) Static(path string, root http.FileSystem, opts ...Option) {
+	g.Get(path, g.fileServer("Static", path, root, opts), opts...)
+}
+
+// SPA registers a handler serving the files of a single-page app from root for
+// GET requests to the path, which must end in a catch-all parameter. Files are
+// served as by Static, but requests for paths without an extension that do not
+// name a file are sent the index.html of root, so the app can route them:
+//
+//   router.SPA("/app/*path", http.Dir("dist"))
+//
+//   /app/main.js                serves dist/main.js
+//   /app/users/5                serves dist/index.html
+//   /app/missing.js             404 Not Found
+func (r *Router) SPA(path string, root http.FileSystem, opts ...Option) {
+	r.Group("").SPA(path, root, opts...)
+}
+
+// SPA registers a handler serving the files of a single-page app from root for
+// GET requests to the path, relative to the Group, as Router.SPA does.
+func (g *Group) SPA(path string, root http.FileSystem, opts ...Option) {
+	s := g.fileServer("SPA", path, root, opts)
+	s.fallback = true
+
+	g.Get(path, s, opts...)
+}
+
+// StaticFS registers a handler serving files from fsys for GET requests to the
+// path, as Static does. So that embedded files can be served directly:
+//
+//   //go:embed public
+//   var public embed.FS
+//
+//   assets, _ := fs.Sub(public, "public")
+//   router.StaticFS("/assets/*path", assets)
+//
+// Files without a modification time, as embedded files are, are sent with an
+// ETag made from their content instead, so that clients can still revalidate
+// their cached copies.
+func (r *Router) StaticFS(path string, fsys fs.FS, opts ...Option) {
+	r.Static(path, http.FS(fsys), opts...)
+}
+
+// StaticFS registers a handler serving files from fsys for GET requests to the
+// path, relative to the Group, as Router.StaticFS does.
+func (g *Group) StaticFS(path string, fsys fs.FS, opts ...Option) {
+	g.Static(path, http.FS(fsys), opts...)
+}
+
+// DirectoryListing sets whether Static, StaticFS and SPA list the files of
+// directories without an index file. By default they respond with 404 Not
+// Found instead.
+func DirectoryListing(enabled bool) Option {
+	return func(e *entry) {
+		e.files = append(e.files, func(s *fileServer) {
+			s.listing = enabled
+		})
+	}
+}
+
+// IndexFiles sets the names of the files that Static, StaticFS and SPA serve
+// for requests for a directory, trying each in turn. By default only
+// "index.html" is served.
+func IndexFiles(names ...string) Option {
+	return func(e *entry) {
+		e.files = append(e.files, func(s *fileServer) {
+			s.index = names
+		})
+	}
+}
+
+// FileNotFound sets the handler Static, StaticFS and SPA use for requests for
+// files that do not exist, instead of responding with 404 Not Found.
+//
+//   router.Static("/docs/*path", http.Dir("docs"), route.FileNotFound(docsNotFound))
+func FileNotFound(handler http.Handler) Option {
+	return func(e *entry) {
+		e.files = append(e.files, func(s *fileServer) {
+			s.notFound = handler
+		})
+	}
+}
+
+// CacheControl sets the Cache-Control header Static, StaticFS and SPA send with
+// files having one of the extensions, or with every file if no extensions are
+// given. It can be given more than once:
+//
+//   router.Static("/assets/*path", http.Dir("public"),
+//     route.CacheControl("no-cache"),
+//     route.CacheControl("public, max-age=86400", ".css", ".js"))
+//
+// Values for an extension take precedence over those for every file.
+func CacheControl(value string, extensions ...string) Option {
+	return func(e *entry) {
+		e.files = append(e.files, func(s *fileServer) {
+			if s.cacheControl == nil {
+				s.cacheControl = map[string]string{}
+			}
+			if len(extensions) == 0 {
+				s.cacheControl[""] = value
+			}
+			for _, ext := range extensions {
+				s.cacheControl[strings.ToLower(ext)] = value
+			}
+		})
+	}
+}
+
+// Immutable sets the Cache-Control header Static, StaticFS and SPA send with
+// files to allow caching them forever. It should only be given to routes serving
+// files with a hash of their content in their name, so that each change is
+// served from a new path:
+//
+//   router.Static("/assets/*path", http.Dir("dist/assets"), route.Immutable())
+func Immutable() Option {
+	return CacheControl("public, max-age=31536000, immutable")
+}
+
+// fileServer returns a fileServer for the path, which must end in a catch-all
+// parameter naming the file, panicking with the name of fn if it does not. It
+// is configured by the options of the Group, then opts.
+func (g *Group) fileServer(fn, path string, root http.FileSystem, opts []Option) *fileServer {
+	_, _, p := parsePattern(path)
+
+	i := strings.LastIndex(p, "/*")
+	if i < 0 {
+		panic("route: " + fn + " requires a path ending in a catch-all parameter: " + path)
+	}
+
+	s := &fileServer{
+		param: p[i+2:],
+		root:  root,
+		index: []string{"index.html"},
+	}
+
+	e := &entry{}
+	for _, opt := range append(append([]Option{}, g.opts...), opts...) {
+		opt(e)
+	}
+	for _, fn := range e.files {
+		fn(s)
+	}
+
+	return s
+}
+
+// fileServer serves the file named by a catch-all parameter.
+type fileServer struct {
+	param        string
+	root         http.FileSystem
+	index        []string
+	listing      bool
+	notFound     http.Handler
+	cacheControl map[string]string
+
+	// fallback is set to serve the index for paths without an extension that
+	// do not name a file.
+	fallback bool
+
+	// etags holds the ETags of files without a modification time, which are
+	// assumed not to change, by name.
+	etags sync.Map
+}
+
+func (s *fileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name, err := FileVar(r, s.param)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	if s.fallback && path.Ext(name) == "" && !s.exists("/"+name) {
+		name = ""
+	}
+	name = path.Clean("/" + name)
+
+	f, err := s.root.Open(name)
+	if err != nil {
+		s.error(w, r, err)
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		s.error(w, r, err)
+		return
+	}
+
+	// the trailing slash is not part of the parameter, so is checked for in
+	// the path of the request, to tell requests for a directory from those
+	// for its index
+	slash := strings.HasSuffix(r.URL.Path, "/")
+
+	if info.IsDir() {
+		if !slash && name != "/" {
+			localRedirect(w, r, path.Base(r.URL.Path)+"/")
+			return
+		}
+
+		for _, index := range s.index {
+			ff, err := s.root.Open(path.Join(name, index))
+			if err != nil {
+				continue
+			}
+			defer ff.Close()
+
+			if fi, err := ff.Stat(); err == nil && !fi.IsDir() {
+				s.serveFile(w, r, path.Join(name, index), ff, fi)
+				return
+			}
+		}
+
+		if !s.listing {
+			s.error(w, r, fs.ErrNotExist)
+			return
+		}
+
+		r2 := new(http.Request)
+		*r2 = *r
+		r2.URL = new(url.URL)
+		*r2.URL = *r.URL
+		r2.URL.Path = strings.TrimSuffix(name, "/") + "/"
+		r2.URL.RawPath = ""
+
+		http.FileServer(s.root).ServeHTTP(w, r2)
+		return
+	}
+
+	if slash && name != "/" {
+		localRedirect(w, r, "../"+path.Base(name))
+		return
+	}
+	for _, index := range s.index {
+		if path.Base(name) == index {
+			localRedirect(w, r, "./")
+			return
+		}
+	}
+
+	s.serveFile(w, r, name, f, info)
+}
+
+// serveFile writes the named file, setting its Cache-Control and ETag.
+func (s *fileServer) serveFile(w http.ResponseWriter, r *http.Request, name string, f http.File, info fs.FileInfo) {
+	if value, ok := s.cacheControl[strings.ToLower(path.Ext(name))]; ok {
+		w.Header().Set("Cache-Control", value)
+	} else if value, ok := s.cacheControl[""]; ok {
+		w.Header().Set("Cache-Control", value)
+	}
+
+	if etag := s.etag(name, f, info); etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+
+	http.ServeContent(w, r, info.Name(), info.ModTime(), f)
+}
+
+// error responds to a request for a file that could not be opened.
+func (s *fileServer) error(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		if s.notFound != nil {
+			s.notFound.ServeHTTP(w, r)
+			return
+		}
+		http.Error(w, "404 page not found", http.StatusNotFound)
+	case errors.Is(err, fs.ErrPermission):
+		http.Error(w, "403 Forbidden", http.StatusForbidden)
+	default:
+		http.Error(w, "500 Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// exists returns true if the file or directory with the name exists.
+func (s *fileServer) exists(name string) bool {
+	f, err := s.root.Open(path.Clean(name))
+	if err != nil {
+		return false
+	}
+
+	f.Close()
+	return true
+}
+
+// etag returns an ETag for the named file, made from its size and modification
+// time, or if it has no modification time from its content.
+func (s *fileServer) etag(name string, f http.File, info fs.FileInfo) string {
+	if modtime := info.ModTime(); !modtime.IsZero() {
+		return `"` + strconv.FormatInt(modtime.UnixNano(), 36) + "-" + strconv.FormatInt(info.Size(), 36) + `"`
+	}
+	if etag, ok := s.etags.Load(name); ok {
+		return etag.(string)
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return ""
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return ""
+	}
+
+	etag := `"` + base64.RawURLEncoding.EncodeToString(hash.Sum(nil)[:16]) + `"`
+	s.etags.Store(name, etag)
+	return etag
+}
+
+// ErrUnsafePath is returned by FileVar and CleanFilePath for paths that could
+// name a file outside of the directory they are relative to. It responds with
+// 400 Bad Request when returned from a handler.
+var ErrUnsafePath = Error(http.StatusBadRequest, "unsafe path")
+
+// FileVar returns the value of the parameter with the name, usually a
+// catch-all, as a path that is safe to open relative to a directory. It is
+// decoded, if the router has not already decoded it, then checked by
+// CleanFilePath:
+//
+//   router.HandleFunc("/files/*path", func(w http.ResponseWriter, r *http.Request) error {
+//     name, err := route.FileVar(r, "path")
+//     if err != nil {
+//       return err
+//     }
+//
+//     http.ServeFile(w, r, filepath.Join("files", filepath.FromSlash(name)))
+//     return nil
+//   })
+func FileVar(r *http.Request, name string) (string, error) {
+	value, err := lookupVar(r, name)
+	if err != nil {
+		return "", err
+	}
+
+	if m := getMatch(r); m != nil && !m.decoded {
+		if value, err = url.PathUnescape(value); err != nil {
+			return "", ErrUnsafePath
+		}
+	}
+
+	return CleanFilePath(value)
+}
+
+// CleanFilePath returns the decoded path p cleaned and without a leading
+// slash, so "/a/./b" becomes "a/b". It returns ErrUnsafePath if p contains a
+// ".." element, separated by either '/' or '\', a NUL byte, or a
+// percent-encoded '.', '/', '\' or NUL that may be decoded again later.
+func CleanFilePath(p string) (string, error) {
+	if strings.IndexByte(p, 0) >= 0 {
+		return "", ErrUnsafePath
+	}
+
+	lower := strings.ToLower(p)
+	for _, encoded := range []string{"%2e", "%2f", "%5c", "%00"} {
+		if strings.Contains(lower, encoded) {
+			return "", ErrUnsafePath
+		}
+	}
+
+	for _, element := range strings.FieldsFunc(p, func(r rune) bool { return r == '/' || r == '\\' }) {
+		if element == ".." {
+			return "", ErrUnsafePath
+		}
+	}
+
+	return strings.TrimPrefix(path.Clean("/"+p), "/"), nil
+}
+
+// localRedirect redirects the request to a path relative to it, keeping the
+// query. Taken from net/http.
+func localRedirect(w http.ResponseWriter, r *http.Request, newPath string) {
+	if q := r.URL.RawQuery; q != "" {
+		newPath += "?" + q
+	}
+	w.Header().Set("Location", newPath)
+	w.WriteHeader(http.StatusMovedPermanently)
+}
@@ -0,0 +1,292 @@
+package route
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var staticFiles = fstest.MapFS{
+	"index.html":        {Data: []byte("<h1>Home</h1>")},
+	"css/site.css":      {Data: []byte("body {}")},
+	"docs/index.html":   {Data: []byte("<h1>Docs</h1>")},
+	"docs/a b.txt":      {Data: []byte("spaced")},
+	"images/.gitignore": {Data: []byte("")},
+}
+
+func TestRouterStatic(t *testing.T) {
+	router := New()
+	router.Static("/assets/*path", http.FS(staticFiles))
+	router.Group("/v2").Static("/static/{file...}", http.FS(staticFiles))
+
+	cases := []struct {
+		path     string
+		code     int
+		body     string
+		location string
+	}{
+		{"/assets/css/site.css", 200, "body {}", ""},
+		{"/assets/", 200, "<h1>Home</h1>", ""},
+		{"/assets", 200, "<h1>Home</h1>", ""},
+		{"/assets/docs/", 200, "<h1>Docs</h1>", ""},
+		{"/assets/docs", 301, "", "docs/"},
+		{"/assets/docs/index.html", 301, "", "./"},
+		{"/assets/docs/a%20b.txt", 200, "spaced", ""},
+		{"/assets/missing.css", 404, "404 page not found\n", ""},
+		{"/v2/static/css/site.css", 200, "body {}", ""},
+	}
+
+	for _, tc := range cases {
+		r := httptest.NewRequest("GET", tc.path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+
+		assert.Equal(t, tc.code, w.Code, tc.path)
+		if tc.body != "" {
+			assert.Equal(t, tc.body, w.Body.String(), tc.path)
+		}
+		assert.Equal(t, tc.location, w.Header().Get("Location"), tc.path)
+	}
+
+	r := httptest.NewRequest("POST", "/assets/css/site.css", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	assert.Equal(t, 405, w.Code)
+
+	assert.Panics(t, func() {
+		router.Static("/files/:name", http.FS(staticFiles))
+	})
+}
+
+func TestRouterStaticFS(t *testing.T) {
+	files := fstest.MapFS{
+		"public/index.html":      {Data: []byte("<h1>Home</h1>")},
+		"public/css/site.css":    {Data: []byte("body {}")},
+		"public/docs/index.html": {Data: []byte("<h1>Docs</h1>")},
+		"public/old.txt":         {Data: []byte("old"), ModTime: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+	}
+	public, _ := fs.Sub(files, "public")
+
+	router := New()
+	router.StaticFS("/assets/*path", public)
+
+	serve := func(path string, header http.Header) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", path, nil)
+		for k, v := range header {
+			r.Header[k] = v
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve("/assets/css/site.css", nil)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "body {}", w.Body.String())
+	assert.Equal(t, "", w.Header().Get("Last-Modified"))
+
+	etag := w.Header().Get("ETag")
+	assert.Regexp(t, `^"[A-Za-z0-9_-]{22}"$`, etag)
+
+	w = serve("/assets/css/site.css", http.Header{"If-None-Match": {etag}})
+	assert.Equal(t, 304, w.Code)
+
+	w = serve("/assets/", nil)
+	assert.Equal(t, 200, w.Code)
+	assert.NotEqual(t, "", w.Header().Get("ETag"))
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+
+	w = serve("/assets/docs/", nil)
+	assert.Equal(t, 200, w.Code)
+	assert.NotEqual(t, "", w.Header().Get("ETag"))
+
+	w = serve("/assets/old.txt", nil)
+	assert.Equal(t, 200, w.Code)
+	assert.Regexp(t, `^"[0-9a-z]+-3"$`, w.Header().Get("ETag"))
+	assert.Equal(t, "Thu, 02 Jan 2020 03:04:05 GMT", w.Header().Get("Last-Modified"))
+
+	w = serve("/assets/missing.txt", nil)
+	assert.Equal(t, 404, w.Code)
+	assert.Equal(t, "", w.Header().Get("ETag"))
+}
+
+func TestRouterSPA(t *testing.T) {
+	router := New()
+	router.SPA("/app/*path", http.FS(staticFiles))
+
+	cases := []struct {
+		path string
+		code int
+		body string
+	}{
+		{"/app/css/site.css", 200, "body {}"},
+		{"/app/", 200, "<h1>Home</h1>"},
+		{"/app/users/5", 200, "<h1>Home</h1>"},
+		{"/app/users/5/", 200, "<h1>Home</h1>"},
+		{"/app/docs/", 200, "<h1>Docs</h1>"},
+		{"/app/missing.js", 404, "404 page not found\n"},
+	}
+
+	for _, tc := range cases {
+		r := httptest.NewRequest("GET", tc.path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+
+		assert.Equal(t, tc.code, w.Code, tc.path)
+		assert.Equal(t, tc.body, w.Body.String(), tc.path)
+	}
+
+	assert.Panics(t, func() {
+		router.SPA("/other", http.FS(staticFiles))
+	})
+}
+
+func TestRouterStaticOptions(t *testing.T) {
+	notFound := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(418)
+	})
+
+	router := New()
+	router.Static("/plain/*path", http.FS(staticFiles))
+	router.Static("/listed/*path", http.FS(staticFiles), DirectoryListing(true))
+	router.Static("/indexed/*path", http.FS(staticFiles), IndexFiles("home.html", "site.css"))
+
+	assets := router.Group("/assets", CacheControl("no-cache"), FileNotFound(notFound))
+	assets.Static("/*path", http.FS(staticFiles), CacheControl("max-age=60", ".CSS", ".js"))
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	assert.Equal(t, 404, serve("/plain/images/").Code)
+
+	w := serve("/listed/images/")
+	assert.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), `<a href=".gitignore">.gitignore</a>`)
+
+	w = serve("/indexed/css/")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "body {}", w.Body.String())
+	assert.Equal(t, 301, serve("/indexed/css/site.css").Code)
+	assert.Equal(t, 200, serve("/indexed/index.html").Code)
+
+	w = serve("/assets/css/site.css")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "max-age=60", w.Header().Get("Cache-Control"))
+
+	w = serve("/assets/")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+
+	assert.Equal(t, 418, serve("/assets/missing.css").Code)
+	assert.Equal(t, 418, serve("/assets/images/").Code)
+	assert.Equal(t, 404, serve("/plain/missing.css").Code)
+}
+
+func TestRouterStaticConditional(t *testing.T) {
+	files := fstest.MapFS{
+		"app.js":       {Data: []byte("go()"), ModTime: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+		"app.1a2b3.js": {Data: []byte("go()"), ModTime: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+	}
+
+	router := New()
+	router.StaticFS("/files/*path", files)
+	router.StaticFS("/assets/*path", files, Immutable())
+
+	serve := func(path string, header http.Header) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", path, nil)
+		for k, v := range header {
+			r.Header[k] = v
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve("/files/app.js", nil)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "", w.Header().Get("Cache-Control"))
+	etag := w.Header().Get("ETag")
+
+	assert.Equal(t, 304, serve("/files/app.js", http.Header{"If-None-Match": {etag}}).Code)
+	assert.Equal(t, 200, serve("/files/app.js", http.Header{"If-None-Match": {`"other"`}}).Code)
+	assert.Equal(t, 304, serve("/files/app.js", http.Header{"If-Modified-Since": {"Thu, 02 Jan 2020 03:04:05 GMT"}}).Code)
+	assert.Equal(t, 200, serve("/files/app.js", http.Header{"If-Modified-Since": {"Wed, 01 Jan 2020 00:00:00 GMT"}}).Code)
+
+	w = serve("/assets/app.1a2b3.js", nil)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "public, max-age=31536000, immutable", w.Header().Get("Cache-Control"))
+}
+
+func TestCleanFilePath(t *testing.T) {
+	cases := map[string]string{
+		"":            "",
+		"a/b.txt":     "a/b.txt",
+		"/a/./b.txt":  "a/b.txt",
+		"a//b/":       "a/b",
+		"a..b/c":      "a..b/c",
+		"100%.txt":    "100%.txt",
+		"dir/.hidden": "dir/.hidden",
+	}
+
+	for p, expected := range cases {
+		cleaned, err := CleanFilePath(p)
+		assert.Nil(t, err, p)
+		assert.Equal(t, expected, cleaned, p)
+	}
+
+	for _, p := range []string{"..", "../etc/passwd", "a/../../b", `a\..\b`, "a\x00b", "%2e%2e/a", "a/%2E./b", "a%2fb", "a%5Cb", "a%00"} {
+		_, err := CleanFilePath(p)
+		assert.Equal(t, ErrUnsafePath, err, p)
+	}
+}
+
+func TestFileVar(t *testing.T) {
+	for _, decode := range []bool{false, true} {
+		var name string
+		var err error
+
+		router := New()
+		router.SkipClean = true
+		router.UnescapeVars = decode
+		router.HandleFunc("/files/*path", func(w http.ResponseWriter, r *http.Request) {
+			name, err = FileVar(r, "path")
+		})
+
+		serve := func(path string) {
+			r := httptest.NewRequest("GET", path, nil)
+			router.ServeHTTP(httptest.NewRecorder(), r)
+		}
+
+		serve("/files/a%20b/c.txt")
+		assert.Nil(t, err)
+		assert.Equal(t, "a b/c.txt", name)
+
+		for _, path := range []string{"/files/../secret", "/files/%2e%2e/secret", "/files/a%2f..%2fb", "/files/%252e%252e/secret", "/files/a%00"} {
+			serve(path)
+			assert.Equal(t, ErrUnsafePath, err, path)
+		}
+	}
+}
+
+func TestRouterStaticTraversal(t *testing.T) {
+	router := New()
+	router.SkipClean = true
+	router.Static("/assets/*path", http.FS(staticFiles))
+
+	for _, path := range []string{"/assets/../static.go", "/assets/%2e%2e/static.go", "/assets/css%2f..%2f..%2fstatic.go"} {
+		r := httptest.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+
+		assert.Equal(t, 400, w.Code, path)
+	}
+}
@@ -0,0 +1,135 @@
+package route
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// routeStats are the statistics recorded for a route.
+type routeStats struct {
+	hits   atomic.Uint64
+	errors atomic.Uint64
+	total  atomic.Int64
+	max    atomic.Int64
+}
+
+func (s *routeStats) record(d time.Duration, failed bool) {
+	s.hits.Add(1)
+	if failed {
+		s.errors.Add(1)
+	}
+	s.total.Add(int64(d))
+
+	for {
+		max := s.max.Load()
+		if int64(d) <= max || s.max.CompareAndSwap(max, int64(d)) {
+			break
+		}
+	}
+}
+
+// RouteStats are the statistics recorded for a route while RecordStats is set.
+type RouteStats struct {
+	// Pattern, Host, Name and Methods identify the route, as for RouteInfo.
+	Pattern string   `json:"pattern"`
+	Host    string   `json:"host,omitempty"`
+	Name    string   `json:"name,omitempty"`
+	Methods []string `json:"methods,omitempty"`
+
+	// Hits is the number of requests handled by the route.
+	Hits uint64 `json:"hits"`
+
+	// Errors is the number of requests for which the handler returned an error.
+	Errors uint64 `json:"errors"`
+
+	// MeanLatency and MaxLatency are the mean and longest times taken by the
+	// handler. In JSON they are given in nanoseconds.
+	MeanLatency time.Duration `json:"meanLatency"`
+	MaxLatency  time.Duration `json:"maxLatency"`
+}
+
+// Stats returns the statistics recorded for each route, in the order they
+// would be visited by Walk. Routes are included whether or not they have handled
+// any requests, so that those that are unused can be found. Statistics are only
+// recorded when RecordStats is set:
+//
+//   router.RecordStats = true
+//
+//   for _, s := range router.Stats() {
+//     log.Println(s.Pattern, s.Hits, s.Errors, s.MeanLatency)
+//   }
+func (r *Router) Stats() []RouteStats {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var stats []RouteStats
+	for _, ep := range r.sortedEndpoints() {
+		for _, e := range ep.routes {
+			s := RouteStats{
+				Pattern:    ep.pattern,
+				Host:       ep.host,
+				Name:       e.name,
+				Methods:    e.methods,
+				Hits:       e.stats.hits.Load(),
+				Errors:     e.stats.errors.Load(),
+				MaxLatency: time.Duration(e.stats.max.Load()),
+			}
+			if s.Hits > 0 {
+				s.MeanLatency = time.Duration(e.stats.total.Load() / int64(s.Hits))
+			}
+
+			stats = append(stats, s)
+		}
+	}
+
+	return stats
+}
+
+// PublishStats publishes the result of Stats as an expvar variable with the
+// name, so that it is served by expvar.Handler. Like expvar.Publish it panics if
+// the name is already in use.
+func (r *Router) PublishStats(name string) {
+	expvar.Publish(name, expvar.Func(func() any {
+		return r.Stats()
+	}))
+}
+
+// ExpvarHandler returns a handler serving the variables published with expvar,
+// as expvar.Handler does, along with the Stats of the router as "route.stats".
+// This is needed as expvar only registers its handler with
+// http.DefaultServeMux:
+//
+//   router.RecordStats = true
+//   router.Handle("GET /debug/vars", route.ExpvarHandler(router))
+//
+// The router may be nil, to serve only the variables published with expvar.
+func ExpvarHandler(router *Router) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		fmt.Fprint(w, "{\n")
+		first := true
+		expvar.Do(func(kv expvar.KeyValue) {
+			if !first {
+				fmt.Fprint(w, ",\n")
+			}
+			first = false
+			fmt.Fprintf(w, "%s: %s", strconv.Quote(kv.Key), kv.Value)
+		})
+
+		if router != nil {
+			stats, _ := json.Marshal(router.Stats())
+			if !first {
+				fmt.Fprint(w, ",\n")
+			}
+			fmt.Fprintf(w, "%s: %s", strconv.Quote("route.stats"), stats)
+		}
+
+		fmt.Fprint(w, "\n}\n")
+	})
+}
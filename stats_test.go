@@ -0,0 +1,100 @@
+package route
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouterStats(t *testing.T) {
+	router := New()
+	router.RecordStats = true
+	router.ErrorHandler = IgnoreErrors
+	router.HandleFunc("GET /users/:id", func(w http.ResponseWriter, r *http.Request) error {
+		if Vars(r)["id"] == "0" {
+			return errors.New("no user")
+		}
+		return nil
+	}, Name("user"), Formats("json"))
+	router.HandleFunc("/unused", func(w http.ResponseWriter, r *http.Request) {})
+
+	for _, path := range []string{"/users/1", "/users/2.json", "/users/0", "/missing"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	stats := router.Stats()
+	if assert.Len(t, stats, 2) {
+		assert.Equal(t, "/unused", stats[0].Pattern)
+		assert.Equal(t, uint64(0), stats[0].Hits)
+
+		assert.Equal(t, "/users/:id", stats[1].Pattern)
+		assert.Equal(t, "user", stats[1].Name)
+		assert.Equal(t, []string{"GET"}, stats[1].Methods)
+		assert.Equal(t, uint64(3), stats[1].Hits)
+		assert.Equal(t, uint64(1), stats[1].Errors)
+		assert.True(t, stats[1].MaxLatency >= stats[1].MeanLatency)
+	}
+
+	frozen := router.Freeze()
+	frozen.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/unused", nil))
+	assert.Equal(t, uint64(1), frozen.Stats()[0].Hits)
+	assert.Equal(t, uint64(0), router.Stats()[0].Hits)
+}
+
+func TestRouterStatsNotRecorded(t *testing.T) {
+	router := New()
+	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	assert.Equal(t, uint64(0), router.Stats()[0].Hits)
+}
+
+func TestRouterPublishStats(t *testing.T) {
+	router := New()
+	router.RecordStats = true
+	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {})
+	router.PublishStats("route_test_stats")
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	var stats []RouteStats
+	assert.Nil(t, json.Unmarshal([]byte(expvar.Get("route_test_stats").String()), &stats))
+	assert.Equal(t, uint64(1), stats[0].Hits)
+}
+
+func TestExpvarHandler(t *testing.T) {
+	router := New()
+	router.RecordStats = true
+	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {})
+	router.Handle("GET /debug/vars", ExpvarHandler(router))
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/debug/vars", nil))
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+
+	var vars map[string]json.RawMessage
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &vars))
+	assert.Contains(t, vars, "memstats")
+	assert.Contains(t, vars, "cmdline")
+
+	var stats []RouteStats
+	assert.Nil(t, json.Unmarshal(vars["route.stats"], &stats))
+	if assert.Len(t, stats, 2) {
+		assert.Equal(t, "/", stats[0].Pattern)
+		assert.Equal(t, uint64(1), stats[0].Hits)
+	}
+
+	w = httptest.NewRecorder()
+	ExpvarHandler(nil).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	vars = nil
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &vars))
+	assert.NotContains(t, vars, "route.stats")
+}
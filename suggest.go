@@ -0,0 +1,145 @@
+package route
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// maxSuggestions is the most routes suggested for a request.
+const maxSuggestions = 5
+
+type suggestionsKey struct{}
+
+// Suggestions returns the patterns of routes similar to the request, most
+// similar first, when it is passed to the NotFoundHandler of a Router with
+// SuggestRoutes set. Routes are similar when their static segments are a small
+// number of edits from those of the path, ignoring case, or when the path is
+// missing their last segment:
+//
+//   router.SuggestRoutes = true
+//   router.Get("/users/:id", userHandler)
+//   router.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+//     w.WriteHeader(http.StatusNotFound)
+//     fmt.Fprintf(w, "did you mean %v?", route.Suggestions(r))
+//   })
+//
+//   /usres/5         did you mean [/users/:id]?
+//   /Users/5         did you mean [/users/:id]?
+//   /users           did you mean [/users/:id]?
+func Suggestions(r *http.Request) []string {
+	suggestions, _ := r.Context().Value(suggestionsKey{}).([]string)
+	return suggestions
+}
+
+// suggest returns the patterns of the routes for the host similar to the path,
+// as described for Suggestions.
+func (r *Router) suggest(host, path string) []string {
+	type suggestion struct {
+		pattern  string
+		distance int
+	}
+
+	host = strings.ToLower(host)
+	segments := splitSegments(path)
+
+	r.mu.RLock()
+	var found []suggestion
+	for _, ep := range r.endpoints {
+		if ep.vars != nil || (ep.host != "" && ep.host != host && ep.host != stripPort(host)) {
+			continue
+		}
+
+		if distance, ok := segmentsDistance(segments, splitSegments(ep.pattern)); ok {
+			found = append(found, suggestion{ep.host + ep.pattern, distance})
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].distance != found[j].distance {
+			return found[i].distance < found[j].distance
+		}
+		return found[i].pattern < found[j].pattern
+	})
+
+	if len(found) > maxSuggestions {
+		found = found[:maxSuggestions]
+	}
+
+	var patterns []string
+	for _, s := range found {
+		patterns = append(patterns, s.pattern)
+	}
+	return patterns
+}
+
+// splitSegments returns the segments of the path, ignoring any leading or
+// trailing slash.
+func splitSegments(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// segmentsDistance returns the number of edits needed to make the segments of a
+// path match those of a pattern, and false if they are not similar. Segments
+// with parameters match any segment, and a catch-all parameter any that
+// remain. A path missing the last segment of the pattern is one edit away.
+func segmentsDistance(path, pattern []string) (int, bool) {
+	if n := len(pattern); n > 0 && strings.HasPrefix(pattern[n-1], "*") {
+		if len(path) < n-1 {
+			return 0, false
+		}
+		return segmentsDistance(path[:n-1], pattern[:n-1])
+	}
+
+	distance := 0
+	switch len(pattern) - len(path) {
+	case 0:
+	case 1:
+		distance = 1
+		pattern = pattern[:len(path)]
+	default:
+		return 0, false
+	}
+
+	for i, segment := range pattern {
+		if strings.ContainsAny(segment, ":*{<") {
+			continue
+		}
+
+		d := editDistance(strings.ToLower(path[i]), strings.ToLower(segment))
+		if d > 2 || 2*d > len(segment) {
+			return 0, false
+		}
+		distance += d
+	}
+
+	return distance, true
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
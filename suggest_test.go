@@ -0,0 +1,56 @@
+package route
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouterSuggestRoutes(t *testing.T) {
+	noop := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	router := New()
+	router.SuggestRoutes = true
+	router.Get("/users/:id", noop)
+	router.Get("/users/:id/posts", noop)
+	router.Get("/teams", noop)
+	router.Get("/files/*path", noop)
+	router.Host("api.example.com").Get("/status", noop)
+
+	var suggestions []string
+	router.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		suggestions = Suggestions(r)
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	testCases := map[string]struct {
+		path     string
+		expected []string
+	}{
+		"typo":            {"/usres/5", []string{"/users/:id", "/users/:id/posts"}},
+		"wrong case":      {"/Users/5/Posts", []string{"/users/:id/posts"}},
+		"missing segment": {"/users", []string{"/users/:id"}},
+		"catch-all":       {"/flies/a/b/c", []string{"/files/*path"}},
+		"other host":      {"/stats", nil},
+		"nothing close":   {"/accounts/5", nil},
+		"close":           {"/team", []string{"/teams"}},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			suggestions = nil
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest("GET", tc.path, nil))
+
+			assert.Equal(t, http.StatusNotFound, rec.Code)
+			assert.Equal(t, tc.expected, suggestions)
+		})
+	}
+
+	req := httptest.NewRequest("GET", "http://api.example.com/stats", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, []string{"api.example.com/status"}, suggestions)
+}
@@ -0,0 +1,59 @@
+package route
+
+import (
+	"context"
+	"net/http"
+)
+
+// A Tracer starts a span for each request served by a Router it is set on. It
+// is the point at which a tracing library, such as OpenTelemetry, is
+// integrated, without the router depending on it:
+//
+//   type otelTracer struct{ trace.Tracer }
+//
+//   func (t otelTracer) Start(ctx context.Context, r *http.Request) (context.Context, route.Span) {
+//     ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(r.Header))
+//     ctx, span := t.Tracer.Start(ctx, r.Method, trace.WithSpanKind(trace.SpanKindServer))
+//     return ctx, otelSpan{span}
+//   }
+//
+//   router.Tracer = otelTracer{otel.Tracer("server")}
+//
+// The context returned by Start is used for the request passed to the handler,
+// so spans started by the handler are children of the request's span.
+type Tracer interface {
+	Start(ctx context.Context, r *http.Request) (context.Context, Span)
+}
+
+// A Span records the handling of a request.
+//
+// When a route matches the span is named with the method and pattern, as
+// "GET /users/:id", and given the attributes "http.route", set to the pattern,
+// and "http.route.param.<name>" for each parameter of the route, set to its
+// unescaped value. It is ended with the status of the response and any error
+// returned by the handler.
+type Span interface {
+	SetName(name string)
+	SetAttribute(key, value string)
+	End(status int, err error)
+}
+
+// serveTraced serves the request within a span started by the Tracer.
+func (r *Router) serveTraced(w http.ResponseWriter, req *http.Request) {
+	ctx, span := r.Tracer.Start(req.Context(), req)
+	req, o := observe(req.WithContext(ctx), true)
+	rec := &responseRecorder{ResponseWriter: w}
+
+	defer func() {
+		if o.pattern != "" {
+			span.SetName(req.Method + " " + o.pattern)
+			span.SetAttribute("http.route", o.pattern)
+			for k, v := range o.vars {
+				span.SetAttribute("http.route.param."+k, v)
+			}
+		}
+		span.End(rec.code(), o.err)
+	}()
+
+	r.serve(rec, req)
+}
@@ -0,0 +1,96 @@
+package route
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type spanKey struct{}
+
+type testTracer struct {
+	spans []*testSpan
+}
+
+func (t *testTracer) Start(ctx context.Context, r *http.Request) (context.Context, Span) {
+	span := &testSpan{name: r.Method, attrs: map[string]string{}}
+	t.spans = append(t.spans, span)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+type testSpan struct {
+	name   string
+	attrs  map[string]string
+	status int
+	err    error
+	ended  bool
+}
+
+func (s *testSpan) SetName(name string)            { s.name = name }
+func (s *testSpan) SetAttribute(key, value string) { s.attrs[key] = value }
+func (s *testSpan) End(status int, err error)      { s.status, s.err, s.ended = status, err, true }
+
+func TestRouterTracer(t *testing.T) {
+	tracer := &testTracer{}
+	failure := errors.New("failed")
+
+	var inHandler interface{}
+
+	router := New()
+	router.Tracer = tracer
+	router.ErrorHandler = IgnoreErrors
+	router.HandleFunc("GET /users/:name", func(w http.ResponseWriter, r *http.Request) {
+		inHandler = r.Context().Value(spanKey{})
+		w.WriteHeader(201)
+	})
+	router.HandleFunc("GET /fail", func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(500)
+		return failure
+	})
+
+	for _, path := range []string{"/users/john%20doe", "/fail", "/missing"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	if assert.Len(t, tracer.spans, 3) {
+		span := tracer.spans[0]
+		assert.Equal(t, "GET /users/:name", span.name)
+		assert.Equal(t, map[string]string{
+			"http.route":            "/users/:name",
+			"http.route.param.name": "john doe",
+		}, span.attrs)
+		assert.Equal(t, 201, span.status)
+		assert.Nil(t, span.err)
+		assert.True(t, span.ended)
+		assert.True(t, span == inHandler)
+
+		span = tracer.spans[1]
+		assert.Equal(t, "GET /fail", span.name)
+		assert.Equal(t, 500, span.status)
+		assert.Equal(t, failure, span.err)
+
+		span = tracer.spans[2]
+		assert.Equal(t, "GET", span.name)
+		assert.Empty(t, span.attrs)
+		assert.Equal(t, 404, span.status)
+	}
+}
+
+func TestRouterTracerWithMetrics(t *testing.T) {
+	metrics := NewMetrics()
+
+	router := New()
+	router.Tracer = &testTracer{}
+	router.HandleFunc("GET /users/:name", func(w http.ResponseWriter, r *http.Request) {})
+
+	metrics.Instrument(router).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/x", nil))
+
+	var b strings.Builder
+	metrics.WriteTo(&b)
+	assert.Contains(t, b.String(), `http_requests_total{method="GET",pattern="/users/:name",status="200"} 1`)
+}
@@ -0,0 +1,53 @@
+package route
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// A Registrar registers routes, it is implemented by Router and Group.
+type Registrar interface {
+	Handle(path string, handler interface{}, opts ...Option)
+}
+
+var (
+	_ Registrar = (*Router)(nil)
+	_ Registrar = (*Group)(nil)
+)
+
+// HandleJSON registers a function taking and returning typed values as the
+// handler for the path. The request is bound to a Req with Bind, which must be a
+// struct, then fn is called and the Resp it returns written as JSON:
+//
+//   type getThing struct {
+//     ID int64 `route:"id"`
+//   }
+//
+//   route.HandleJSON(router, "GET /things/:id", func(ctx context.Context, req getThing) (Thing, error) {
+//     return things.Find(ctx, req.ID)
+//   })
+//
+// Errors from binding the request and returned by fn are passed to the
+// ErrorHandler of the router, as for any Handler.
+func HandleJSON[Req, Resp any](r Registrar, path string, fn func(ctx context.Context, req Req) (Resp, error), opts ...Option) {
+	r.Handle(path, jsonHandler(fn), opts...)
+}
+
+// jsonHandler returns a HandlerFunc calling fn as described by HandleJSON.
+func jsonHandler[Req, Resp any](fn func(context.Context, Req) (Resp, error)) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req Req
+		if err := Bind(r, &req); err != nil {
+			return err
+		}
+
+		resp, err := fn(r.Context(), req)
+		if err != nil {
+			return err
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		return json.NewEncoder(w).Encode(resp)
+	}
+}
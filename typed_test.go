@@ -0,0 +1,74 @@
+package route
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type thingRequest struct {
+	ID   int64  `route:"id" json:"-"`
+	Name string `json:"name"`
+}
+
+type thingResponse struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestHandleJSON(t *testing.T) {
+	router := New()
+	router.MapError(context.Canceled, 499, "")
+
+	update := func(ctx context.Context, req thingRequest) (thingResponse, error) {
+		if req.Name == "" {
+			return thingResponse{}, Error(http.StatusUnprocessableEntity, "name is required")
+		}
+		if req.Name == "cancel" {
+			return thingResponse{}, context.Canceled
+		}
+		return thingResponse{ID: req.ID, Name: req.Name}, nil
+	}
+
+	HandleJSON(router, "PUT /things/:id", update)
+	HandleJSON(router.Group("/v2"), "PUT /things/:id", update)
+
+	serve := func(target, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("PUT", target, strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve("/things/5", `{"name": "widget"}`)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"id": 5, "name": "widget"}`, w.Body.String())
+
+	w = serve("/v2/things/6", `{"name": "gadget"}`)
+	assert.Equal(t, 200, w.Code)
+	assert.JSONEq(t, `{"id": 6, "name": "gadget"}`, w.Body.String())
+
+	assert.Equal(t, 400, serve("/things/five", `{"name": "widget"}`).Code)
+	assert.Equal(t, 400, serve("/things/5", `{"name": `).Code)
+	assert.Equal(t, 422, serve("/things/5", `{}`).Code)
+	assert.Equal(t, 499, serve("/things/5", `{"name": "cancel"}`).Code)
+}
+
+func TestHandleJSONRoutes(t *testing.T) {
+	router := New()
+	HandleJSON(router, "GET /things/:id", func(ctx context.Context, req thingRequest) (thingResponse, error) {
+		return thingResponse{}, errors.New("not implemented")
+	})
+
+	routes := router.Routes()
+	assert.Len(t, routes, 1)
+	assert.Equal(t, "/things/:id", routes[0].Pattern)
+	assert.Regexp(t, `^typed_test\.go:\d+$`, routes[0].Source)
+}
@@ -0,0 +1,207 @@
+package route
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/url"
+	"strings"
+)
+
+// URL returns the URL for the route registered with the given name. The pairs
+// are alternating parameter names and values, any name that is not a parameter
+// of the route is added to the query string instead. If the route was
+// registered for a host the URL includes it.
+//
+//   router.Handle("/user/:name", userHandler, route.Name("user.show"))
+//
+//   u, _ := router.URL("user.show", "name", "gopher", "tab", "posts")
+//   u.String() // "/user/gopher?tab=posts"
+func (r *Router) URL(name string, pairs ...string) (*url.URL, error) {
+	if len(pairs)%2 != 0 {
+		return nil, errors.New("route: odd number of parameter name/value pairs")
+	}
+
+	r.mu.RLock()
+	ep, ok := r.names[name]
+	r.mu.RUnlock()
+
+	if !ok {
+		return nil, errors.New("route: no route named " + name)
+	}
+	pattern := ep.pattern
+
+	params := map[string]bool{}
+	for _, param := range patternParams(pattern) {
+		params[param] = true
+	}
+
+	vars := map[string]string{}
+	query := url.Values{}
+	for i := 0; i < len(pairs); i += 2 {
+		if params[pairs[i]] {
+			vars[pairs[i]] = pairs[i+1]
+		} else {
+			query.Add(pairs[i], pairs[i+1])
+		}
+	}
+
+	r.mu.RLock()
+	u, err := build(pattern, vars, query, r.tree.matchers, false)
+	r.mu.RUnlock()
+
+	if err == nil {
+		u.Host = ep.host
+	}
+
+	return u, err
+}
+
+// FuncMap returns functions for use in templates. It contains a single function
+// "url" which builds the URL for a named route, taking the same arguments as
+// URL. Values are formatted as by fmt.Sprint, so need not be strings:
+//
+//   <a href="{{url "user.show" "name" .User}}">profile</a>
+func (r *Router) FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"url": func(name string, pairs ...interface{}) (string, error) {
+			strs := make([]string, len(pairs))
+			for i, pair := range pairs {
+				strs[i] = fmt.Sprint(pair)
+			}
+
+			u, err := r.URL(name, strs...)
+			if err != nil {
+				return "", err
+			}
+
+			return u.String(), nil
+		},
+	}
+}
+
+// Build returns the URL for a path pattern, as registered with Handle, with
+// each parameter replaced by its value from vars and query encoded as the query
+// string. Parameters may be written in either syntax accepted by Handle, so
+// "/user/{name}" and "/files/{path...}" can be built as well as "/user/:name".
+// Named parameter values are escaped so that they fill exactly one path
+// segment, catch-all values are escaped segment by segment so any '/' they
+// contain is kept.
+//
+//   u, _ := route.Build("/files/:owner/*path", map[string]string{
+//     "owner": "john doe",
+//     "path":  "photos/cat?.jpg",
+//   }, url.Values{"size": {"small"}})
+//
+//   u.String() // "/files/john%20doe/photos/cat%3F.jpg?size=small"
+//
+// An error is returned if a named parameter has no value, or a value that does
+// not satisfy its constraint, or if the pattern is invalid. Constraints naming
+// a MatcherFunc are not checked, use Router.URL to check those.
+func Build(pattern string, vars map[string]string, query url.Values) (*url.URL, error) {
+	return build(pattern, vars, query, nil, false)
+}
+
+// build is Build checking constraints naming matchers against the given
+// matchers, if they are not nil. If rawGreedy is set the catch-all value is
+// taken as escaped, as it was in the request, so that an encoded '/' stays
+// within its segment.
+func build(pattern string, vars map[string]string, query url.Values, matchers map[string]MatcherFunc, rawGreedy bool) (*url.URL, error) {
+	if pattern == "" || pattern[0] != '/' {
+		return nil, errors.New("route: path must begin with '/'")
+	}
+
+	parts := strings.Split(pattern, "/")[1:]
+	escaped := make([]string, len(parts))
+
+	for i, part := range parts {
+		if name, ok := greedyParam(part); ok {
+			if i != len(parts)-1 {
+				return nil, errors.New("route: path after greedy parameter")
+			}
+			if rawGreedy {
+				escaped[i] = escapeRawGreedy(vars[name])
+			} else {
+				escaped[i] = escapeGreedy(vars[name])
+			}
+			continue
+		}
+		if part == "{$}" {
+			if i != len(parts)-1 {
+				return nil, errors.New("route: path after {$}")
+			}
+			escaped[i] = ""
+			continue
+		}
+
+		seg, ok := parseSegment(part)
+		if !ok {
+			escaped[i] = part
+			continue
+		}
+
+		value, ok := vars[seg.name]
+		if !ok || value == "" {
+			return nil, errors.New("route: missing value for parameter " + seg.name)
+		}
+		if seg.constraint != "" && (seg.constraint[0] != '<' || matchers != nil) {
+			if _, ok := newConstraint(seg.constraint, matchers)(value); !ok {
+				return nil, errors.New("route: value for parameter " + seg.name + " does not satisfy " + seg.constraint)
+			}
+		}
+		escaped[i] = seg.prefix + url.PathEscape(value) + seg.suffix
+	}
+
+	rawPath := "/" + strings.Join(escaped, "/")
+	path, err := url.PathUnescape(rawPath)
+	if err != nil {
+		return nil, err
+	}
+
+	u := &url.URL{Path: path, RawQuery: query.Encode()}
+	if rawPath != u.EscapedPath() {
+		u.RawPath = rawPath
+	}
+
+	return u, nil
+}
+
+// patternParams returns the names of the parameters in a path pattern.
+func patternParams(pattern string) []string {
+	var params []string
+	for _, part := range strings.Split(pattern, "/") {
+		if name, ok := greedyParam(part); ok {
+			params = append(params, name)
+		} else if seg, ok := parseSegment(part); ok {
+			params = append(params, seg.name)
+		}
+	}
+
+	return params
+}
+
+// escapeGreedy escapes each '/' separated segment of a catch-all value.
+func escapeGreedy(value string) string {
+	segments := strings.Split(value, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// escapeRawGreedy escapes each '/' separated segment of a catch-all value that
+// is already escaped, so that segments are escaped as by escapeGreedy but an
+// encoded '/' is not taken as a separator. Segments that are not validly
+// encoded are escaped as they are.
+func escapeRawGreedy(value string) string {
+	segments := strings.Split(value, "/")
+	for i, segment := range segments {
+		if unescaped, err := url.PathUnescape(segment); err == nil {
+			segment = unescaped
+		}
+		segments[i] = url.PathEscape(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,146 @@
+package route
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuild(t *testing.T) {
+	cases := []struct {
+		pattern  string
+		vars     map[string]string
+		query    url.Values
+		expected string
+	}{
+		{"/", nil, nil, "/"},
+		{"/user/:name", map[string]string{"name": "gopher"}, nil, "/user/gopher"},
+		{"/user/:name", map[string]string{"name": "john doe"}, nil, "/user/john%20doe"},
+		{"/user/:name", map[string]string{"name": "a/b?c"}, nil, "/user/a%2Fb%3Fc"},
+		{"/files/*path", map[string]string{"path": "a b/c?.txt"}, nil, "/files/a%20b/c%3F.txt"},
+		{"/files/*path", map[string]string{}, nil, "/files/"},
+		{"/search", nil, url.Values{"q": {"a&b"}}, "/search?q=a%26b"},
+		{"/posts/:id([0-9]+)", map[string]string{"id": "12"}, nil, "/posts/12"},
+		{"/reports/:period{daily,weekly}", map[string]string{"period": "weekly"}, nil, "/reports/weekly"},
+		{"/v{version}/files/:name.json", map[string]string{"version": "2", "name": "a b"}, nil, "/v2/files/a%20b.json"},
+		{"/v1/things:batchGet", nil, nil, "/v1/things:batchGet"},
+		{"/user/{name}", map[string]string{"name": "john doe"}, nil, "/user/john%20doe"},
+		{"/files/{path...}", map[string]string{"path": "a b/c.txt"}, nil, "/files/a%20b/c.txt"},
+		{"/user/{$}", nil, nil, "/user/"},
+	}
+
+	for _, tc := range cases {
+		u, err := Build(tc.pattern, tc.vars, tc.query)
+
+		assert.Nil(t, err)
+		assert.Equal(t, tc.expected, u.String())
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	cases := []struct {
+		pattern string
+		vars    map[string]string
+	}{
+		{"user", nil},
+		{"/user/:name", nil},
+		{"/user/:name", map[string]string{"name": ""}},
+		{"/files/*path/more", map[string]string{"path": "a"}},
+		{"/posts/:id([0-9]+)", map[string]string{"id": "hello"}},
+		{"/reports/:period{daily,weekly}", map[string]string{"period": "yearly"}},
+	}
+
+	for _, tc := range cases {
+		_, err := Build(tc.pattern, tc.vars, nil)
+
+		assert.NotNil(t, err)
+	}
+}
+
+func TestBuildRoundTrip(t *testing.T) {
+	vars := map[string]string{"name": "a/b c", "path": "x/y z"}
+
+	handler := &recordingHandler{}
+	router := New()
+	router.Handle("/user/:name/*path", handler)
+
+	u, err := Build("/user/:name/*path", vars, nil)
+	assert.Nil(t, err)
+
+	r, _ := http.NewRequest("GET", u.String(), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	assert.True(t, handler.Used)
+	assert.Equal(t, map[string]string{"name": "a%2Fb%20c", "path": "x/y%20z"}, handler.Vars)
+}
+
+func TestRouterURL(t *testing.T) {
+	router := New()
+	router.Handle("/user/:name", &recordingHandler{}, Name("user.show"))
+
+	u, err := router.URL("user.show", "name", "gopher", "tab", "posts")
+	assert.Nil(t, err)
+	assert.Equal(t, "/user/gopher?tab=posts", u.String())
+
+	_, err = router.URL("user.show")
+	assert.NotNil(t, err)
+
+	_, err = router.URL("user.show", "name")
+	assert.NotNil(t, err)
+
+	_, err = router.URL("user.missing", "name", "gopher")
+	assert.NotNil(t, err)
+}
+
+func TestRouterURLWithMatcher(t *testing.T) {
+	router := New()
+	router.Matcher("short", func(segment string) (string, bool) {
+		return segment, len(segment) <= 3
+	})
+	router.Handle("/code/:code<short>", &recordingHandler{}, Name("code"))
+
+	u, err := router.URL("code", "code", "abc")
+	assert.Nil(t, err)
+	assert.Equal(t, "/code/abc", u.String())
+
+	_, err = router.URL("code", "code", "abcd")
+	assert.NotNil(t, err)
+
+	u, err = Build("/code/:code<short>", map[string]string{"code": "abcd"}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "/code/abcd", u.String())
+}
+
+func TestRouterNameAlreadyRegistered(t *testing.T) {
+	router := New()
+	router.Handle("/user/:name", &recordingHandler{}, Name("user"))
+
+	recv := checkPanics(t, func() {
+		router.Handle("/users/:name", &recordingHandler{}, Name("user"))
+	})
+
+	assert.Regexp(t, `^route with name already registered: user for /user/:name at url_test\.go:\d+$`, recv)
+}
+
+func TestRouterFuncMap(t *testing.T) {
+	router := New()
+	router.Handle("/user/:name/posts/:id", &recordingHandler{}, Name("post"))
+
+	tmpl := template.Must(template.New("").Funcs(router.FuncMap()).Parse(
+		`<a href="{{url "post" "name" .Name "id" .ID}}">`))
+
+	var buf bytes.Buffer
+	err := tmpl.Execute(&buf, struct {
+		Name string
+		ID   int
+	}{"john doe", 5})
+
+	assert.Nil(t, err)
+	assert.Equal(t, `<a href="/user/john%20doe/posts/5">`, buf.String())
+}
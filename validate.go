@@ -0,0 +1,111 @@
+package route
+
+import (
+	"strings"
+)
+
+// A ShadowedRoute is a route that can never be matched, as requests for it are
+// always handled by another route.
+type ShadowedRoute struct {
+	// Route is the route that cannot be matched.
+	Route RouteInfo
+
+	// By is the route that matches requests instead.
+	By RouteInfo
+}
+
+// ShadowedRoutes is the error returned by Validate, listing each route that can
+// never be matched.
+type ShadowedRoutes []ShadowedRoute
+
+func (routes ShadowedRoutes) Error() string {
+	lines := make([]string, len(routes))
+	for i, route := range routes {
+		lines[i] = "route " + describeRoute(route.Route) + " is shadowed by " + describeRoute(route.By)
+	}
+
+	return "route: " + strings.Join(lines, "; ")
+}
+
+// describeRoute returns the methods, host, pattern and source of a route, as
+// "GET example.com/users (main.go:12)".
+func describeRoute(info RouteInfo) string {
+	s := info.Host + info.Pattern
+	if len(info.Methods) > 0 {
+		s = strings.Join(info.Methods, ",") + " " + s
+	}
+	if info.Source != "" {
+		s += " (" + info.Source + ")"
+	}
+
+	return s
+}
+
+// Validate checks the registered routes, returning a ShadowedRoutes error if
+// any can never be matched. This happens when:
+//
+//   - an earlier route for the same pattern, without conditions, accepts all
+//     of the methods of the route, for example "GET /users" can not be matched
+//     if it was registered after a route for "/users" with Methods("GET",
+//     "POST");
+//
+//   - a catch-all parameter directly follows the pattern, for example "/files"
+//     can not be matched if "/files/*path" is registered, as the catch-all is
+//     preferred when it would match an empty value.
+func (r *Router) Validate() error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var shadowed ShadowedRoutes
+
+	for _, ep := range r.sortedEndpoints() {
+		for j, e := range ep.routes {
+			for i, earlier := range ep.routes[:j] {
+				if !earlier.conditional() && coversMethods(earlier.methods, e.methods) {
+					shadowed = append(shadowed, ShadowedRoute{Route: ep.info(j), By: ep.info(i)})
+					break
+				}
+			}
+		}
+
+		i := strings.LastIndexByte(ep.pattern, '/')
+		if i <= 0 || !strings.HasPrefix(ep.pattern[i+1:], "*") {
+			continue
+		}
+		if parent, ok := r.endpoints[ep.host+ep.pattern[:i]]; ok && len(ep.routes) > 0 {
+			for j := range parent.routes {
+				shadowed = append(shadowed, ShadowedRoute{Route: parent.info(j), By: ep.info(0)})
+			}
+		}
+	}
+
+	if len(shadowed) == 0 {
+		return nil
+	}
+
+	return shadowed
+}
+
+// coversMethods returns true if a route restricted to methods a accepts every
+// method that a route restricted to methods b does. A route with no methods
+// accepts any.
+func coversMethods(a, b []string) bool {
+	if len(a) == 0 {
+		return true
+	}
+	if len(b) == 0 {
+		return false
+	}
+
+	set := map[string]bool{}
+	for _, method := range a {
+		set[method] = true
+	}
+	for _, method := range b {
+		if !set[method] {
+			return false
+		}
+	}
+
+	return true
+}
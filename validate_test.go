@@ -0,0 +1,46 @@
+package route
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouterValidate(t *testing.T) {
+	router := New()
+	router.Handle("/users", &recordingHandler{}, Methods("GET", "POST"))
+	router.Handle("GET /users", &recordingHandler{})
+	router.Handle("/users", &recordingHandler{})
+	router.Handle("/files", &recordingHandler{})
+	router.Handle("/files/*path", &recordingHandler{})
+
+	err := router.Validate()
+	if assert.IsType(t, ShadowedRoutes{}, err) {
+		shadowed := err.(ShadowedRoutes)
+
+		if assert.Len(t, shadowed, 2) {
+			assert.Equal(t, "/files", shadowed[0].Route.Pattern)
+			assert.Equal(t, "/files/*path", shadowed[0].By.Pattern)
+
+			assert.Equal(t, "/users", shadowed[1].Route.Pattern)
+			assert.Equal(t, []string{"GET"}, shadowed[1].Route.Methods)
+			assert.Equal(t, []string{"GET", "POST"}, shadowed[1].By.Methods)
+		}
+
+		assert.Regexp(t, `^route: route /files \(validate_test\.go:\d+\) is shadowed by /files/\*path \(validate_test\.go:\d+\); route GET /users`, err.Error())
+	}
+}
+
+func TestRouterValidateReachable(t *testing.T) {
+	router := New()
+	router.Handle("GET /users", &recordingHandler{}, Header("Accept", "text/csv"))
+	router.Handle("GET /users", &recordingHandler{})
+	router.Handle("/users", &recordingHandler{}, Methods("GET", "POST"))
+	router.Handle("/users", &recordingHandler{})
+	router.Handle("/", &recordingHandler{})
+	router.Handle("/*path", &recordingHandler{})
+	router.Handle("/files/:name", &recordingHandler{})
+	router.Handle("/files/:name/edit", &recordingHandler{})
+
+	assert.Nil(t, router.Validate())
+}
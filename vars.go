@@ -0,0 +1,265 @@
+package route
+
+import (
+	"encoding"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrMissingVar is wrapped by the VarError returned when the route has no
+// parameter with the name.
+var ErrMissingVar = errors.New("missing")
+
+// VarError is returned by the typed parameter accessors, such as IntVar, when
+// the parameter is missing or its value can't be converted. It responds with
+// 400 Bad Request when returned from a handler.
+type VarError struct {
+	// Name is the name of the parameter.
+	Name string
+
+	// Value is the value of the parameter, if it was present.
+	Value string
+
+	// Err is the reason the value could not be converted.
+	Err error
+}
+
+func (e *VarError) Error() string {
+	if errors.Is(e.Err, ErrMissingVar) {
+		return "route: parameter " + e.Name + " is missing"
+	}
+
+	return "route: parameter " + e.Name + " has invalid value " + strconv.Quote(e.Value) + ": " + e.Err.Error()
+}
+
+// StatusCode returns 400.
+func (e *VarError) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+func (e *VarError) Unwrap() error {
+	return e.Err
+}
+
+// lookupVar returns the value of the parameter of the request with the name,
+// or a VarError if there is not one.
+func lookupVar(r *http.Request, name string) (string, error) {
+	value, ok := GetParams(r).Get(name)
+	if !ok {
+		return "", &VarError{Name: name, Err: ErrMissingVar}
+	}
+
+	return value, nil
+}
+
+// varParsers holds the functions registered with RegisterVar, by the type they
+// parse.
+var varParsers = struct {
+	sync.RWMutex
+	m map[reflect.Type]func(string) (any, error)
+}{m: map[reflect.Type]func(string) (any, error){}}
+
+// RegisterVar registers the function used to parse parameters as values of
+// type T, for Var and BindVars. It replaces any function previously registered
+// for T.
+//
+//   type UserID int64
+//
+//   route.RegisterVar(func(s string) (UserID, error) {
+//     id, err := strconv.ParseInt(strings.TrimPrefix(s, "u"), 10, 64)
+//     return UserID(id), err
+//   })
+func RegisterVar[T any](parse func(string) (T, error)) {
+	varParsers.Lock()
+	defer varParsers.Unlock()
+
+	varParsers.m[reflect.TypeFor[T]()] = func(s string) (any, error) {
+		return parse(s)
+	}
+}
+
+// Var returns the value of the parameter with the name as a value of type T.
+//
+//   id, err := route.Var[int64](r, "id")
+//   if err != nil {
+//     return err
+//   }
+//
+// The value is parsed by the function registered for T with RegisterVar, or if
+// there is not one by the UnmarshalText method of *T. Otherwise strings,
+// booleans and numbers, including types defined as them, are parsed as by the
+// strconv package. Var panics if it does not know how to parse values of T.
+func Var[T any](r *http.Request, name string) (T, error) {
+	var v T
+
+	value, err := lookupVar(r, name)
+	if err != nil {
+		return v, err
+	}
+
+	if err := parseVar(reflect.ValueOf(&v).Elem(), value); err != nil {
+		return v, &VarError{Name: name, Value: value, Err: err}
+	}
+
+	return v, nil
+}
+
+// parseVar parses the string s into v, which must be settable. Errors from the
+// strconv package are unwrapped to the reason for failing.
+func parseVar(v reflect.Value, s string) error {
+	varParsers.RLock()
+	parse, ok := varParsers.m[v.Type()]
+	varParsers.RUnlock()
+
+	if ok {
+		parsed, err := parse(s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(parsed))
+		return nil
+	}
+
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+
+	var err error
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(s); err == nil {
+			v.SetBool(b)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		if i, err = strconv.ParseInt(s, 10, v.Type().Bits()); err == nil {
+			v.SetInt(i)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		if u, err = strconv.ParseUint(s, 10, v.Type().Bits()); err == nil {
+			v.SetUint(u)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(s, v.Type().Bits()); err == nil {
+			v.SetFloat(f)
+		}
+	default:
+		panic("route: no parser for parameters of type " + v.Type().String())
+	}
+
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		return numErr.Err
+	}
+
+	return err
+}
+
+// IntVar returns the value of the parameter with the name as an integer. It is
+// the same as Var[int64].
+//
+//   id, err := route.IntVar(r, "id")
+//   if err != nil {
+//     return err
+//   }
+func IntVar(r *http.Request, name string) (int64, error) {
+	return Var[int64](r, name)
+}
+
+// BoolVar returns the value of the parameter with the name as a boolean. It
+// accepts the values strconv.ParseBool does, and is the same as Var[bool].
+func BoolVar(r *http.Request, name string) (bool, error) {
+	return Var[bool](r, name)
+}
+
+// UUIDVar returns the value of the parameter with the name as the bytes of a
+// UUID, written in the form "xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx". The result
+// can be converted directly to the UUID type of most packages.
+func UUIDVar(r *http.Request, name string) ([16]byte, error) {
+	value, err := lookupVar(r, name)
+	if err != nil {
+		return [16]byte{}, err
+	}
+
+	uuid, ok := parseUUID(value)
+	if !ok {
+		return [16]byte{}, &VarError{Name: name, Value: value, Err: errors.New("invalid UUID")}
+	}
+
+	return uuid, nil
+}
+
+// parseUUID parses a UUID in its hyphenated form.
+func parseUUID(s string) (uuid [16]byte, ok bool) {
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return uuid, false
+	}
+
+	digits := s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:36]
+	if _, err := hex.Decode(uuid[:], []byte(digits)); err != nil {
+		return uuid, false
+	}
+
+	return uuid, true
+}
+
+// VarErrors is returned by BindVars when parameters can't be bound, with an
+// error for each. It responds with 400 Bad Request when returned from a
+// handler.
+type VarErrors []*VarError
+
+func (errs VarErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// StatusCode returns 400.
+func (errs VarErrors) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+func (errs VarErrors) Unwrap() []error {
+	unwrapped := make([]error, len(errs))
+	for i, err := range errs {
+		unwrapped[i] = err
+	}
+
+	return unwrapped
+}
+
+// BindVars sets the fields of the struct pointed to by dst from the parameters
+// of the request. Each field to set is tagged with the name of its parameter,
+// and has its value parsed as by Var:
+//
+//   var vars struct {
+//     ID   int64  `route:"id"`
+//     Slug string `route:"slug"`
+//   }
+//   if err := route.BindVars(r, &vars); err != nil {
+//     return err
+//   }
+//
+// If any parameters are missing or can't be parsed the fields that could be set
+// are, and VarErrors is returned. Otherwise the struct is validated as by Bind.
+// BindVars panics if dst is not a pointer to a struct.
+func BindVars(r *http.Request, dst any) error {
+	if errs := bindFields(structValue(dst, "BindVars"), "route", varsGetter(r), true, nil); len(errs) > 0 {
+		return errs
+	}
+
+	return validate(dst)
+}
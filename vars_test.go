@@ -0,0 +1,202 @@
+package route
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntVar(t *testing.T) {
+	router := New()
+	router.HandleFunc("/posts/:id", func(w http.ResponseWriter, r *http.Request) error {
+		id, err := IntVar(r, "id")
+		if err != nil {
+			return err
+		}
+
+		w.Write([]byte(strconv.FormatInt(id*2, 10)))
+		return nil
+	})
+
+	r, _ := http.NewRequest("GET", "/posts/21", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "42", w.Body.String())
+
+	r, _ = http.NewRequest("GET", "/posts/first", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, 400, w.Code)
+}
+
+func TestVarErrors(t *testing.T) {
+	router := New()
+	router.HandleFunc("/:id/:flag", func(w http.ResponseWriter, r *http.Request) {
+		_, err := IntVar(r, "id")
+		assert.Equal(t, `route: parameter id has invalid value "99999999999999999999": value out of range`, err.Error())
+		assert.True(t, errors.Is(err, strconv.ErrRange))
+
+		_, err = BoolVar(r, "flag")
+		assert.Equal(t, `route: parameter flag has invalid value "maybe": invalid syntax`, err.Error())
+
+		_, err = IntVar(r, "missing")
+		assert.Equal(t, "route: parameter missing is missing", err.Error())
+		assert.True(t, errors.Is(err, ErrMissingVar))
+
+		var coder StatusCoder
+		assert.True(t, errors.As(err, &coder))
+		assert.Equal(t, 400, coder.StatusCode())
+	})
+
+	r, _ := http.NewRequest("GET", "/99999999999999999999/maybe", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+}
+
+func TestBoolVar(t *testing.T) {
+	router := New()
+	router.HandleFunc("/flags/:on", func(w http.ResponseWriter, r *http.Request) {
+		on, err := BoolVar(r, "on")
+		assert.Nil(t, err)
+		assert.True(t, on)
+	})
+
+	r, _ := http.NewRequest("GET", "/flags/true", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+}
+
+func TestUUIDVar(t *testing.T) {
+	var uuid [16]byte
+	var err error
+
+	router := New()
+	router.HandleFunc("/things/:id", func(w http.ResponseWriter, r *http.Request) {
+		uuid, err = UUIDVar(r, "id")
+	})
+
+	r, _ := http.NewRequest("GET", "/things/6ba7b810-9DAD-11d1-80b4-00c04fd430c8", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+
+	assert.Nil(t, err)
+	assert.Equal(t, [16]byte{0x6b, 0xa7, 0xb8, 0x10, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8}, uuid)
+
+	for _, id := range []string{"6ba7b810", "6ba7b8109dad11d180b400c04fd430c8", "6ba7b810-9dad-11d1-80b4-00c04fd430cg"} {
+		r, _ = http.NewRequest("GET", "/things/"+id, nil)
+		router.ServeHTTP(httptest.NewRecorder(), r)
+
+		assert.NotNil(t, err, id)
+	}
+}
+
+type userID int64
+
+type colour struct {
+	r, g, b byte
+}
+
+func (c *colour) UnmarshalText(text []byte) error {
+	if len(text) != 6 {
+		return errors.New("not a colour")
+	}
+
+	v, err := strconv.ParseUint(string(text), 16, 32)
+	c.r, c.g, c.b = byte(v>>16), byte(v>>8), byte(v)
+	return err
+}
+
+type slug string
+
+func TestVar(t *testing.T) {
+	RegisterVar(func(s string) (slug, error) {
+		if s == "" || s[0] != '~' {
+			return "", errors.New("not a slug")
+		}
+		return slug(s[1:]), nil
+	})
+
+	router := New()
+	router.HandleFunc("/:user/:colour/:ratio/:slug/:count", func(w http.ResponseWriter, r *http.Request) {
+		user, err := Var[userID](r, "user")
+		assert.Nil(t, err)
+		assert.Equal(t, userID(12), user)
+
+		c, err := Var[colour](r, "colour")
+		assert.Nil(t, err)
+		assert.Equal(t, colour{0xff, 0x80, 0x00}, c)
+
+		ratio, err := Var[float32](r, "ratio")
+		assert.Nil(t, err)
+		assert.Equal(t, float32(0.5), ratio)
+
+		s, err := Var[slug](r, "slug")
+		assert.Nil(t, err)
+		assert.Equal(t, slug("hello"), s)
+
+		_, err = Var[uint8](r, "count")
+		assert.Equal(t, `route: parameter count has invalid value "300": value out of range`, err.Error())
+
+		_, err = Var[slug](r, "user")
+		assert.Equal(t, `route: parameter user has invalid value "12": not a slug`, err.Error())
+
+		assert.Panics(t, func() {
+			Var[[]string](r, "user")
+		})
+	})
+
+	r, _ := http.NewRequest("GET", "/12/ff8000/0.5/~hello/300", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, 200, w.Code)
+}
+
+func TestBindVars(t *testing.T) {
+	type postVars struct {
+		User  userID `route:"user"`
+		ID    int64  `route:"id"`
+		Slug  string `route:"slug"`
+		Other string
+	}
+
+	router := New()
+	router.HandleFunc("/:user/posts/:id/:slug", func(w http.ResponseWriter, r *http.Request) error {
+		var vars postVars
+		if err := BindVars(r, &vars); err != nil {
+			return err
+		}
+
+		assert.Equal(t, postVars{User: 5, ID: 12, Slug: "hello-world"}, vars)
+		return nil
+	})
+	router.HandleFunc("/:user/drafts/:id", func(w http.ResponseWriter, r *http.Request) {
+		var vars postVars
+		err := BindVars(r, &vars)
+
+		assert.Equal(t, postVars{User: 5}, vars)
+		assert.Equal(t, `route: parameter id has invalid value "new": invalid syntax; route: parameter slug is missing`, err.Error())
+		assert.True(t, errors.Is(err, ErrMissingVar))
+
+		var verrs VarErrors
+		assert.True(t, errors.As(err, &verrs))
+		assert.Len(t, verrs, 2)
+
+		assert.Panics(t, func() {
+			BindVars(r, vars)
+		})
+	})
+
+	r, _ := http.NewRequest("GET", "/5/posts/12/hello-world", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, 200, w.Code)
+
+	r, _ = http.NewRequest("GET", "/5/drafts/new", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+}
@@ -0,0 +1,232 @@
+package route
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// A Version is a group of routes below a version prefix, such as "/v1", that
+// can be extended by later versions so that routes which have not changed are
+// only registered once:
+//
+//   v1 := router.Version("v1")
+//   v1.Get("/users/:id", getUserV1)
+//   v1.Get("/teams/:id", getTeam)
+//
+//   v2 := v1.Extend("v2")
+//   v2.Get("/users/:id", getUserV2)
+//
+//   /v1/users/5      getUserV1
+//   /v2/users/5      getUserV2
+//   /v2/teams/5      getTeam
+//
+// Routes registered with a Version are also registered with each version that
+// extends it, whether they are registered before or after it is extended,
+// unless that version registers its own route with the same method and path.
+type Version struct {
+	name     string
+	opts     []Option
+	group    *Group
+	mu       *sync.Mutex
+	routes   map[string]versionRoute
+	own      map[string]bool
+	children []*Version
+
+	// deprecation holds the headers set by Deprecate.
+	deprecation atomic.Pointer[http.Header]
+}
+
+// versionRoute is a registration made with a Version.
+type versionRoute struct {
+	method  string
+	path    string
+	handler interface{}
+	fn      bool
+	opts    []Option
+}
+
+// key identifies the route by its method and path, so that a version can
+// override the routes it inherits.
+func (rt versionRoute) key() string {
+	method, host, path := parsePattern(rt.path)
+	if rt.method != "" {
+		method = rt.method
+	}
+
+	return strings.ToUpper(method) + " " + host + path
+}
+
+// Version returns a Version for registering routes below the prefix "/"+name.
+// The options are applied to every route registered with the Version, and
+// those that extend it, before those given on registration.
+func (r *Router) Version(name string, opts ...Option) *Version {
+	return newVersion(r, name, &sync.Mutex{}, opts)
+}
+
+func newVersion(r *Router, name string, mu *sync.Mutex, opts []Option) *Version {
+	v := &Version{
+		name:   name,
+		opts:   opts,
+		mu:     mu,
+		routes: map[string]versionRoute{},
+		own:    map[string]bool{},
+	}
+	v.group = r.Group("/"+name, append(append([]Option{}, opts...), Tag("version", v))...)
+
+	return v
+}
+
+// Extend returns a Version for registering routes below the prefix "/"+name
+// that inherits the routes of v. The options are applied to the routes of the
+// new version only, after any given to v.
+func (v *Version) Extend(name string, opts ...Option) *Version {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	child := newVersion(v.group.router, name, v.mu, append(append([]Option{}, v.opts...), opts...))
+	v.children = append(v.children, child)
+
+	for _, rt := range v.routes {
+		child.register(rt, false)
+	}
+
+	return child
+}
+
+// Name returns the name of the version, as "v1".
+func (v *Version) Name() string {
+	return v.name
+}
+
+// Deprecate marks the version as deprecated from the time at, so that
+// responses for its routes have a Deprecation header. If sunset is not zero a
+// Sunset header gives the time the version will stop being served, and if link
+// is not empty a Link header points to documentation for migrating away from
+// it:
+//
+//   v1.Deprecate(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+//     time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC),
+//     "https://example.com/docs/migrating-to-v2")
+//
+// Versions extending v are not deprecated with it.
+func (v *Version) Deprecate(at, sunset time.Time, link string) {
+	header := http.Header{"Deprecation": {"@" + strconv.FormatInt(at.Unix(), 10)}}
+	if !sunset.IsZero() {
+		header.Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+	}
+	if link != "" {
+		header.Add("Link", "<"+link+`>; rel="deprecation"`)
+	}
+
+	if v.deprecation.Swap(&header) != nil {
+		return
+	}
+
+	v.group.router.Before(func(w http.ResponseWriter, r *http.Request) bool {
+		if version, _ := MetaValue[*Version](r, "version"); version == v {
+			for key, values := range *v.deprecation.Load() {
+				for _, value := range values {
+					w.Header().Add(key, value)
+				}
+			}
+		}
+		return true
+	})
+}
+
+// APIVersion returns the name of the Version the route matched by the request
+// was registered with, or "" if it was not registered with one.
+func APIVersion(r *http.Request) string {
+	if v, ok := MetaValue[*Version](r, "version"); ok {
+		return v.name
+	}
+	return ""
+}
+
+// Handle registers the handler for the path, relative to the Version. The path
+// may begin with a method, as with Router.Handle.
+func (v *Version) Handle(path string, handler interface{}, opts ...Option) {
+	v.add(versionRoute{path: path, handler: handler, opts: opts})
+}
+
+// HandleFunc registers the handler function for the path, relative to the
+// Version.
+func (v *Version) HandleFunc(path string, handler interface{}, opts ...Option) {
+	v.add(versionRoute{path: path, handler: handler, fn: true, opts: opts})
+}
+
+// Get registers the handler for GET requests to the path.
+func (v *Version) Get(path string, handler interface{}, opts ...Option) {
+	v.add(versionRoute{method: "GET", path: path, handler: handler, opts: opts})
+}
+
+// Post registers the handler for POST requests to the path.
+func (v *Version) Post(path string, handler interface{}, opts ...Option) {
+	v.add(versionRoute{method: "POST", path: path, handler: handler, opts: opts})
+}
+
+// Put registers the handler for PUT requests to the path.
+func (v *Version) Put(path string, handler interface{}, opts ...Option) {
+	v.add(versionRoute{method: "PUT", path: path, handler: handler, opts: opts})
+}
+
+// Patch registers the handler for PATCH requests to the path.
+func (v *Version) Patch(path string, handler interface{}, opts ...Option) {
+	v.add(versionRoute{method: "PATCH", path: path, handler: handler, opts: opts})
+}
+
+// Delete registers the handler for DELETE requests to the path.
+func (v *Version) Delete(path string, handler interface{}, opts ...Option) {
+	v.add(versionRoute{method: "DELETE", path: path, handler: handler, opts: opts})
+}
+
+// add registers a route of the version itself.
+func (v *Version) add(rt versionRoute) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.register(rt, true)
+}
+
+// register registers the route with the version, replacing any route it
+// inherited for the same method and path, then with the versions extending it.
+// Inherited routes are ignored when the version has its own.
+func (v *Version) register(rt versionRoute, own bool) {
+	key := rt.key()
+	if !own && v.own[key] {
+		return
+	}
+
+	if old, ok := v.routes[key]; ok && !v.own[key] {
+		v.group.router.Remove(v.group.pattern(old.pattern()))
+	}
+
+	switch {
+	case rt.method != "":
+		v.group.handleMethod(rt.method, rt.path, rt.handler, rt.opts)
+	case rt.fn:
+		v.group.HandleFunc(rt.path, rt.handler, rt.opts...)
+	default:
+		v.group.Handle(rt.path, rt.handler, rt.opts...)
+	}
+
+	v.routes[key] = rt
+	v.own[key] = own
+
+	for _, child := range v.children {
+		child.register(rt, false)
+	}
+}
+
+// pattern returns the path of the route, beginning with its method if it has
+// one, so that it can be removed.
+func (rt versionRoute) pattern() string {
+	if rt.method != "" {
+		return rt.method + " " + rt.path
+	}
+	return rt.path
+}
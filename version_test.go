@@ -0,0 +1,77 @@
+package route
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionExtend(t *testing.T) {
+	respond := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(body + " " + APIVersion(r)))
+		}
+	}
+
+	router := New()
+	v1 := router.Version("v1")
+	v1.Get("/users/:id", respond("user1"))
+	v1.Handle("/teams/:id", respond("team"))
+
+	v2 := v1.Extend("v2")
+	v2.Get("/users/:id", respond("user2"))
+
+	v3 := v2.Extend("v3")
+	v3.Handle("/teams/:id", respond("team3"))
+
+	v1.Post("/users", respond("create"))
+	v2.Post("/users", respond("create2"))
+
+	testCases := map[string]struct {
+		method, path, body string
+	}{
+		"v1 user":   {"GET", "/v1/users/5", "user1 v1"},
+		"v2 user":   {"GET", "/v2/users/5", "user2 v2"},
+		"v3 user":   {"GET", "/v3/users/5", "user2 v3"},
+		"v1 team":   {"GET", "/v1/teams/5", "team v1"},
+		"v2 team":   {"GET", "/v2/teams/5", "team v2"},
+		"v3 team":   {"GET", "/v3/teams/5", "team3 v3"},
+		"v1 create": {"POST", "/v1/users", "create v1"},
+		"v2 create": {"POST", "/v2/users", "create2 v2"},
+		"v3 create": {"POST", "/v3/users", "create2 v3"},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, nil))
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tc.body, rec.Body.String())
+		})
+	}
+}
+
+func TestVersionDeprecate(t *testing.T) {
+	router := New()
+	v1 := router.Version("v1")
+	v1.Get("/users", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	v2 := v1.Extend("v2")
+
+	v1.Deprecate(time.Unix(1735689600, 0), time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), "https://example.com/migrate")
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/v1/users", nil))
+	assert.Equal(t, "@1735689600", rec.Header().Get("Deprecation"))
+	assert.Equal(t, "Tue, 01 Jul 2025 00:00:00 GMT", rec.Header().Get("Sunset"))
+	assert.Equal(t, `<https://example.com/migrate>; rel="deprecation"`, rec.Header().Get("Link"))
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/v2/users", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "", rec.Header().Get("Deprecation"))
+	assert.Equal(t, "v2", v2.Name())
+}
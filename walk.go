@@ -0,0 +1,205 @@
+package route
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RouteInfo describes a registered route.
+type RouteInfo struct {
+	// Pattern is the path pattern of the route, as "/user/:name".
+	Pattern string `json:"pattern"`
+
+	// Host is the host the route was registered for, if any.
+	Host string `json:"host,omitempty"`
+
+	// Name is the name of the route, if any.
+	Name string `json:"name,omitempty"`
+
+	// Methods lists the methods the route is restricted to, if any.
+	Methods []string `json:"methods,omitempty"`
+
+	// Params lists the names of the parameters in the pattern.
+	Params []string `json:"params,omitempty"`
+
+	// Handler describes the handler of the route. For functions this is the
+	// function's name, otherwise it is the handler's type.
+	Handler string `json:"handler"`
+
+	// Source is the file and line the route was registered at, as
+	// "main.go:42".
+	Source string `json:"source,omitempty"`
+
+	// Canonical is the path, with any host, of the route this route is an alias
+	// of, if it was registered with HandleAll.
+	Canonical string `json:"canonical,omitempty"`
+
+	// Meta is the metadata attached to the route with Tag, if any.
+	Meta map[string]any `json:"meta,omitempty"`
+
+	// Priority is the position of the route amongst those registered for the
+	// same pattern, routes with a lower priority are tried first.
+	Priority int `json:"priority"`
+}
+
+// Routes returns a description of each registered route, in the order they
+// would be visited by Walk. The returned slice is a snapshot, so does not
+// change as further routes are registered.
+func (r *Router) Routes() []RouteInfo {
+	_, infos := r.routes()
+
+	return infos
+}
+
+// Walk calls fn for each registered route, ordered by host then pattern. Routes
+// registered for the same pattern are visited in the order they are tried. If
+// fn returns an error walking stops and the error is returned.
+//
+// Routes are collected before fn is first called, so fn may register routes
+// without them being visited.
+func (r *Router) Walk(fn func(pattern string, h http.Handler, info RouteInfo) error) error {
+	handlers, infos := r.routes()
+
+	for i, info := range infos {
+		if err := fn(info.Pattern, handlers[i], info); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// routes returns the handler and description of each registered route.
+func (r *Router) routes() ([]http.Handler, []RouteInfo) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var handlers []http.Handler
+	var infos []RouteInfo
+	for _, ep := range r.sortedEndpoints() {
+		for i, e := range ep.routes {
+			handlers = append(handlers, httpHandler(e.handler))
+			infos = append(infos, ep.info(i))
+		}
+	}
+
+	return handlers, infos
+}
+
+// sortedEndpoints returns the endpoints of the router ordered by host then
+// pattern. It must be called with the lock held.
+func (r *Router) sortedEndpoints() []*endpoint {
+	var eps []*endpoint
+	for _, ep := range r.endpoints {
+		// endpoints with vars are added by the Formats option, and so are part
+		// of another route
+		if ep.vars == nil {
+			eps = append(eps, ep)
+		}
+	}
+	sort.Slice(eps, func(i, j int) bool {
+		if eps[i].host != eps[j].host {
+			return eps[i].host < eps[j].host
+		}
+		return eps[i].pattern < eps[j].pattern
+	})
+
+	return eps
+}
+
+// info describes the i-th route of the endpoint.
+func (ep *endpoint) info(i int) RouteInfo {
+	e := ep.routes[i]
+
+	return RouteInfo{
+		Pattern:   ep.pattern,
+		Host:      ep.host,
+		Name:      e.name,
+		Methods:   e.methods,
+		Params:    patternParams(ep.pattern),
+		Handler:   handlerName(httpHandler(e.handler)),
+		Source:    e.source,
+		Canonical: e.canonical,
+		Meta:      e.meta,
+		Priority:  i,
+	}
+}
+
+// httpHandler returns the http.Handler that was registered for h.
+func httpHandler(h Handler) http.Handler {
+	switch v := h.(type) {
+	case nilErrorHandler:
+		return v.Handler
+	case http.Handler:
+		return v
+	default:
+		return HandlerFunc(h.ServeErrorHTTP)
+	}
+}
+
+// handlerName returns the name of the function h is, or otherwise its type.
+func handlerName(h http.Handler) string {
+	if v := reflect.ValueOf(h); v.Kind() == reflect.Func {
+		if fn := runtime.FuncForPC(v.Pointer()); fn != nil {
+			return fn.Name()
+		}
+	}
+
+	return fmt.Sprintf("%T", h)
+}
+
+// callSite returns the file and line of the first caller outside of this
+// package, ignoring its tests.
+func callSite() string {
+	_, self, _, _ := runtime.Caller(0)
+	dir := filepath.Dir(self)
+
+	for skip := 2; ; skip++ {
+		_, file, line, ok := runtime.Caller(skip)
+		if !ok {
+			return ""
+		}
+		if filepath.Dir(file) != dir || strings.HasSuffix(file, "_test.go") {
+			return filepath.Base(file) + ":" + strconv.Itoa(line)
+		}
+	}
+}
+
+// RouteTable returns the registered routes as text, one route to a line in the
+// order they would be visited by Walk, giving the methods, or "*" for any, the
+// host and pattern, and any name or route it is an alias of:
+//
+//   GET /users name=users
+//   GET /people alias=/users
+//   GET,POST example.com/users/:id
+//   * /files/*path
+//
+// The text only changes when the routes do, so it can be compared to a copy
+// kept with the tests of an app to guard against routes being added or removed
+// by accident, see the routetest package.
+func (r *Router) RouteTable() string {
+	var b strings.Builder
+	for _, info := range r.Routes() {
+		methods := "*"
+		if len(info.Methods) > 0 {
+			methods = strings.Join(info.Methods, ",")
+		}
+
+		b.WriteString(methods + " " + info.Host + info.Pattern)
+		if info.Name != "" {
+			b.WriteString(" name=" + info.Name)
+		}
+		if info.Canonical != "" {
+			b.WriteString(" alias=" + info.Canonical)
+		}
+		b.WriteByte('\n')
+	}
+
+	return b.String()
+}
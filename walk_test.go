@@ -0,0 +1,109 @@
+package route
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouterWalk(t *testing.T) {
+	router := New()
+
+	handler := &recordingHandler{}
+	router.Handle("GET /user/:name", handler, Name("user"))
+	router.Handle("/user/:name", handler)
+	router.Handle("/", handler)
+	router.Handle("/files/*path", handler)
+	router.Handle("api.example.com/", handler)
+	router.Handle("/reports/:id", handler, Formats("json"))
+
+	var infos []RouteInfo
+	err := router.Walk(func(pattern string, h http.Handler, info RouteInfo) error {
+		assert.Equal(t, info.Pattern, pattern)
+		assert.Equal(t, handler, h)
+		assert.Equal(t, "*route.recordingHandler", info.Handler)
+		assert.Regexp(t, `^walk_test\.go:\d+$`, info.Source)
+		info.Handler, info.Source = "", ""
+		infos = append(infos, info)
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []RouteInfo{
+		{Pattern: "/"},
+		{Pattern: "/files/*path", Params: []string{"path"}},
+		{Pattern: "/reports/:id", Params: []string{"id"}},
+		{Pattern: "/user/:name", Name: "user", Methods: []string{"GET"}, Params: []string{"name"}},
+		{Pattern: "/user/:name", Params: []string{"name"}, Priority: 1},
+		{Pattern: "/", Host: "api.example.com"},
+	}, infos)
+}
+
+func TestRouterWalkStops(t *testing.T) {
+	router := New()
+	router.Handle("/a", &recordingHandler{})
+	router.Handle("/b", &recordingHandler{})
+
+	expectedErr := errors.New("stop")
+	visited := 0
+
+	err := router.Walk(func(pattern string, h http.Handler, info RouteInfo) error {
+		visited++
+		return expectedErr
+	})
+
+	assert.Equal(t, expectedErr, err)
+	assert.Equal(t, 1, visited)
+}
+
+func TestRouterWalkErrorHandler(t *testing.T) {
+	router := New()
+	router.HandleFunc("/a", func(w http.ResponseWriter, r *http.Request) error { return nil })
+
+	router.Walk(func(pattern string, h http.Handler, info RouteInfo) error {
+		assert.IsType(t, HandlerFunc(nil), h)
+		return nil
+	})
+}
+
+func TestRouterRoutes(t *testing.T) {
+	router := New()
+	router.HandleFunc("/b", func(w http.ResponseWriter, r *http.Request) {})
+	router.Get("/a", http.NotFoundHandler(), Name("a"))
+
+	routes := router.Routes()
+
+	if assert.Len(t, routes, 2) {
+		assert.Equal(t, "/a", routes[0].Pattern)
+		assert.Equal(t, "a", routes[0].Name)
+		assert.Equal(t, []string{"GET"}, routes[0].Methods)
+		assert.Equal(t, "net/http.NotFound", routes[0].Handler)
+		assert.Regexp(t, `^walk_test\.go:\d+$`, routes[0].Source)
+
+		assert.Equal(t, "/b", routes[1].Pattern)
+		assert.Equal(t, "hawx.me/code/route.TestRouterRoutes.func1", routes[1].Handler)
+		assert.Regexp(t, `^walk_test\.go:\d+$`, routes[1].Source)
+	}
+
+	router.Handle("/c", &recordingHandler{})
+	assert.Len(t, routes, 2)
+}
+
+func TestRouterRouteTable(t *testing.T) {
+	noop := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	router := New()
+	router.HandleAll([]string{"/users", "/people"}, noop, Methods("GET"), Name("users"))
+	router.Handle("/users/:id", noop, Methods("GET", "POST"))
+	router.Handle("/files/*path", noop)
+	router.Host("api.example.com").Get("/status", noop)
+
+	assert.Equal(t, `* /files/*path
+GET /people alias=/users
+GET /users name=users
+GET,POST /users/:id
+GET api.example.com/status
+`, router.RouteTable())
+}
@@ -0,0 +1,196 @@
+package route
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// webSocketGUID is appended to the key of a handshake to compute the accept
+// key, as given by RFC 6455.
+const webSocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// WebSocket registers the handler for WebSocket handshakes to the path. The
+// handshake is validated before the handler is called, so that it only sees
+// requests it can upgrade:
+//
+//   router.WebSocket("/rooms/:room/socket", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+//     conn, err := upgrader.Upgrade(w, r, nil)
+//     ...
+//     room := route.Vars(r)["room"]
+//   }))
+//
+// Requests that are not a WebSocket handshake are responded to with 400 Bad
+// Request, and those for an unsupported version of the protocol with 426
+// Upgrade Required. Handshakes from another origin are responded to with 403
+// Forbidden, unless the origin is allowed with Origins.
+//
+// The handler is given the outermost ResponseWriter that can be hijacked,
+// unwrapping any middleware that cannot, so that it can be hijacked whatever
+// the route is served through. Once hijacked nothing more is written to the
+// response. The handshake can be completed with AcceptWebSocket, or by
+// passing the request to a WebSocket library.
+func (r *Router) WebSocket(path string, handler http.Handler, opts ...Option) {
+	r.Group("").WebSocket(path, handler, opts...)
+}
+
+// WebSocket registers the handler for WebSocket handshakes to the path,
+// relative to the Group, as Router.WebSocket does.
+func (g *Group) WebSocket(path string, handler http.Handler, opts ...Option) {
+	e := &entry{}
+	for _, opt := range append(append([]Option{}, g.opts...), opts...) {
+		opt(e)
+	}
+
+	g.Get(path, webSocketHandler{handler: handler, origins: e.origins}, opts...)
+}
+
+// Origins sets the origins allowed to make a WebSocket handshake to the route,
+// as "https://example.com". The origin "*" allows any. Without it handshakes
+// are only accepted from the host the request was made to, or from clients
+// that do not send an Origin header.
+func Origins(origins ...string) Option {
+	return func(e *entry) {
+		for _, origin := range origins {
+			e.origins = append(e.origins, strings.ToLower(origin))
+		}
+	}
+}
+
+// webSocketHandler validates the handshake of a request before passing it to a
+// handler.
+type webSocketHandler struct {
+	handler http.Handler
+	origins []string
+}
+
+func (h webSocketHandler) ServeErrorHTTP(w http.ResponseWriter, r *http.Request) error {
+	if err := checkHandshake(r); err != nil {
+		return err
+	}
+	if !h.allowed(r) {
+		return Error(http.StatusForbidden, "origin not allowed")
+	}
+
+	h.handler.ServeHTTP(hijacker(w), r)
+	return nil
+}
+
+// allowed returns true if the Origin of the request may make the handshake.
+func (h webSocketHandler) allowed(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	if len(h.origins) == 0 {
+		u, err := url.Parse(origin)
+		return err == nil && strings.EqualFold(u.Host, r.Host)
+	}
+
+	origin = strings.ToLower(origin)
+	for _, allowed := range h.origins {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkHandshake returns an error if the request is not a valid WebSocket
+// handshake.
+func checkHandshake(r *http.Request) error {
+	if r.Method != http.MethodGet {
+		return Error(http.StatusMethodNotAllowed, "WebSocket handshake must be a GET request")
+	}
+	if !headerContains(r.Header, "Connection", "upgrade") || !headerContains(r.Header, "Upgrade", "websocket") {
+		return Error(http.StatusBadRequest, "not a WebSocket handshake")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		return versionError{}
+	}
+	if key, err := base64.StdEncoding.DecodeString(r.Header.Get("Sec-WebSocket-Key")); err != nil || len(key) != 16 {
+		return Error(http.StatusBadRequest, "invalid Sec-WebSocket-Key")
+	}
+
+	return nil
+}
+
+// versionError is returned for handshakes using a version of the protocol
+// other than 13. It is responded to with 426 Upgrade Required and the version
+// that is supported.
+type versionError struct{}
+
+func (versionError) Error() string {
+	return "route: unsupported WebSocket version"
+}
+
+func (versionError) StatusCode() int {
+	return http.StatusUpgradeRequired
+}
+
+func (versionError) Header() http.Header {
+	return http.Header{"Sec-Websocket-Version": {"13"}}
+}
+
+// headerContains returns true if any of the comma-separated values of the
+// header named key is token, ignoring case.
+func headerContains(header http.Header, key, token string) bool {
+	for _, value := range header.Values(key) {
+		for _, v := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(v), token) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// hijacker returns the first ResponseWriter that can be hijacked, found by
+// unwrapping w, or w if there is none.
+func hijacker(w http.ResponseWriter) http.ResponseWriter {
+	for v := w; ; {
+		if _, ok := v.(http.Hijacker); ok {
+			return v
+		}
+
+		u, ok := v.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return w
+		}
+		v = u.Unwrap()
+	}
+}
+
+// AcceptWebSocket completes the WebSocket handshake of the request, sending
+// 101 Switching Protocols, and returns the hijacked connection. Framing of
+// messages on the connection is left to the caller.
+func AcceptWebSocket(w http.ResponseWriter, r *http.Request) (net.Conn, *bufio.ReadWriter, error) {
+	if err := checkHandshake(r); err != nil {
+		return nil, nil, err
+	}
+
+	conn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sum := sha1.Sum([]byte(r.Header.Get("Sec-WebSocket-Key") + webSocketGUID))
+
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(sum[:]) + "\r\n\r\n")
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	return conn, rw, nil
+}
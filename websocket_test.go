@@ -0,0 +1,151 @@
+package route
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func handshake(target string) *http.Request {
+	req := httptest.NewRequest("GET", target, nil)
+	req.Header.Set("Connection", "keep-alive, Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	return req
+}
+
+func TestWebSocketValidatesHandshake(t *testing.T) {
+	router := New()
+	router.WebSocket("/socket", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	router.WebSocket("/open", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}), Origins("https://app.example.com"))
+
+	testCases := map[string]struct {
+		target string
+		modify func(*http.Request)
+		code   int
+	}{
+		"valid": {"/socket", func(*http.Request) {}, http.StatusTeapot},
+		"not upgrade": {"/socket", func(r *http.Request) {
+			r.Header.Del("Upgrade")
+		}, http.StatusBadRequest},
+		"bad key": {"/socket", func(r *http.Request) {
+			r.Header.Set("Sec-WebSocket-Key", "short")
+		}, http.StatusBadRequest},
+		"old version": {"/socket", func(r *http.Request) {
+			r.Header.Set("Sec-WebSocket-Version", "8")
+		}, http.StatusUpgradeRequired},
+		"same origin": {"/socket", func(r *http.Request) {
+			r.Header.Set("Origin", "http://example.com")
+		}, http.StatusTeapot},
+		"cross origin": {"/socket", func(r *http.Request) {
+			r.Header.Set("Origin", "https://evil.example")
+		}, http.StatusForbidden},
+		"allowed origin": {"/open", func(r *http.Request) {
+			r.Header.Set("Origin", "https://APP.example.com")
+		}, http.StatusTeapot},
+		"disallowed origin": {"/open", func(r *http.Request) {
+			r.Header.Set("Origin", "http://example.com")
+		}, http.StatusForbidden},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req := handshake(tc.target)
+			tc.modify(req)
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.code, rec.Code)
+			if tc.code == http.StatusUpgradeRequired {
+				assert.Equal(t, "13", rec.Header().Get("Sec-WebSocket-Version"))
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("POST", "/socket", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestWebSocketHijacksThroughWrappers(t *testing.T) {
+	status := make(chan int, 1)
+
+	router := New()
+	router.After(func(r *http.Request, res Result) {
+		status <- res.Status
+	})
+	router.WebSocket("/rooms/:room", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := AcceptWebSocket(w, r)
+		if !assert.Nil(t, err) {
+			return
+		}
+		defer conn.Close()
+
+		rw.WriteString(Vars(r)["room"] + "\n")
+		rw.Flush()
+	}), Caching(CachePolicy{MaxAge: time.Minute}))
+
+	s := httptest.NewServer(router)
+	defer s.Close()
+
+	conn, err := net.Dial("tcp", s.Listener.Addr().String())
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	req := handshake("/rooms/lobby")
+	req.RequestURI = ""
+	req.Host = s.Listener.Addr().String()
+	assert.Nil(t, req.Write(conn))
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", resp.Header.Get("Sec-WebSocket-Accept"))
+	assert.Equal(t, "", resp.Header.Get("Cache-Control"))
+
+	line, _ := br.ReadString('\n')
+	assert.Equal(t, "lobby", strings.TrimSpace(line))
+	assert.Equal(t, http.StatusSwitchingProtocols, <-status)
+}
+
+func TestWebSocketDoesNotWriteAfterHijack(t *testing.T) {
+	var status int
+
+	router := New()
+	router.After(func(r *http.Request, res Result) {
+		status = res.Status
+	})
+	router.WebSocket("/socket", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, _, err := http.NewResponseController(w).Hijack()
+		if assert.Nil(t, err) {
+			conn.Close()
+		}
+	}), Caching(CachePolicy{MaxAge: time.Minute}))
+
+	w := &hijackRecorder{ResponseRecorder: httptest.NewRecorder()}
+	router.ServeHTTP(w, handshake("/socket"))
+
+	assert.True(t, w.hijacked)
+	assert.Empty(t, w.codes)
+	assert.Empty(t, w.Header())
+	assert.Equal(t, http.StatusSwitchingProtocols, status)
+}
@@ -0,0 +1,301 @@
+package route
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// yamlLine is a line of a YAML document, without its indentation or comment.
+type yamlLine struct {
+	num    int
+	indent int
+	text   string
+}
+
+// yamlParser reads the subset of YAML needed for configuration: block mappings
+// and sequences, flow sequences of scalars, quoted and plain scalars, and
+// comments. Anchors, tags, multi-line scalars and flow mappings are not read.
+type yamlParser struct {
+	lines []yamlLine
+	i     int
+}
+
+// parseYAML returns the value of the YAML document as the types json.Unmarshal
+// gives an interface{}, except that integers are a json.Number.
+func parseYAML(data string) (interface{}, error) {
+	p := &yamlParser{}
+
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimRight(stripYAMLComment(line), " \t\r")
+		text := strings.TrimLeft(line, " ")
+		if text == "" || line == "---" || line == "..." {
+			continue
+		}
+		if text[0] == '\t' {
+			return nil, yamlError(i+1, "tabs cannot be used for indentation")
+		}
+
+		p.lines = append(p.lines, yamlLine{num: i + 1, indent: len(line) - len(text), text: text})
+	}
+
+	if len(p.lines) == 0 {
+		return nil, nil
+	}
+
+	v, err := p.node(p.lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.i < len(p.lines) {
+		return nil, yamlError(p.lines[p.i].num, "unexpected indentation")
+	}
+
+	return v, nil
+}
+
+// node reads the mapping or sequence starting at the current line.
+func (p *yamlParser) node(indent int) (interface{}, error) {
+	if isYAMLItem(p.lines[p.i].text) {
+		return p.sequence(indent)
+	}
+
+	return p.mapping(indent)
+}
+
+func (p *yamlParser) sequence(indent int) (interface{}, error) {
+	items := []interface{}{}
+
+	for p.i < len(p.lines) {
+		line := p.lines[p.i]
+		if line.indent < indent {
+			break
+		}
+		if line.indent > indent || !isYAMLItem(line.text) {
+			return nil, yamlError(line.num, "unexpected indentation")
+		}
+
+		rest := strings.TrimLeft(line.text[1:], " ")
+		if rest == "" {
+			p.i++
+			item, err := p.nested(indent)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+			continue
+		}
+
+		if !isYAMLItem(rest) && yamlKeyEnd(rest) < 0 {
+			item, err := yamlScalar(line.num, rest)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+			p.i++
+			continue
+		}
+
+		// the item is a mapping or sequence starting on the same line, which is
+		// read as if it started on its own line after the "- "
+		p.lines[p.i] = yamlLine{num: line.num, indent: line.indent + len(line.text) - len(rest), text: rest}
+		item, err := p.node(p.lines[p.i].indent)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func (p *yamlParser) mapping(indent int) (interface{}, error) {
+	values := map[string]interface{}{}
+
+	for p.i < len(p.lines) {
+		line := p.lines[p.i]
+		if line.indent < indent {
+			break
+		}
+		if line.indent > indent || isYAMLItem(line.text) {
+			return nil, yamlError(line.num, "unexpected indentation")
+		}
+
+		end := yamlKeyEnd(line.text)
+		if end < 0 {
+			return nil, yamlError(line.num, "expected a key")
+		}
+		key, err := yamlScalar(line.num, line.text[:end])
+		if err != nil {
+			return nil, err
+		}
+		name, ok := key.(string)
+		if !ok {
+			name = fmt.Sprint(key)
+		}
+		if _, exists := values[name]; exists {
+			return nil, yamlError(line.num, "duplicate key "+strconv.Quote(name))
+		}
+
+		p.i++
+		if rest := strings.TrimLeft(line.text[end+1:], " "); rest != "" {
+			values[name], err = yamlScalar(line.num, rest)
+		} else if p.i < len(p.lines) && p.lines[p.i].indent == indent && isYAMLItem(p.lines[p.i].text) {
+			// a sequence may be at the same indentation as its key
+			values[name], err = p.sequence(indent)
+		} else {
+			values[name], err = p.nested(indent)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+// nested reads the node on the following lines if they are indented more than
+// indent, otherwise the value is null.
+func (p *yamlParser) nested(indent int) (interface{}, error) {
+	if p.i >= len(p.lines) || p.lines[p.i].indent <= indent {
+		return nil, nil
+	}
+
+	return p.node(p.lines[p.i].indent)
+}
+
+// isYAMLItem returns true if the text is an item of a block sequence.
+func isYAMLItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// yamlKeyEnd returns the index of the colon ending the key of a mapping entry,
+// or -1 if the text is not one.
+func yamlKeyEnd(text string) int {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case i == 0 && (c == '"' || c == '\''):
+			quote = c
+		case c == ':' && (i+1 == len(text) || text[i+1] == ' '):
+			return i
+		}
+	}
+
+	return -1
+}
+
+// stripYAMLComment removes a comment from the end of the line, ignoring any #
+// that is quoted or not after a space.
+func stripYAMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || strings.IndexByte(" [,:-", line[i-1]) >= 0 {
+				quote = c
+			}
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+
+	return line
+}
+
+// yamlScalar returns the value of a scalar or flow sequence of scalars.
+func yamlScalar(num int, text string) (interface{}, error) {
+	text = strings.TrimSpace(text)
+
+	switch text[0] {
+	case '"':
+		s, err := strconv.Unquote(text)
+		if err != nil {
+			return nil, yamlError(num, "invalid quoted string "+text)
+		}
+		return s, nil
+
+	case '\'':
+		if len(text) < 2 || text[len(text)-1] != '\'' {
+			return nil, yamlError(num, "invalid quoted string "+text)
+		}
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), nil
+
+	case '[':
+		if text[len(text)-1] != ']' {
+			return nil, yamlError(num, "flow sequences must be on one line")
+		}
+		items := []interface{}{}
+		for _, item := range splitYAMLFlow(text[1 : len(text)-1]) {
+			if strings.TrimSpace(item) == "" {
+				continue
+			}
+			v, err := yamlScalar(num, item)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, v)
+		}
+		return items, nil
+
+	case '{', '&', '*', '!', '|', '>', '%', '@', '`':
+		return nil, yamlError(num, "unsupported value "+text)
+	}
+
+	switch text {
+	case "~", "null", "Null", "NULL":
+		return nil, nil
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	}
+	if _, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return json.Number(text), nil
+	}
+
+	return text, nil
+}
+
+// splitYAMLFlow splits the items of a flow sequence on the commas that are not
+// quoted.
+func splitYAMLFlow(text string) []string {
+	var items []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ',':
+			items = append(items, text[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(items, text[start:])
+}
+
+func yamlError(num int, msg string) error {
+	return errors.New("yaml: line " + strconv.Itoa(num) + ": " + msg)
+}